@@ -16,6 +16,11 @@ type Expression interface {
 type Identifier struct {
 	Token token.Token
 	Value string
+
+	// Where the variable is stored, set by the resolver
+	Local bool // If the variable is stored in a frame instead of by name in the global environment
+	Depth int  // The amount of frames between the frame the identifier is used in and the frame of the variable
+	Slot  int  // The index of the variable in its frame
 }
 
 func (i *Identifier) expressionNode()      {}
@@ -96,6 +101,32 @@ func (ie *IfExpression) String() string {
 func (ie *IfExpression) Line() int   { return ie.Token.Line }
 func (ie *IfExpression) Column() int { return ie.Token.Column }
 
+type TernaryExpression struct {
+	Token     token.Token // The '?' token
+	Condition Expression
+
+	Consequence Expression
+	Alternative Expression
+}
+
+func (te *TernaryExpression) expressionNode()      {}
+func (te *TernaryExpression) TokenLiteral() string { return te.Token.Literal }
+func (te *TernaryExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(te.Condition.String())
+	out.WriteString(" ? ")
+	out.WriteString(te.Consequence.String())
+	out.WriteString(" : ")
+	out.WriteString(te.Alternative.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+func (te *TernaryExpression) Line() int   { return te.Token.Line }
+func (te *TernaryExpression) Column() int { return te.Token.Column }
+
 type BreakExpression struct {
 	Token token.Token // The 'break' token
 }
@@ -230,3 +261,127 @@ func (ce *ChainingExpression) String() string {
 }
 func (ce *ChainingExpression) Line() int   { return ce.Token.Line }
 func (ce *ChainingExpression) Column() int { return ce.Token.Column }
+
+/*
+Get the method call on the right-hand side of a chaining expression like left.method(args)
+*/
+func (ce *ChainingExpression) Call() (*CallExpression, bool) {
+	call, ok := ce.Right.(*CallExpression)
+
+	return call, ok
+}
+
+type MemberReassignmentExpression struct {
+	Token  token.Token // The assignment operator token (e.g. =, +=, -=)
+	Target Expression  // IndexExpression or ChainingExpression, e.g. arr[0] or obj.key
+	Value  Expression
+}
+
+func (mr *MemberReassignmentExpression) expressionNode()      {}
+func (mr *MemberReassignmentExpression) TokenLiteral() string { return mr.Token.Literal }
+func (mr *MemberReassignmentExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(mr.Target.String())
+	out.WriteString(fmt.Sprintf(" %s ", mr.Token.Literal))
+	out.WriteString(mr.Value.String())
+
+	return out.String()
+}
+func (mr *MemberReassignmentExpression) Line() int   { return mr.Token.Line }
+func (mr *MemberReassignmentExpression) Column() int { return mr.Token.Column }
+
+type MemberIncrementDecrementExpression struct {
+	Token  token.Token // The increment or decrement operator token (e.g. ++, --)
+	Target Expression  // IndexExpression or ChainingExpression, e.g. arr[0] or obj.key
+	Before bool        // true for the postfix form, which evaluates to the value from before the update
+}
+
+func (mi *MemberIncrementDecrementExpression) expressionNode()      {}
+func (mi *MemberIncrementDecrementExpression) TokenLiteral() string { return mi.Token.Literal }
+func (mi *MemberIncrementDecrementExpression) String() string {
+	var out bytes.Buffer
+
+	if mi.Before {
+		out.WriteString(mi.Target.String())
+		out.WriteString(mi.Token.Literal)
+	} else {
+		out.WriteString(mi.Token.Literal)
+		out.WriteString(mi.Target.String())
+	}
+
+	return out.String()
+}
+func (mi *MemberIncrementDecrementExpression) Line() int   { return mi.Token.Line }
+func (mi *MemberIncrementDecrementExpression) Column() int { return mi.Token.Column }
+
+type SpreadExpression struct {
+	Token token.Token // The '...' token
+	Value Expression  // The array, string or object that is spread
+}
+
+func (se *SpreadExpression) expressionNode()      {}
+func (se *SpreadExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SpreadExpression) String() string       { return se.Token.Literal + se.Value.String() }
+func (se *SpreadExpression) Line() int            { return se.Token.Line }
+func (se *SpreadExpression) Column() int          { return se.Token.Column }
+
+/*
+An arm of a match expression, e.g. `[x, y] if x > y => x`
+*/
+type MatchArm struct {
+	Patterns []Expression // Alternative patterns, the arm is used if any of them matches
+	Guard    Expression   // Extra condition the arm has to satisfy, can be nil
+	Body     Expression
+	Slots    int // The amount of names bound by the patterns, set by the resolver
+}
+
+func (ma *MatchArm) String() string {
+	var out bytes.Buffer
+
+	patterns := []string{}
+
+	for _, pattern := range ma.Patterns {
+		patterns = append(patterns, pattern.String())
+	}
+
+	out.WriteString(strings.Join(patterns, ", "))
+
+	if ma.Guard != nil {
+		out.WriteString(" if ")
+		out.WriteString(ma.Guard.String())
+	}
+
+	out.WriteString(" => ")
+	out.WriteString(ma.Body.String())
+
+	return out.String()
+}
+
+type MatchExpression struct {
+	Token   token.Token // The 'match' token
+	Subject Expression
+	Arms    []*MatchArm
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+
+	arms := []string{}
+
+	for _, arm := range me.Arms {
+		arms = append(arms, arm.String())
+	}
+
+	out.WriteString("match ")
+	out.WriteString(me.Subject.String())
+	out.WriteString(" { ")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+func (me *MatchExpression) Line() int   { return me.Token.Line }
+func (me *MatchExpression) Column() int { return me.Token.Column }
@@ -537,3 +537,273 @@ func TestIncrementDecrementExpressionAfter(t *testing.T) {
 
 	expression.expressionNode()
 }
+
+func TestMemberReassignmentExpression(t *testing.T) {
+	expression := &MemberReassignmentExpression{
+		Target: &IndexExpression{
+			Left: &Identifier{
+				Value: "foo",
+				Token: token.Token{
+					Type:    token.IDENT,
+					Literal: "foo",
+					Line:    1,
+					Column:  1,
+				},
+			},
+			Index: &IntegerLiteral{
+				Value: 0,
+				Token: token.Token{
+					Type:    token.INT,
+					Literal: "0",
+					Line:    1,
+					Column:  5,
+				},
+			},
+			Token: token.Token{
+				Type:    token.LBRACKET,
+				Literal: "[",
+				Line:    1,
+				Column:  4,
+			},
+		},
+		Value: &IntegerLiteral{
+			Value: 5,
+			Token: token.Token{
+				Type:    token.INT,
+				Literal: "5",
+				Line:    1,
+				Column:  11,
+			},
+		},
+		Token: token.Token{
+			Type:    token.PLUS_ASSIGN,
+			Literal: "+=",
+			Line:    1,
+			Column:  8,
+		},
+	}
+
+	if expression.String() != "(foo[0]) += 5" {
+		t.Errorf("MemberReassignmentExpression.String() = %s; want (foo[0]) += 5", expression.String())
+	}
+
+	if expression.TokenLiteral() != "+=" {
+		t.Errorf("MemberReassignmentExpression.TokenLiteral() = %s; want +=", expression.TokenLiteral())
+	}
+
+	if expression.Line() != 1 {
+		t.Errorf("MemberReassignmentExpression.Line() = %d; want 1", expression.Line())
+	}
+
+	if expression.Column() != 8 {
+		t.Errorf("MemberReassignmentExpression.Column() = %d; want 8", expression.Column())
+	}
+
+	expression.expressionNode()
+}
+
+func TestMemberIncrementDecrementExpression(t *testing.T) {
+	target := &ChainingExpression{
+		Left: &Identifier{
+			Value: "foo",
+			Token: token.Token{
+				Type:    token.IDENT,
+				Literal: "foo",
+				Line:    1,
+				Column:  1,
+			},
+		},
+		Right: &Identifier{
+			Value: "bar",
+			Token: token.Token{
+				Type:    token.IDENT,
+				Literal: "bar",
+				Line:    1,
+				Column:  5,
+			},
+		},
+		Token: token.Token{
+			Type:    token.DOT,
+			Literal: ".",
+			Line:    1,
+			Column:  4,
+		},
+	}
+
+	expression := &MemberIncrementDecrementExpression{
+		Target: target,
+		Token: token.Token{
+			Type:    token.INCREMENT,
+			Literal: "++",
+			Line:    1,
+			Column:  8,
+		},
+		Before: true,
+	}
+
+	if expression.String() != "(foo.bar)++" {
+		t.Errorf("MemberIncrementDecrementExpression.String() = %s; want (foo.bar)++", expression.String())
+	}
+
+	if expression.TokenLiteral() != "++" {
+		t.Errorf("MemberIncrementDecrementExpression.TokenLiteral() = %s; want ++", expression.TokenLiteral())
+	}
+
+	if expression.Line() != 1 {
+		t.Errorf("MemberIncrementDecrementExpression.Line() = %d; want 1", expression.Line())
+	}
+
+	if expression.Column() != 8 {
+		t.Errorf("MemberIncrementDecrementExpression.Column() = %d; want 8", expression.Column())
+	}
+
+	expression.Before = false
+
+	if expression.String() != "++(foo.bar)" {
+		t.Errorf("MemberIncrementDecrementExpression.String() = %s; want ++(foo.bar)", expression.String())
+	}
+
+	expression.expressionNode()
+}
+
+func TestTernaryExpression(t *testing.T) {
+	expression := &TernaryExpression{
+		Condition: &Identifier{
+			Value: "foo",
+			Token: token.Token{
+				Type:    token.IDENT,
+				Literal: "foo",
+				Line:    1,
+				Column:  1,
+			},
+		},
+		Consequence: &Identifier{
+			Value: "bar",
+			Token: token.Token{
+				Type:    token.IDENT,
+				Literal: "bar",
+				Line:    1,
+				Column:  7,
+			},
+		},
+		Alternative: &Identifier{
+			Value: "baz",
+			Token: token.Token{
+				Type:    token.IDENT,
+				Literal: "baz",
+				Line:    1,
+				Column:  13,
+			},
+		},
+		Token: token.Token{
+			Type:    token.QUESTION,
+			Literal: "?",
+			Line:    1,
+			Column:  5,
+		},
+	}
+
+	if expression.String() != "(foo ? bar : baz)" {
+		t.Errorf("TernaryExpression.String() = %s; want (foo ? bar : baz)", expression.String())
+	}
+
+	if expression.TokenLiteral() != "?" {
+		t.Errorf("TernaryExpression.TokenLiteral() = %s; want ?", expression.TokenLiteral())
+	}
+
+	if expression.Line() != 1 {
+		t.Errorf("TernaryExpression.Line() = %d; want 1", expression.Line())
+	}
+
+	if expression.Column() != 5 {
+		t.Errorf("TernaryExpression.Column() = %d; want 5", expression.Column())
+	}
+
+	expression.expressionNode()
+}
+
+func TestSpreadExpression(t *testing.T) {
+	expression := &SpreadExpression{
+		Token: token.Token{
+			Type:    token.ELLIPSIS,
+			Literal: "...",
+			Line:    1,
+			Column:  2,
+		},
+		Value: &Identifier{
+			Value: "foo",
+			Token: token.Token{
+				Type:    token.IDENT,
+				Literal: "foo",
+				Line:    1,
+				Column:  5,
+			},
+		},
+	}
+
+	if expression.String() != "...foo" {
+		t.Errorf("SpreadExpression.String() = %s; want ...foo", expression.String())
+	}
+
+	if expression.TokenLiteral() != "..." {
+		t.Errorf("SpreadExpression.TokenLiteral() = %s; want ...", expression.TokenLiteral())
+	}
+
+	if expression.Line() != 1 {
+		t.Errorf("SpreadExpression.Line() = %d; want 1", expression.Line())
+	}
+
+	if expression.Column() != 2 {
+		t.Errorf("SpreadExpression.Column() = %d; want 2", expression.Column())
+	}
+
+	expression.expressionNode()
+}
+
+func TestMatchExpression(t *testing.T) {
+	identifier := func(name string) *Identifier {
+		return &Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+	}
+
+	expression := &MatchExpression{
+		Token: token.Token{
+			Type:    token.MATCH,
+			Literal: "match",
+			Line:    1,
+			Column:  1,
+		},
+		Subject: identifier("foo"),
+		Arms: []*MatchArm{
+			{
+				Patterns: []Expression{
+					&IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1},
+					&IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "2"}, Value: 2},
+				},
+				Body: identifier("small"),
+			},
+			{
+				Patterns: []Expression{identifier("n")},
+				Guard:    identifier("big"),
+				Body:     identifier("n"),
+			},
+		},
+	}
+
+	if expression.String() != "match foo { 1, 2 => small, n if big => n }" {
+		t.Errorf("MatchExpression.String() = %s; want match foo { 1, 2 => small, n if big => n }", expression.String())
+	}
+
+	if expression.TokenLiteral() != "match" {
+		t.Errorf("MatchExpression.TokenLiteral() = %s; want match", expression.TokenLiteral())
+	}
+
+	if expression.Line() != 1 {
+		t.Errorf("MatchExpression.Line() = %d; want 1", expression.Line())
+	}
+
+	if expression.Column() != 1 {
+		t.Errorf("MatchExpression.Column() = %d; want 1", expression.Column())
+	}
+
+	expression.expressionNode()
+}
@@ -2,7 +2,9 @@ package ast
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
+	"unicode"
 
 	"github.com/iskandervdh/vorn/token"
 )
@@ -53,6 +55,10 @@ func (nl *NullLiteral) Column() int          { return nl.Token.Column }
 type FunctionLiteral struct {
 	Token     token.Token // The 'func' token
 	Arguments []*Identifier
+	Defaults  map[string]Expression // Default values of optional arguments by argument name
+	Rest      *Identifier           // The ...rest argument that collects any remaining arguments, nil if there is none
+	Patterns  map[string]Expression // Destructuring patterns of arguments by argument name
+	Slots     int                   // The amount of names bound by the arguments, set by the resolver
 
 	Body *BlockStatement
 }
@@ -62,15 +68,9 @@ func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
-	args := []string{}
-
-	for _, p := range fl.Arguments {
-		args = append(args, p.String())
-	}
-
 	out.WriteString(fl.TokenLiteral())
 	out.WriteString("(")
-	out.WriteString(strings.Join(args, ", "))
+	out.WriteString(FormatArguments(fl.Arguments, fl.Defaults, fl.Rest))
 	out.WriteString(") ")
 	out.WriteString(fl.Body.String())
 
@@ -86,10 +86,41 @@ type StringLiteral struct {
 
 func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
-func (sl *StringLiteral) String() string       { return sl.Token.Literal }
+func (sl *StringLiteral) String() string       { return QuoteString(sl.Value) }
 func (sl *StringLiteral) Line() int            { return sl.Token.Line }
 func (sl *StringLiteral) Column() int          { return sl.Token.Column }
 
+type TemplateLiteral struct {
+	Token token.Token  // The TEMPLATE_START token
+	Parts []Expression // *StringLiteral for the literal parts, any other expression for the interpolations
+}
+
+func (tl *TemplateLiteral) expressionNode()      {}
+func (tl *TemplateLiteral) TokenLiteral() string { return tl.Token.Literal }
+func (tl *TemplateLiteral) String() string {
+	var out bytes.Buffer
+
+	out.WriteByte('"')
+
+	for _, part := range tl.Parts {
+		if literal, ok := part.(*StringLiteral); ok {
+			writeEscapedString(&out, literal.Value)
+
+			continue
+		}
+
+		out.WriteString("${")
+		out.WriteString(part.String())
+		out.WriteString("}")
+	}
+
+	out.WriteByte('"')
+
+	return out.String()
+}
+func (tl *TemplateLiteral) Line() int   { return tl.Token.Line }
+func (tl *TemplateLiteral) Column() int { return tl.Token.Column }
+
 type ArrayLiteral struct {
 	Token    token.Token // the '[' token
 	Elements []Expression
@@ -117,6 +148,7 @@ func (al *ArrayLiteral) Column() int { return al.Token.Column }
 type HashLiteral struct {
 	Token token.Token // the '{' token
 	Pairs map[Expression]Expression
+	Keys  []Expression // The keys and spread expressions in the order they are defined in
 }
 
 func (hl *HashLiteral) expressionNode()      {}
@@ -126,8 +158,13 @@ func (hl *HashLiteral) String() string {
 
 	pairs := []string{}
 
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+": "+value.String())
+	for _, key := range hl.Keys {
+		if spread, ok := key.(*SpreadExpression); ok {
+			pairs = append(pairs, spread.String())
+			continue
+		}
+
+		pairs = append(pairs, key.String()+": "+hl.Pairs[key].String())
 	}
 
 	out.WriteString("{")
@@ -138,3 +175,73 @@ func (hl *HashLiteral) String() string {
 }
 func (hl *HashLiteral) Line() int   { return hl.Token.Line }
 func (hl *HashLiteral) Column() int { return hl.Token.Column }
+
+/*
+Format the arguments of a function definition, e.g. a, b = 10, ...rest
+*/
+func FormatArguments(arguments []*Identifier, defaults map[string]Expression, rest *Identifier) string {
+	args := []string{}
+
+	for _, argument := range arguments {
+		if value, ok := defaults[argument.Value]; ok {
+			args = append(args, argument.String()+" = "+value.String())
+		} else {
+			args = append(args, argument.String())
+		}
+	}
+
+	if rest != nil {
+		args = append(args, "..."+rest.String())
+	}
+
+	return strings.Join(args, ", ")
+}
+
+/*
+Quote a string value as a double quoted vorn string literal, escaping characters where needed
+so that the result can be parsed back into the same value.
+*/
+func QuoteString(value string) string {
+	var out bytes.Buffer
+
+	out.WriteByte('"')
+	writeEscapedString(&out, value)
+	out.WriteByte('"')
+
+	return out.String()
+}
+
+/*
+Write a string value to out with the characters escaped that can't appear as is in a double quoted string
+*/
+func writeEscapedString(out *bytes.Buffer, value string) {
+	for i, char := range value {
+		switch char {
+		case '"':
+			out.WriteString(`\"`)
+		case '\\':
+			out.WriteString(`\\`)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\t':
+			out.WriteString(`\t`)
+		case '\r':
+			out.WriteString(`\r`)
+		case 0:
+			out.WriteString(`\0`)
+		case '$':
+			// Only escape $ where it would otherwise start an interpolation
+			if strings.HasPrefix(value[i:], "${") {
+				out.WriteString(`\$`)
+			} else {
+				out.WriteRune(char)
+			}
+		default:
+			if unicode.IsPrint(char) {
+				out.WriteRune(char)
+			} else {
+				out.WriteString(fmt.Sprintf(`\u{%X}`, char))
+			}
+		}
+	}
+}
@@ -190,7 +190,7 @@ func TestStringLiteral(t *testing.T) {
 		t.Errorf("literal.TokenLiteral() wrong. got=%s", literal.TokenLiteral())
 	}
 
-	if literal.String() != "hello" {
+	if literal.String() != `"hello"` {
 		t.Errorf("literal.String() wrong. got=%s", literal.String())
 	}
 
@@ -274,11 +274,79 @@ func TestHashLiteral(t *testing.T) {
 		},
 	}
 
+	for key := range literal.Pairs {
+		literal.Keys = append(literal.Keys, key)
+	}
+
 	if literal.TokenLiteral() != "{" {
 		t.Errorf("literal.TokenLiteral() wrong. got=%s", literal.TokenLiteral())
 	}
 
-	if literal.String() != `{a: 5}` {
+	if literal.String() != `{"a": 5}` {
+		t.Errorf("literal.String() wrong. got=%s", literal.String())
+	}
+
+	if literal.Line() != 1 {
+		t.Errorf("literal.Line() wrong. got=%d", literal.Line())
+	}
+
+	if literal.Column() != 1 {
+		t.Errorf("literal.Column() wrong. got=%d", literal.Column())
+	}
+
+	literal.expressionNode()
+}
+
+func TestQuoteString(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"hello", `"hello"`},
+		{"say \"hi\"", `"say \"hi\""`},
+		{`back\slash`, `"back\\slash"`},
+		{"a\nb\tc\rd", `"a\nb\tc\rd"`},
+		{"nul\x00", `"nul\0"`},
+		{"bell\a", `"bell\u{7}"`},
+		{"😀 été", `"😀 été"`},
+	}
+
+	for _, test := range tests {
+		if quoted := QuoteString(test.value); quoted != test.expected {
+			t.Errorf("QuoteString(%q) = %s; want %s", test.value, quoted, test.expected)
+		}
+	}
+}
+
+func TestTemplateLiteral(t *testing.T) {
+	literal := &TemplateLiteral{
+		Token: token.Token{
+			Type:    token.TEMPLATE_START,
+			Literal: "Hello ",
+			Line:    1,
+			Column:  1,
+		},
+		Parts: []Expression{
+			&StringLiteral{
+				Token: token.Token{Type: token.TEMPLATE_START, Literal: "Hello "},
+				Value: "Hello ",
+			},
+			&Identifier{
+				Token: token.Token{Type: token.IDENT, Literal: "name"},
+				Value: "name",
+			},
+			&StringLiteral{
+				Token: token.Token{Type: token.TEMPLATE_END, Literal: "! ${x}"},
+				Value: "! ${x}",
+			},
+		},
+	}
+
+	if literal.TokenLiteral() != "Hello " {
+		t.Errorf("literal.TokenLiteral() wrong. got=%s", literal.TokenLiteral())
+	}
+
+	if literal.String() != `"Hello ${name}! \${x}"` {
 		t.Errorf("literal.String() wrong. got=%s", literal.String())
 	}
 
@@ -292,3 +360,22 @@ func TestHashLiteral(t *testing.T) {
 
 	literal.expressionNode()
 }
+
+func TestFormatArguments(t *testing.T) {
+	arguments := []*Identifier{
+		{Token: token.Token{Type: token.IDENT, Literal: "a"}, Value: "a"},
+		{Token: token.Token{Type: token.IDENT, Literal: "b"}, Value: "b"},
+	}
+	defaults := map[string]Expression{
+		"b": &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "10"}, Value: 10},
+	}
+	rest := &Identifier{Token: token.Token{Type: token.IDENT, Literal: "rest"}, Value: "rest"}
+
+	if formatted := FormatArguments(arguments, defaults, rest); formatted != "a, b = 10, ...rest" {
+		t.Errorf("FormatArguments() wrong. got=%s", formatted)
+	}
+
+	if formatted := FormatArguments(arguments, nil, nil); formatted != "a, b" {
+		t.Errorf("FormatArguments() wrong. got=%s", formatted)
+	}
+}
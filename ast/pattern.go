@@ -0,0 +1,111 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/iskandervdh/vorn/token"
+)
+
+/*
+A single element of a destructuring pattern, e.g. `b = 10` in `[a, b = 10]` or `port: p = 80` in `{port: p = 80}`
+*/
+type PatternElement struct {
+	Key     *Identifier // The property name, only used in object patterns
+	Target  Expression  // An Identifier, ArrayPattern or HashPattern the value is bound to
+	Default Expression  // The value used when the value is missing or null, can be nil
+}
+
+func (pe *PatternElement) String() string {
+	var out bytes.Buffer
+
+	if pe.Key != nil {
+		out.WriteString(pe.Key.String())
+
+		if identifier, ok := pe.Target.(*Identifier); !ok || identifier.Value != pe.Key.Value {
+			out.WriteString(": ")
+			out.WriteString(pe.Target.String())
+		}
+	} else {
+		out.WriteString(pe.Target.String())
+	}
+
+	if pe.Default != nil {
+		out.WriteString(" = ")
+		out.WriteString(pe.Default.String())
+	}
+
+	return out.String()
+}
+
+type ArrayPattern struct {
+	Token    token.Token // the '[' token
+	Elements []*PatternElement
+	Rest     *Identifier // Collects the remaining elements, can be nil
+}
+
+func (ap *ArrayPattern) expressionNode()      {}
+func (ap *ArrayPattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *ArrayPattern) String() string {
+	return "[" + formatPattern(ap.Elements, ap.Rest) + "]"
+}
+func (ap *ArrayPattern) Line() int   { return ap.Token.Line }
+func (ap *ArrayPattern) Column() int { return ap.Token.Column }
+
+type HashPattern struct {
+	Token    token.Token // the '{' token
+	Elements []*PatternElement
+	Rest     *Identifier // Collects the remaining properties, can be nil
+}
+
+func (hp *HashPattern) expressionNode()      {}
+func (hp *HashPattern) TokenLiteral() string { return hp.Token.Literal }
+func (hp *HashPattern) String() string {
+	return "{" + formatPattern(hp.Elements, hp.Rest) + "}"
+}
+func (hp *HashPattern) Line() int   { return hp.Token.Line }
+func (hp *HashPattern) Column() int { return hp.Token.Column }
+
+func formatPattern(elements []*PatternElement, rest *Identifier) string {
+	parts := []string{}
+
+	for _, element := range elements {
+		parts = append(parts, element.String())
+	}
+
+	if rest != nil {
+		parts = append(parts, "..."+rest.String())
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+/*
+Get all the names a pattern binds a value to, in the order they are defined in.
+A pattern is an Identifier, ArrayPattern or HashPattern.
+*/
+func PatternNames(pattern Expression) []*Identifier {
+	var elements []*PatternElement
+	var rest *Identifier
+
+	switch pattern := pattern.(type) {
+	case *Identifier:
+		return []*Identifier{pattern}
+	case *ArrayPattern:
+		elements, rest = pattern.Elements, pattern.Rest
+	case *HashPattern:
+		elements, rest = pattern.Elements, pattern.Rest
+	}
+
+	names := []*Identifier{}
+
+	for _, element := range elements {
+		names = append(names, PatternNames(element.Target)...)
+	}
+
+	if rest != nil {
+		names = append(names, rest)
+	}
+
+	return names
+}
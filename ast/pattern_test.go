@@ -0,0 +1,126 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/iskandervdh/vorn/token"
+)
+
+func newTestIdentifier(name string) *Identifier {
+	return &Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+}
+
+func TestArrayPattern(t *testing.T) {
+	pattern := &ArrayPattern{
+		Token: token.Token{
+			Type:    token.LBRACKET,
+			Literal: "[",
+			Line:    1,
+			Column:  5,
+		},
+		Elements: []*PatternElement{
+			{Target: newTestIdentifier("a")},
+			{
+				Target:  newTestIdentifier("b"),
+				Default: &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "10"}, Value: 10},
+			},
+		},
+		Rest: newTestIdentifier("rest"),
+	}
+
+	if pattern.String() != "[a, b = 10, ...rest]" {
+		t.Errorf("pattern.String() wrong. got=%s", pattern.String())
+	}
+
+	if pattern.TokenLiteral() != "[" {
+		t.Errorf("pattern.TokenLiteral() wrong. got=%s", pattern.TokenLiteral())
+	}
+
+	if pattern.Line() != 1 {
+		t.Errorf("pattern.Line() wrong. got=%d", pattern.Line())
+	}
+
+	if pattern.Column() != 5 {
+		t.Errorf("pattern.Column() wrong. got=%d", pattern.Column())
+	}
+
+	pattern.expressionNode()
+}
+
+func TestHashPattern(t *testing.T) {
+	pattern := &HashPattern{
+		Token: token.Token{
+			Type:    token.LBRACE,
+			Literal: "{",
+			Line:    1,
+			Column:  5,
+		},
+		Elements: []*PatternElement{
+			{Key: newTestIdentifier("host"), Target: newTestIdentifier("host")},
+			{Key: newTestIdentifier("port"), Target: newTestIdentifier("p")},
+			{
+				Key: newTestIdentifier("tags"),
+				Target: &ArrayPattern{
+					Token:    token.Token{Type: token.LBRACKET, Literal: "["},
+					Elements: []*PatternElement{{Target: newTestIdentifier("first")}},
+				},
+				Default: &ArrayLiteral{Token: token.Token{Type: token.LBRACKET, Literal: "["}, Elements: []Expression{}},
+			},
+		},
+	}
+
+	if pattern.String() != "{host, port: p, tags: [first] = []}" {
+		t.Errorf("pattern.String() wrong. got=%s", pattern.String())
+	}
+
+	if pattern.TokenLiteral() != "{" {
+		t.Errorf("pattern.TokenLiteral() wrong. got=%s", pattern.TokenLiteral())
+	}
+
+	if pattern.Line() != 1 {
+		t.Errorf("pattern.Line() wrong. got=%d", pattern.Line())
+	}
+
+	if pattern.Column() != 5 {
+		t.Errorf("pattern.Column() wrong. got=%d", pattern.Column())
+	}
+
+	pattern.expressionNode()
+
+	names := PatternNames(pattern)
+	expected := []string{"host", "p", "first"}
+
+	if len(names) != len(expected) {
+		t.Fatalf("PatternNames() returned wrong number of names. got=%d", len(names))
+	}
+
+	for i, name := range expected {
+		if names[i].Value != name {
+			t.Errorf("PatternNames()[%d] wrong. want=%s, got=%s", i, name, names[i].Value)
+		}
+	}
+
+	statement := &VariableStatement{
+		Token:   token.Token{Type: token.CONST, Literal: "const"},
+		Pattern: pattern,
+		Value:   newTestIdentifier("config"),
+	}
+
+	if statement.String() != "const {host, port: p, tags: [first] = []} = config;" {
+		t.Errorf("statement.String() wrong. got=%s", statement.String())
+	}
+
+	if len(statement.Names()) != 3 {
+		t.Errorf("statement.Names() returned wrong number of names. got=%d", len(statement.Names()))
+	}
+
+	export := &ExportStatement{Token: token.Token{Type: token.EXPORT, Literal: "export"}, Statement: statement}
+
+	if export.Name() != nil {
+		t.Errorf("export.Name() should be nil for destructured variables. got=%v", export.Name())
+	}
+
+	if len(export.Names()) != 3 {
+		t.Errorf("export.Names() returned wrong number of names. got=%d", len(export.Names()))
+	}
+}
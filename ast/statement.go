@@ -32,9 +32,10 @@ func (es *ExpressionStatement) Line() int   { return es.Token.Line }
 func (es *ExpressionStatement) Column() int { return es.Token.Column }
 
 type VariableStatement struct {
-	Token token.Token
-	Name  *Identifier
-	Value Expression
+	Token   token.Token
+	Name    *Identifier
+	Pattern Expression // ArrayPattern or HashPattern when destructuring, in which case Name is nil
+	Value   Expression
 }
 
 func (vs *VariableStatement) statementNode()       {}
@@ -45,7 +46,13 @@ func (vs *VariableStatement) String() string {
 	var out bytes.Buffer
 
 	out.WriteString(vs.TokenLiteral() + " ")
-	out.WriteString(vs.Name.String())
+
+	if vs.Pattern != nil {
+		out.WriteString(vs.Pattern.String())
+	} else {
+		out.WriteString(vs.Name.String())
+	}
+
 	out.WriteString(" = ")
 
 	if vs.Value != nil {
@@ -59,9 +66,21 @@ func (vs *VariableStatement) String() string {
 func (vs *VariableStatement) Line() int   { return vs.Token.Line }
 func (vs *VariableStatement) Column() int { return vs.Token.Column }
 
+/*
+Get all the names the variable statement defines
+*/
+func (vs *VariableStatement) Names() []*Identifier {
+	if vs.Pattern != nil {
+		return PatternNames(vs.Pattern)
+	}
+
+	return []*Identifier{vs.Name}
+}
+
 type ReturnStatement struct {
 	Token       token.Token
 	ReturnValue Expression
+	TailCall    bool // If the return value is a call, or a ternary with a call in a branch, that can replace the call of the function, set by the resolver
 }
 
 func (rs *ReturnStatement) statementNode()       {}
@@ -86,6 +105,7 @@ type BlockStatement struct {
 	Parent     Scope
 	Token      token.Token // the { token
 	Statements []Statement
+	Slots      int // The amount of names declared directly in the block, set by the resolver
 }
 
 func (bs *BlockStatement) statementNode()       {}
@@ -183,10 +203,46 @@ func (fs *ForStatement) GetScopeStatements() []Statement {
 	return fs.Statements
 }
 
+type ForInStatement struct {
+	Token    token.Token // The 'for' token
+	Key      *Identifier // The index or key of the current element, nil when only the element is used
+	Value    *Identifier // The current element, or the key when iterating over an object without a Key
+	Iterable Expression
+	Body     *BlockStatement
+	Slots    int // The amount of loop variables, set by the resolver
+}
+
+func (fs *ForInStatement) statementNode()       {}
+func (fs *ForInStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForInStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+
+	if fs.Key != nil {
+		out.WriteString(fs.Key.String())
+		out.WriteString(", ")
+	}
+
+	out.WriteString(fs.Value.String())
+	out.WriteString(" in ")
+	out.WriteString(fs.Iterable.String())
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+func (fs *ForInStatement) Line() int   { return fs.Token.Line }
+func (fs *ForInStatement) Column() int { return fs.Token.Column }
+
 type FunctionStatement struct {
 	Token     token.Token // the 'func' token
 	Name      *Identifier
 	Arguments []*Identifier
+	Defaults  map[string]Expression // Default values of optional arguments by argument name
+	Rest      *Identifier           // The ...rest argument that collects any remaining arguments, nil if there is none
+	Patterns  map[string]Expression // Destructuring patterns of arguments by argument name
+	Slots     int                   // The amount of names bound by the arguments, set by the resolver
 
 	Body *BlockStatement
 }
@@ -196,17 +252,11 @@ func (fs *FunctionStatement) TokenLiteral() string { return fs.Token.Literal }
 func (fs *FunctionStatement) String() string {
 	var out bytes.Buffer
 
-	args := []string{}
-
-	for _, p := range fs.Arguments {
-		args = append(args, p.String())
-	}
-
 	out.WriteString(fs.TokenLiteral())
 	out.WriteString(" ")
 	out.WriteString(fs.Name.String())
 	out.WriteString("(")
-	out.WriteString(strings.Join(args, ", "))
+	out.WriteString(FormatArguments(fs.Arguments, fs.Defaults, fs.Rest))
 	out.WriteString(") ")
 
 	out.WriteString(fs.Body.String())
@@ -215,3 +265,165 @@ func (fs *FunctionStatement) String() string {
 }
 func (fs *FunctionStatement) Line() int   { return fs.Token.Line }
 func (fs *FunctionStatement) Column() int { return fs.Token.Column }
+
+type StructStatement struct {
+	Token    token.Token // the 'struct' token
+	Name     *Identifier
+	Fields   []*Identifier
+	Defaults map[string]Expression // Default values of optional fields by field name
+	Methods  []*FunctionStatement
+}
+
+func (ss *StructStatement) statementNode()       {}
+func (ss *StructStatement) TokenLiteral() string { return ss.Token.Literal }
+func (ss *StructStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ss.TokenLiteral())
+	out.WriteString(" ")
+	out.WriteString(ss.Name.String())
+	out.WriteString(" {")
+
+	if len(ss.Fields) > 0 {
+		out.WriteString(" ")
+		out.WriteString(FormatArguments(ss.Fields, ss.Defaults, nil))
+		out.WriteString(";")
+	}
+
+	for _, method := range ss.Methods {
+		out.WriteString(" ")
+		out.WriteString(method.String())
+	}
+
+	out.WriteString(" }")
+
+	return out.String()
+}
+func (ss *StructStatement) Line() int   { return ss.Token.Line }
+func (ss *StructStatement) Column() int { return ss.Token.Column }
+
+type TryStatement struct {
+	Token token.Token // the 'try' token
+	Block *BlockStatement
+
+	CatchParameter *Identifier // optional name the caught error is bound to
+	CatchBlock     *BlockStatement
+	FinallyBlock   *BlockStatement
+	CatchSlots     int // 1 when there is a catch parameter, set by the resolver
+}
+
+func (ts *TryStatement) statementNode()       {}
+func (ts *TryStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *TryStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("try ")
+	out.WriteString(ts.Block.String())
+
+	if ts.CatchBlock != nil {
+		out.WriteString(" catch ")
+
+		if ts.CatchParameter != nil {
+			out.WriteString("(")
+			out.WriteString(ts.CatchParameter.String())
+			out.WriteString(") ")
+		}
+
+		out.WriteString(ts.CatchBlock.String())
+	}
+
+	if ts.FinallyBlock != nil {
+		out.WriteString(" finally ")
+		out.WriteString(ts.FinallyBlock.String())
+	}
+
+	return out.String()
+}
+func (ts *TryStatement) Line() int   { return ts.Token.Line }
+func (ts *TryStatement) Column() int { return ts.Token.Column }
+
+type ThrowStatement struct {
+	Token token.Token // the 'throw' token
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode()       {}
+func (ts *ThrowStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *ThrowStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ts.TokenLiteral() + " ")
+
+	if ts.Value != nil {
+		out.WriteString(ts.Value.String())
+	}
+
+	out.WriteString(";")
+
+	return out.String()
+}
+func (ts *ThrowStatement) Line() int   { return ts.Token.Line }
+func (ts *ThrowStatement) Column() int { return ts.Token.Column }
+
+type ImportStatement struct {
+	Token token.Token // the 'import' token
+	Path  *StringLiteral
+	Name  *Identifier // the name the module is bound to
+}
+
+func (is *ImportStatement) statementNode()       {}
+func (is *ImportStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *ImportStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(is.TokenLiteral() + " ")
+	out.WriteString(is.Path.String())
+	out.WriteString(" as ")
+	out.WriteString(is.Name.String())
+	out.WriteString(";")
+
+	return out.String()
+}
+func (is *ImportStatement) Line() int   { return is.Token.Line }
+func (is *ImportStatement) Column() int { return is.Token.Column }
+
+type ExportStatement struct {
+	Token     token.Token // the 'export' token
+	Statement Statement   // the exported FunctionStatement or VariableStatement
+}
+
+func (es *ExportStatement) statementNode()       {}
+func (es *ExportStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExportStatement) String() string {
+	return es.TokenLiteral() + " " + es.Statement.String()
+}
+func (es *ExportStatement) Line() int   { return es.Token.Line }
+func (es *ExportStatement) Column() int { return es.Token.Column }
+
+/*
+Get the name of the exported function or variable, nil for destructured variables
+*/
+func (es *ExportStatement) Name() *Identifier {
+	switch statement := es.Statement.(type) {
+	case *FunctionStatement:
+		return statement.Name
+	case *VariableStatement:
+		return statement.Name
+	}
+
+	return nil
+}
+
+/*
+Get the names of all the exported functions and variables
+*/
+func (es *ExportStatement) Names() []*Identifier {
+	switch statement := es.Statement.(type) {
+	case *FunctionStatement:
+		return []*Identifier{statement.Name}
+	case *VariableStatement:
+		return statement.Names()
+	}
+
+	return nil
+}
@@ -289,6 +289,131 @@ func TestWhileStatement(t *testing.T) {
 	statement.statementNode()
 }
 
+func TestForInStatement(t *testing.T) {
+	program := NewProgram()
+
+	statement := &ForInStatement{
+		Token: token.Token{
+			Type:    token.FOR,
+			Literal: "for",
+			Line:    1,
+			Column:  1,
+		},
+		Value: &Identifier{
+			Token: token.Token{
+				Type:    token.IDENT,
+				Literal: "x",
+			},
+			Value: "x",
+		},
+		Iterable: &Identifier{
+			Token: token.Token{
+				Type:    token.IDENT,
+				Literal: "foo",
+			},
+			Value: "foo",
+		},
+		Body: &BlockStatement{
+			Token: token.Token{
+				Type:    token.LBRACE,
+				Literal: "{",
+			},
+			Statements: []Statement{},
+			Parent:     program,
+		},
+	}
+
+	if statement.String() != "for (x in foo) {\n}" {
+		t.Errorf("statement.String() wrong. got=%q", statement.String())
+	}
+
+	statement.Key = &Identifier{
+		Token: token.Token{
+			Type:    token.IDENT,
+			Literal: "i",
+		},
+		Value: "i",
+	}
+
+	if statement.String() != "for (i, x in foo) {\n}" {
+		t.Errorf("statement.String() wrong. got=%q", statement.String())
+	}
+
+	if statement.TokenLiteral() != "for" {
+		t.Errorf("statement.TokenLiteral() wrong. got=%q", statement.TokenLiteral())
+	}
+
+	if statement.Line() != 1 {
+		t.Errorf("statement.Line() wrong. got=%d", statement.Line())
+	}
+
+	if statement.Column() != 1 {
+		t.Errorf("statement.Column() wrong. got=%d", statement.Column())
+	}
+
+	statement.statementNode()
+}
+
+func TestStructStatement(t *testing.T) {
+	program := NewProgram()
+
+	statement := &StructStatement{
+		Token: token.Token{
+			Type:    token.STRUCT,
+			Literal: "struct",
+			Line:    1,
+			Column:  1,
+		},
+		Name: &Identifier{
+			Token: token.Token{
+				Type:    token.IDENT,
+				Literal: "Point",
+			},
+			Value: "Point",
+		},
+		Defaults: map[string]Expression{},
+	}
+
+	if statement.String() != "struct Point { }" {
+		t.Errorf("statement.String() wrong. got=%q", statement.String())
+	}
+
+	statement.Fields = []*Identifier{
+		{Token: token.Token{Type: token.IDENT, Literal: "x"}, Value: "x"},
+		{Token: token.Token{Type: token.IDENT, Literal: "y"}, Value: "y"},
+	}
+	statement.Defaults["y"] = &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "0"}, Value: 0}
+	statement.Methods = []*FunctionStatement{
+		{
+			Token: token.Token{Type: token.FUNCTION, Literal: "func"},
+			Name:  &Identifier{Token: token.Token{Type: token.IDENT, Literal: "norm"}, Value: "norm"},
+			Body: &BlockStatement{
+				Token:      token.Token{Type: token.LBRACE, Literal: "{"},
+				Statements: []Statement{},
+				Parent:     program,
+			},
+		},
+	}
+
+	if statement.String() != "struct Point { x, y = 0; func norm() {\n} }" {
+		t.Errorf("statement.String() wrong. got=%q", statement.String())
+	}
+
+	if statement.TokenLiteral() != "struct" {
+		t.Errorf("statement.TokenLiteral() wrong. got=%q", statement.TokenLiteral())
+	}
+
+	if statement.Line() != 1 {
+		t.Errorf("statement.Line() wrong. got=%d", statement.Line())
+	}
+
+	if statement.Column() != 1 {
+		t.Errorf("statement.Column() wrong. got=%d", statement.Column())
+	}
+
+	statement.statementNode()
+}
+
 func TestForStatement(t *testing.T) {
 	program := NewProgram()
 
@@ -498,3 +623,206 @@ func TestFunctionStatement(t *testing.T) {
 
 	statement.statementNode()
 }
+
+func TestTryStatement(t *testing.T) {
+	statement := &TryStatement{
+		Token: token.Token{
+			Type:    token.TRY,
+			Literal: "try",
+			Line:    1,
+			Column:  1,
+		},
+		Block: &BlockStatement{
+			Token:      token.Token{Type: token.LBRACE, Literal: "{"},
+			Statements: []Statement{},
+		},
+		CatchParameter: &Identifier{
+			Token: token.Token{
+				Type:    token.IDENT,
+				Literal: "err",
+			},
+			Value: "err",
+		},
+		CatchBlock: &BlockStatement{
+			Token:      token.Token{Type: token.LBRACE, Literal: "{"},
+			Statements: []Statement{},
+		},
+		FinallyBlock: &BlockStatement{
+			Token:      token.Token{Type: token.LBRACE, Literal: "{"},
+			Statements: []Statement{},
+		},
+	}
+
+	expected := "try {\n} catch (err) {\n} finally {\n}"
+
+	if statement.String() != expected {
+		t.Errorf("statement.String() wrong. got=%q", statement.String())
+	}
+
+	if statement.TokenLiteral() != "try" {
+		t.Errorf("statement.TokenLiteral() wrong. got=%q", statement.TokenLiteral())
+	}
+
+	if statement.Line() != 1 {
+		t.Errorf("statement.Line() wrong. got=%d", statement.Line())
+	}
+
+	if statement.Column() != 1 {
+		t.Errorf("statement.Column() wrong. got=%d", statement.Column())
+	}
+
+	statement.CatchParameter = nil
+	statement.FinallyBlock = nil
+
+	if statement.String() != "try {\n} catch {\n}" {
+		t.Errorf("statement.String() wrong. got=%q", statement.String())
+	}
+
+	statement.statementNode()
+}
+
+func TestThrowStatement(t *testing.T) {
+	statement := &ThrowStatement{
+		Token: token.Token{
+			Type:    token.THROW,
+			Literal: "throw",
+			Line:    1,
+			Column:  1,
+		},
+		Value: &StringLiteral{
+			Token: token.Token{
+				Type:    token.STRING,
+				Literal: "oops",
+			},
+			Value: "oops",
+		},
+	}
+
+	if statement.String() != `throw "oops";` {
+		t.Errorf("statement.String() wrong. got=%q", statement.String())
+	}
+
+	if statement.TokenLiteral() != "throw" {
+		t.Errorf("statement.TokenLiteral() wrong. got=%q", statement.TokenLiteral())
+	}
+
+	if statement.Line() != 1 {
+		t.Errorf("statement.Line() wrong. got=%d", statement.Line())
+	}
+
+	if statement.Column() != 1 {
+		t.Errorf("statement.Column() wrong. got=%d", statement.Column())
+	}
+
+	statement.statementNode()
+}
+
+func TestImportStatement(t *testing.T) {
+	statement := &ImportStatement{
+		Token: token.Token{
+			Type:    token.IMPORT,
+			Literal: "import",
+			Line:    1,
+			Column:  1,
+		},
+		Path: &StringLiteral{
+			Token: token.Token{
+				Type:    token.STRING,
+				Literal: "./utils.vorn",
+			},
+			Value: "./utils.vorn",
+		},
+		Name: &Identifier{
+			Token: token.Token{
+				Type:    token.IDENT,
+				Literal: "utils",
+			},
+			Value: "utils",
+		},
+	}
+
+	if statement.String() != `import "./utils.vorn" as utils;` {
+		t.Errorf("statement.String() wrong. got=%q", statement.String())
+	}
+
+	if statement.TokenLiteral() != "import" {
+		t.Errorf("statement.TokenLiteral() wrong. got=%q", statement.TokenLiteral())
+	}
+
+	if statement.Line() != 1 {
+		t.Errorf("statement.Line() wrong. got=%d", statement.Line())
+	}
+
+	if statement.Column() != 1 {
+		t.Errorf("statement.Column() wrong. got=%d", statement.Column())
+	}
+
+	statement.statementNode()
+}
+
+func TestExportStatement(t *testing.T) {
+	name := &Identifier{
+		Token: token.Token{
+			Type:    token.IDENT,
+			Literal: "X",
+		},
+		Value: "X",
+	}
+
+	statement := &ExportStatement{
+		Token: token.Token{
+			Type:    token.EXPORT,
+			Literal: "export",
+			Line:    1,
+			Column:  1,
+		},
+		Statement: &VariableStatement{
+			Token: token.Token{
+				Type:    token.CONST,
+				Literal: "const",
+			},
+			Name: name,
+			Value: &IntegerLiteral{
+				Token: token.Token{
+					Type:    token.INT,
+					Literal: "1",
+				},
+				Value: 1,
+			},
+		},
+	}
+
+	if statement.String() != "export const X = 1;" {
+		t.Errorf("statement.String() wrong. got=%q", statement.String())
+	}
+
+	if statement.TokenLiteral() != "export" {
+		t.Errorf("statement.TokenLiteral() wrong. got=%q", statement.TokenLiteral())
+	}
+
+	if statement.Name() != name {
+		t.Errorf("statement.Name() wrong. got=%v", statement.Name())
+	}
+
+	if statement.Line() != 1 {
+		t.Errorf("statement.Line() wrong. got=%d", statement.Line())
+	}
+
+	if statement.Column() != 1 {
+		t.Errorf("statement.Column() wrong. got=%d", statement.Column())
+	}
+
+	statement.Statement = &FunctionStatement{Name: name}
+
+	if statement.Name() != name {
+		t.Errorf("statement.Name() wrong. got=%v", statement.Name())
+	}
+
+	statement.Statement = &ReturnStatement{}
+
+	if statement.Name() != nil {
+		t.Errorf("statement.Name() wrong. got=%v", statement.Name())
+	}
+
+	statement.statementNode()
+}
@@ -0,0 +1,261 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+type Instructions []byte
+
+type Opcode byte
+
+const (
+	OpConstant Opcode = iota // Push a constant
+	OpNull                   // Push null
+	OpTrue                   // Push true
+	OpFalse                  // Push false
+	OpNil                    // Push the missing value of statements that do not produce a value
+	OpPop                    // Pop the top of the stack
+
+	OpJump          // Jump to an instruction
+	OpJumpNotTruthy // Pop the top of the stack and jump if it is not truthy
+	OpAnd           // Jump and keep the top of the stack if it is not truthy, pop it otherwise
+	OpOr            // Jump and keep the top of the stack if it is truthy, pop it otherwise
+
+	OpGetName     // Push the value of an identifier
+	OpDeclare     // Check that the names of a variable or function statement are not defined yet
+	OpDefine      // Pop a value and define it as the name of a variable or function statement
+	OpGetVariable // Push the current value of the variable of a reassignment or ++ and --
+	OpAssign      // Pop a value and assign it to the variable of a reassignment or ++ and --
+	OpIncrement   // Pop a number and push the value of a ++ or -- expression and the updated number
+	OpGetGlobal   // Push the value of a global variable, like OpGetName or OpGetVariable depending on the node
+	OpSetGlobal   // Pop a value and assign it to a global variable, like OpAssign
+
+	OpSetMember       // Pop a value, key and container and assign the value to the member of the container
+	OpIncrementMember // Pop a key and container and apply a ++ or -- operator to the member of the container
+
+	OpPrefix // Apply a prefix operator to the top of the stack
+	OpInfix  // Apply an infix operator to the top two values of the stack
+	OpIndex  // Index the second value of the stack with the top of the stack
+
+	OpArray    // Create an array of the top values of the stack
+	OpHash     // Create an object of the top key and value pairs of the stack
+	OpToString // Convert the top of the stack to a string
+	OpTemplate // Create the string of a template literal from the top strings of the stack
+
+	OpClosure       // Create a function from a compiled function and the current environment
+	OpCall          // Call the function below the arguments on the stack
+	OpTailCall      // Call the function below the arguments on the stack in place of the current function
+	OpChainCall     // Call a method on the value below the arguments on the stack
+	OpTailChainCall // Call a method on the value below the arguments on the stack in place of the current function
+	OpChainProperty // Get a property of the top of the stack
+	OpReturnValue   // Return the top of the stack from the current function
+
+	OpRange       // Start iterating over the numbers of a call of range and jump to the target when the function below the arguments is range
+	OpIterate     // Pop a value and start iterating over it for a for-in loop
+	OpIterNext    // Push the next value and key of the innermost iterator, or jump to the target when there are none left
+	OpPopIterator // Stop the innermost iterator
+
+	OpPushScope // Enter a new frame with the given amount of slots enclosed by the current environment
+	OpPopScope  // Leave the current environment
+
+	OpEval       // Evaluate a node with the evaluator and push the result
+	OpControl    // Jump to the break, continue or return target if the top of the stack is a break, continue or return value
+	OpWrapReturn // Wrap the top of the stack in a return value
+
+	OpSetHandler // Handle errors until the matching OpPopHandler by pushing the error and continuing at the target
+	OpPopHandler // Stop handling errors
+	OpThrow      // Pop a value and throw it as an error
+	OpCatch      // Pop an error and push the object the parameter of a catch block is bound to
+	OpFinally    // Pop the value of a finally block and the try statement and push the one that takes precedence
+)
+
+type Definition struct {
+	Name          string
+	OperandWidths []int
+	HasNode       bool // If the first operand is the index of the node the instruction belongs to
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant: {"OpConstant", []int{4}, false},
+	OpNull:     {"OpNull", []int{}, false},
+	OpTrue:     {"OpTrue", []int{}, false},
+	OpFalse:    {"OpFalse", []int{}, false},
+	OpNil:      {"OpNil", []int{}, false},
+	OpPop:      {"OpPop", []int{}, false},
+
+	OpJump:          {"OpJump", []int{4}, false},
+	OpJumpNotTruthy: {"OpJumpNotTruthy", []int{4}, false},
+	OpAnd:           {"OpAnd", []int{4}, false},
+	OpOr:            {"OpOr", []int{4}, false},
+
+	OpGetName:     {"OpGetName", []int{4}, true},
+	OpDeclare:     {"OpDeclare", []int{4}, true},
+	OpDefine:      {"OpDefine", []int{4}, true},
+	OpGetVariable: {"OpGetVariable", []int{4}, true},
+	OpAssign:      {"OpAssign", []int{4}, true},
+	OpIncrement:   {"OpIncrement", []int{4}, true},
+	OpGetGlobal:   {"OpGetGlobal", []int{4, 4}, true},
+	OpSetGlobal:   {"OpSetGlobal", []int{4, 4}, true},
+
+	OpSetMember:       {"OpSetMember", []int{4}, true},
+	OpIncrementMember: {"OpIncrementMember", []int{4}, true},
+
+	OpPrefix: {"OpPrefix", []int{4}, true},
+	OpInfix:  {"OpInfix", []int{4}, true},
+	OpIndex:  {"OpIndex", []int{4}, true},
+
+	OpArray:    {"OpArray", []int{4, 2}, true},
+	OpHash:     {"OpHash", []int{4, 2}, true},
+	OpToString: {"OpToString", []int{4}, true},
+	OpTemplate: {"OpTemplate", []int{4, 2}, true},
+
+	OpClosure:       {"OpClosure", []int{4}, false},
+	OpCall:          {"OpCall", []int{4, 2}, true},
+	OpTailCall:      {"OpTailCall", []int{4, 2}, true},
+	OpChainCall:     {"OpChainCall", []int{4, 2}, true},
+	OpTailChainCall: {"OpTailChainCall", []int{4, 2}, true},
+	OpChainProperty: {"OpChainProperty", []int{4}, true},
+	OpReturnValue:   {"OpReturnValue", []int{}, false},
+
+	OpRange:       {"OpRange", []int{4, 2, 4}, true},
+	OpIterate:     {"OpIterate", []int{4}, true},
+	OpIterNext:    {"OpIterNext", []int{4}, false},
+	OpPopIterator: {"OpPopIterator", []int{}, false},
+
+	OpPushScope: {"OpPushScope", []int{2}, false},
+	OpPopScope:  {"OpPopScope", []int{}, false},
+
+	OpEval:       {"OpEval", []int{4}, true},
+	OpControl:    {"OpControl", []int{4, 4, 4}, false},
+	OpWrapReturn: {"OpWrapReturn", []int{4}, true},
+
+	OpSetHandler: {"OpSetHandler", []int{4}, false},
+	OpPopHandler: {"OpPopHandler", []int{}, false},
+	OpThrow:      {"OpThrow", []int{4}, true},
+	OpCatch:      {"OpCatch", []int{4}, true},
+	OpFinally:    {"OpFinally", []int{}, false},
+}
+
+/*
+Get the definition of an opcode.
+
+Returns an error if the opcode is not defined.
+*/
+func Lookup(op byte) (*Definition, error) {
+	definition, ok := definitions[Opcode(op)]
+
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+
+	return definition, nil
+}
+
+/*
+Create an instruction from an opcode and its operands
+*/
+func Make(op Opcode, operands ...int) []byte {
+	definition, ok := definitions[op]
+
+	if !ok { // coverage-ignore
+		return []byte{}
+	}
+
+	length := 1
+
+	for _, width := range definition.OperandWidths {
+		length += width
+	}
+
+	instruction := make([]byte, length)
+	instruction[0] = byte(op)
+	offset := 1
+
+	for i, operand := range operands {
+		width := definition.OperandWidths[i]
+
+		switch width {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(operand))
+		case 4:
+			binary.BigEndian.PutUint32(instruction[offset:], uint32(operand))
+		}
+
+		offset += width
+	}
+
+	return instruction
+}
+
+/*
+Read the operands of an instruction using the definition of its opcode.
+
+Returns the operands and the amount of bytes that were read.
+*/
+func ReadOperands(definition *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(definition.OperandWidths))
+	offset := 0
+
+	for i, width := range definition.OperandWidths {
+		switch width {
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 4:
+			operands[i] = int(ReadUint32(ins[offset:]))
+		}
+
+		offset += width
+	}
+
+	return operands, offset
+}
+
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+func ReadUint32(ins Instructions) uint32 {
+	return binary.BigEndian.Uint32(ins)
+}
+
+/*
+Get a human readable listing of the instructions, one instruction per line prefixed with its offset
+*/
+func (ins Instructions) String() string {
+	var out bytes.Buffer
+
+	i := 0
+
+	for i < len(ins) {
+		definition, err := Lookup(ins[i])
+
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i++
+
+			continue
+		}
+
+		operands, read := ReadOperands(definition, ins[i+1:])
+
+		fmt.Fprintf(&out, "%04d %s\n", i, formatInstruction(definition, operands))
+
+		i += 1 + read
+	}
+
+	return out.String()
+}
+
+func formatInstruction(definition *Definition, operands []int) string {
+	var out bytes.Buffer
+
+	out.WriteString(definition.Name)
+
+	for _, operand := range operands {
+		fmt.Fprintf(&out, " %d", operand)
+	}
+
+	return out.String()
+}
@@ -0,0 +1,1039 @@
+package compiler
+
+import (
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/object"
+	"github.com/iskandervdh/vorn/token"
+)
+
+/*
+A function body or the main program lowered to instructions
+*/
+type Function struct {
+	Node         ast.Node // The FunctionLiteral or FunctionStatement, nil for the main program
+	Instructions Instructions
+	Bytecode     *Bytecode // The bytecode the constants and nodes the instructions refer to belong to
+}
+
+/*
+The result of compiling a program
+*/
+type Bytecode struct {
+	Main      *Function
+	Constants []object.Object
+	Nodes     []ast.Node // Nodes used for errors and the nodes that are evaluated by the evaluator
+	Functions []*Function
+	Globals   []string // The names of the global variables, by the index instructions refer to them with
+}
+
+type controlKind int
+
+const (
+	loopControl    controlKind = iota // Break and continue jump out of or to the start of a loop
+	captureControl                    // Break and continue become the value of the surrounding expression or statement
+)
+
+type transferKind int
+
+const (
+	breakTransfer transferKind = iota
+	continueTransfer
+	returnTransfer
+)
+
+/*
+Where a break, continue or return inside a block ends up, which mirrors how
+the evaluator passes the break, continue and return values on to the parent node
+*/
+type control struct {
+	kind          controlKind
+	scopes        int   // The amount of environments that were pushed when the control started
+	iterators     int   // The amount of iterators that were started when the control started
+	captureReturn bool  // If return values are captured as well, only used by captureControl
+	breaks        []int // Positions of jumps to the end of the loop or capture
+	continues     []int // Positions of jumps to the next iteration of the loop
+}
+
+/*
+The instructions of the function that is being compiled
+*/
+type compilationScope struct {
+	instructions Instructions
+	controls     []*control
+	scopes       int // The amount of environments pushed by the blocks that are being compiled
+	iterators    int // The amount of iterators started by the for-in loops that are being compiled
+	outer        *compilationScope
+}
+
+type Compiler struct {
+	bytecode *Bytecode
+	scope    *compilationScope
+	globals  map[string]int // The indexes of the global variables by name
+}
+
+func New() *Compiler {
+	return &Compiler{
+		bytecode: &Bytecode{},
+		globals:  map[string]int{},
+	}
+}
+
+/*
+Compile a program to bytecode that can be run by the vm. The program has to be resolved by the resolver first.
+
+Nodes the compiler has no instructions for are evaluated by the evaluator,
+so every program that can be evaluated can also be compiled.
+*/
+func Compile(program *ast.Program) *Bytecode {
+	c := New()
+	c.scope = &compilationScope{}
+
+	for _, statement := range program.Statements {
+		// A break or continue that is not inside a loop only ends the current statement of the program
+		c.pushControl(captureControl, false)
+		c.compileStatement(statement, true)
+		c.popControl()
+
+		c.emit(OpPop)
+	}
+
+	c.bytecode.Main = &Function{Instructions: c.scope.instructions, Bytecode: c.bytecode}
+
+	return c.bytecode
+}
+
+func (c *Compiler) emit(op Opcode, operands ...int) int {
+	position := len(c.scope.instructions)
+	c.scope.instructions = append(c.scope.instructions, Make(op, operands...)...)
+
+	return position
+}
+
+/*
+Set the operands of the instruction at position, used for jumps to instructions that were not compiled yet
+*/
+func (c *Compiler) changeOperands(position int, operands ...int) {
+	op := Opcode(c.scope.instructions[position])
+	copy(c.scope.instructions[position:], Make(op, operands...))
+}
+
+func (c *Compiler) patchJumps(positions []int, target int) {
+	for _, position := range positions {
+		c.changeOperands(position, target)
+	}
+}
+
+func (c *Compiler) addConstant(obj object.Object) int {
+	c.bytecode.Constants = append(c.bytecode.Constants, obj)
+
+	return len(c.bytecode.Constants) - 1
+}
+
+func (c *Compiler) addNode(node ast.Node) int {
+	c.bytecode.Nodes = append(c.bytecode.Nodes, node)
+
+	return len(c.bytecode.Nodes) - 1
+}
+
+/*
+Get the index of the global variable with the given name, adding it when it was not used before
+*/
+func (c *Compiler) addGlobal(name string) int {
+	if index, ok := c.globals[name]; ok {
+		return index
+	}
+
+	c.bytecode.Globals = append(c.bytecode.Globals, name)
+	c.globals[name] = len(c.bytecode.Globals) - 1
+
+	return len(c.bytecode.Globals) - 1
+}
+
+/*
+Compile getting the current value of the variable of a reassignment or ++ and -- expression
+*/
+func (c *Compiler) compileGetVariable(node ast.Node, name *ast.Identifier) {
+	if name.Local {
+		c.emit(OpGetVariable, c.addNode(node))
+	} else {
+		c.emit(OpGetGlobal, c.addNode(node), c.addGlobal(name.Value))
+	}
+}
+
+/*
+Compile assigning the value on top of the stack to the variable of a reassignment or ++ and -- expression
+*/
+func (c *Compiler) compileAssign(node ast.Node, name *ast.Identifier) {
+	if name.Local {
+		c.emit(OpAssign, c.addNode(node))
+	} else {
+		c.emit(OpSetGlobal, c.addNode(node), c.addGlobal(name.Value))
+	}
+}
+
+func (c *Compiler) pushControl(kind controlKind, captureReturn bool) *control {
+	ctrl := &control{kind: kind, scopes: c.scope.scopes, iterators: c.scope.iterators, captureReturn: captureReturn}
+	c.scope.controls = append(c.scope.controls, ctrl)
+
+	return ctrl
+}
+
+/*
+Stop the innermost control, pointing the jumps to the end of a capture to the current position
+*/
+func (c *Compiler) popControl() *control {
+	ctrl := c.scope.controls[len(c.scope.controls)-1]
+	c.scope.controls = c.scope.controls[:len(c.scope.controls)-1]
+
+	if ctrl.kind == captureControl {
+		c.patchJumps(ctrl.breaks, len(c.scope.instructions))
+	}
+
+	return ctrl
+}
+
+/*
+Compile a break, continue or return of the break, continue or return value on top of the stack.
+
+Return values are only wrapped when they are captured, a return statement returns its value directly.
+*/
+func (c *Compiler) transfer(kind transferKind, wrapped bool, node ast.Node) {
+	for i := len(c.scope.controls) - 1; i >= 0; i-- {
+		ctrl := c.scope.controls[i]
+
+		switch ctrl.kind {
+		case loopControl:
+			if kind == returnTransfer {
+				continue
+			}
+
+			c.emit(OpPop)
+			c.popScopes(ctrl.scopes, ctrl.iterators)
+
+			if kind == breakTransfer {
+				ctrl.breaks = append(ctrl.breaks, c.emit(OpJump, 0))
+			} else {
+				ctrl.continues = append(ctrl.continues, c.emit(OpJump, 0))
+			}
+
+			return
+		case captureControl:
+			if kind == returnTransfer && !ctrl.captureReturn {
+				continue
+			}
+
+			if kind == returnTransfer && !wrapped {
+				c.emit(OpWrapReturn, c.addNode(node))
+			}
+
+			c.popScopes(ctrl.scopes, ctrl.iterators)
+			ctrl.breaks = append(ctrl.breaks, c.emit(OpJump, 0))
+
+			return
+		}
+	}
+
+	// Outside of loops and captures the value is returned from the function
+	c.emit(OpReturnValue)
+}
+
+/*
+Leave the environments pushed since there were scopes environments and stop the iterators started since there were
+iterators iterators, without changing the compile time counts
+*/
+func (c *Compiler) popScopes(scopes, iterators int) {
+	for i := c.scope.scopes; i > scopes; i-- {
+		c.emit(OpPopScope)
+	}
+
+	for i := c.scope.iterators; i > iterators; i-- {
+		c.emit(OpPopIterator)
+	}
+}
+
+/*
+Jump to the matching break, continue or return when the value on top of the stack is a break, continue or return value
+*/
+func (c *Compiler) emitControlCheck() {
+	check := c.emit(OpControl, 0, 0, 0)
+	skip := c.emit(OpJump, 0)
+
+	breakTarget := len(c.scope.instructions)
+	c.transfer(breakTransfer, true, nil)
+
+	continueTarget := len(c.scope.instructions)
+	c.transfer(continueTransfer, true, nil)
+
+	returnTarget := len(c.scope.instructions)
+	c.transfer(returnTransfer, true, nil)
+
+	c.changeOperands(check, breakTarget, continueTarget, returnTarget)
+	c.changeOperands(skip, len(c.scope.instructions))
+}
+
+/*
+Compile a statement. When needValue is true the value of the statement is left on the stack,
+otherwise only break, continue and return values have an effect.
+*/
+func (c *Compiler) compileStatement(statement ast.Statement, needValue bool) {
+	switch statement := statement.(type) {
+	case *ast.ExpressionStatement:
+		c.compileExpressionStatement(statement.Expression, needValue)
+
+	case *ast.VariableStatement:
+		if statement.Pattern != nil {
+			c.compileEval(statement, needValue)
+
+			return
+		}
+
+		node := c.addNode(statement)
+		c.emit(OpDeclare, node)
+		c.compileExpression(statement.Value)
+		c.emit(OpDefine, node)
+		c.emitNil(needValue)
+
+	case *ast.FunctionStatement:
+		node := c.addNode(statement)
+		c.emit(OpDeclare, node)
+		c.emit(OpClosure, c.compileFunction(statement, statement.Body))
+		c.emit(OpDefine, node)
+		c.emitNil(needValue)
+
+	case *ast.ReturnStatement:
+		if statement.TailCall && !c.capturesReturn() {
+			c.compileTailExpression(statement.ReturnValue)
+		} else {
+			c.compileExpression(statement.ReturnValue)
+		}
+
+		c.transfer(returnTransfer, false, statement)
+
+	case *ast.BlockStatement:
+		c.compileBlock(statement)
+		c.emitNull(needValue)
+
+	case *ast.WhileStatement:
+		c.compileWhileStatement(statement)
+		c.emitNull(needValue)
+
+	case *ast.ForStatement:
+		c.compileForStatement(statement)
+		c.emitNull(needValue)
+
+	case *ast.ForInStatement:
+		c.compileForInStatement(statement)
+		c.emitNull(needValue)
+
+	case *ast.TryStatement:
+		c.compileTryStatement(statement)
+		c.emitControlCheck()
+
+		if !needValue {
+			c.emit(OpPop)
+		}
+
+	case *ast.ThrowStatement:
+		c.compileExpression(statement.Value)
+		c.emit(OpThrow, c.addNode(statement))
+		c.emitNil(needValue)
+
+	default:
+		c.compileEval(statement, needValue)
+	}
+}
+
+func (c *Compiler) compileExpressionStatement(expression ast.Expression, needValue bool) {
+	switch expression := expression.(type) {
+	case *ast.BreakExpression:
+		c.emit(OpConstant, c.addConstant(object.NewBreak(expression)))
+		c.transfer(breakTransfer, true, expression)
+
+	case *ast.ContinueExpression:
+		c.emit(OpConstant, c.addConstant(object.NewContinue(expression)))
+		c.transfer(continueTransfer, true, expression)
+
+	case *ast.IfExpression:
+		c.compileIfExpression(expression, false)
+		c.emitNull(needValue)
+
+	case *ast.ReassignmentExpression:
+		c.compileReassignment(expression)
+		c.emitNil(needValue)
+
+	default:
+		c.compileExpression(expression)
+
+		if mayBeControl(expression) {
+			c.emitControlCheck()
+		}
+
+		if !needValue {
+			c.emit(OpPop)
+		}
+	}
+}
+
+/*
+Let the evaluator evaluate a statement, checking its value for a break, continue or return
+*/
+func (c *Compiler) compileEval(statement ast.Statement, needValue bool) {
+	c.emit(OpEval, c.addNode(statement))
+	c.emitControlCheck()
+
+	if !needValue {
+		c.emit(OpPop)
+	}
+}
+
+func (c *Compiler) emitNil(needValue bool) {
+	if needValue {
+		c.emit(OpNil)
+	}
+}
+
+func (c *Compiler) emitNull(needValue bool) {
+	if needValue {
+		c.emit(OpNull)
+	}
+}
+
+/*
+Check if the value of an expression can be a break, continue or return value
+*/
+func mayBeControl(expression ast.Expression) bool {
+	switch expression := expression.(type) {
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.BooleanLiteral, *ast.NullLiteral,
+		*ast.TemplateLiteral, *ast.FunctionLiteral, *ast.ArrayLiteral, *ast.HashLiteral, *ast.PrefixExpression,
+		*ast.IncrementDecrementExpression, *ast.MemberReassignmentExpression, *ast.MemberIncrementDecrementExpression:
+		return false
+	case *ast.InfixExpression:
+		// Logical operators return one of their operands
+		return expression.Operator == token.AND || expression.Operator == token.OR
+	}
+
+	return true
+}
+
+/*
+Compile the statements of a block in a new frame.
+
+Like in the evaluator the frame is only created when the resolver gave the block any slots.
+*/
+func (c *Compiler) compileBlock(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+
+	scoped := block.Slots > 0
+
+	if scoped {
+		c.emit(OpPushScope, block.Slots)
+		c.scope.scopes++
+	}
+
+	for _, statement := range block.Statements {
+		c.compileStatement(statement, false)
+	}
+
+	if scoped {
+		c.emit(OpPopScope)
+		c.scope.scopes--
+	}
+}
+
+/*
+Compile an if expression. Only when the if expression is used as a value a break, continue or return
+inside of it becomes its value, otherwise it is passed on to the surrounding statements.
+*/
+func (c *Compiler) compileIfExpression(ie *ast.IfExpression, asValue bool) {
+	c.compileExpression(ie.Condition)
+	jumpNotTruthy := c.emit(OpJumpNotTruthy, 0)
+
+	if asValue {
+		c.pushControl(captureControl, true)
+	}
+
+	c.compileBlock(ie.Consequence)
+	c.emitNull(asValue)
+	jump := c.emit(OpJump, 0)
+
+	c.changeOperands(jumpNotTruthy, len(c.scope.instructions))
+	c.compileBlock(ie.Alternative)
+	c.emitNull(asValue)
+
+	c.changeOperands(jump, len(c.scope.instructions))
+
+	if asValue {
+		c.popControl()
+	}
+}
+
+func (c *Compiler) compileWhileStatement(ws *ast.WhileStatement) {
+	start := len(c.scope.instructions)
+
+	c.compileExpression(ws.Condition)
+	jumpNotTruthy := c.emit(OpJumpNotTruthy, 0)
+
+	c.pushControl(loopControl, false)
+	c.compileBlock(ws.Consequence)
+	c.emit(OpJump, start)
+	loop := c.popControl()
+
+	end := len(c.scope.instructions)
+	c.changeOperands(jumpNotTruthy, end)
+	c.patchJumps(loop.breaks, end)
+	c.patchJumps(loop.continues, start)
+}
+
+func (c *Compiler) compileForStatement(fs *ast.ForStatement) {
+	if fs.Init != nil {
+		c.compileIgnored(func() { c.compileStatement(fs.Init, true) })
+	}
+
+	start := len(c.scope.instructions)
+
+	c.compileExpression(fs.Condition)
+	jumpNotTruthy := c.emit(OpJumpNotTruthy, 0)
+
+	c.pushControl(loopControl, false)
+	c.compileBlock(fs.Body)
+	loop := c.popControl()
+
+	update := len(c.scope.instructions)
+
+	if fs.Update != nil {
+		c.compileIgnored(func() { c.compileExpressionStatement(fs.Update, true) })
+	}
+
+	c.emit(OpJump, start)
+
+	end := len(c.scope.instructions)
+	c.changeOperands(jumpNotTruthy, end)
+	c.patchJumps(loop.breaks, end)
+	c.patchJumps(loop.continues, update)
+}
+
+/*
+Compile a for-in loop, binding the loop variables in a new frame for every iteration like the evaluator does
+*/
+func (c *Compiler) compileForInStatement(fs *ast.ForInStatement) {
+	node := c.addNode(fs)
+
+	if call, ok := fs.Iterable.(*ast.CallExpression); ok && !hasSpread(call.Arguments) {
+		// A call of range is iterated without creating the array of numbers, other functions are called
+		c.compileExpression(call.Function)
+
+		for _, argument := range call.Arguments {
+			c.compileExpression(argument)
+		}
+
+		callNode := c.addNode(call)
+		rangeCall := c.emit(OpRange, callNode, len(call.Arguments), 0)
+		c.emit(OpCall, callNode, len(call.Arguments))
+		c.emit(OpIterate, node)
+		c.changeOperands(rangeCall, callNode, len(call.Arguments), len(c.scope.instructions))
+	} else {
+		c.compileExpression(fs.Iterable)
+		c.emit(OpIterate, node)
+	}
+
+	c.scope.iterators++
+
+	start := len(c.scope.instructions)
+	next := c.emit(OpIterNext, 0)
+
+	c.pushControl(loopControl, false)
+
+	scoped := fs.Slots > 0
+
+	if scoped {
+		c.emit(OpPushScope, fs.Slots)
+		c.scope.scopes++
+	}
+
+	if fs.Key != nil {
+		c.emit(OpDefine, c.addNode(fs.Key))
+	} else {
+		c.emit(OpPop)
+	}
+
+	c.emit(OpDefine, c.addNode(fs.Value))
+	c.compileBlock(fs.Body)
+
+	if scoped {
+		c.emit(OpPopScope)
+		c.scope.scopes--
+	}
+
+	c.emit(OpJump, start)
+	loop := c.popControl()
+
+	end := len(c.scope.instructions)
+	c.changeOperands(next, end)
+	c.emit(OpPopIterator)
+	c.scope.iterators--
+
+	c.patchJumps(loop.breaks, end)
+	c.patchJumps(loop.continues, start)
+}
+
+/*
+Compile code whose value and errors are ignored, like the evaluator does for the initialization and update of a for loop
+*/
+func (c *Compiler) compileIgnored(compile func()) {
+	handler := c.emit(OpSetHandler, 0)
+
+	c.pushControl(captureControl, true)
+	compile()
+	c.popControl()
+
+	c.emit(OpPopHandler)
+	c.changeOperands(handler, len(c.scope.instructions))
+
+	// Pop the value or the error
+	c.emit(OpPop)
+}
+
+/*
+Compile a try statement, leaving the value the evaluator would give it on the stack.
+
+Like in the evaluator a break, continue or return inside any of the blocks becomes the value of the statement,
+and the value of the finally block takes precedence when it is a break, continue, return or error.
+*/
+func (c *Compiler) compileTryStatement(ts *ast.TryStatement) {
+	handler := c.emit(OpSetHandler, 0)
+
+	c.pushControl(captureControl, true)
+	c.compileBlock(ts.Block)
+	c.emit(OpNull)
+	c.popControl()
+
+	c.emit(OpPopHandler)
+
+	// Without a catch block the error is the value of the statement
+	if ts.CatchBlock == nil {
+		c.changeOperands(handler, len(c.scope.instructions))
+	} else {
+		skip := c.emit(OpJump, 0)
+		c.changeOperands(handler, len(c.scope.instructions))
+		c.compileCatch(ts)
+		c.changeOperands(skip, len(c.scope.instructions))
+	}
+
+	if ts.FinallyBlock != nil {
+		c.pushControl(captureControl, true)
+		c.compileBlock(ts.FinallyBlock)
+		c.emit(OpNull)
+		c.popControl()
+
+		c.emit(OpFinally)
+	}
+}
+
+/*
+Compile the catch block of a try statement, which starts with the caught error on the stack
+*/
+func (c *Compiler) compileCatch(ts *ast.TryStatement) {
+	var handler int
+
+	// An error inside the catch block becomes the value of the statement until the finally block has run
+	if ts.FinallyBlock != nil {
+		handler = c.emit(OpSetHandler, 0)
+	}
+
+	c.pushControl(captureControl, true)
+
+	scoped := ts.CatchSlots > 0
+
+	if scoped {
+		c.emit(OpPushScope, ts.CatchSlots)
+		c.scope.scopes++
+	}
+
+	if ts.CatchParameter != nil {
+		node := c.addNode(ts.CatchParameter)
+		c.emit(OpCatch, node)
+		c.emit(OpDefine, node)
+	} else {
+		c.emit(OpPop)
+	}
+
+	c.compileBlock(ts.CatchBlock)
+
+	if scoped {
+		c.emit(OpPopScope)
+		c.scope.scopes--
+	}
+
+	c.emit(OpNull)
+	c.popControl()
+
+	if ts.FinallyBlock != nil {
+		c.emit(OpPopHandler)
+		c.changeOperands(handler, len(c.scope.instructions))
+	}
+}
+
+/*
+Compile a call without spread arguments as an OpCall or OpTailCall
+*/
+func (c *Compiler) compileCall(call *ast.CallExpression, op Opcode) {
+	c.compileExpression(call.Function)
+
+	for _, argument := range call.Arguments {
+		c.compileExpression(argument)
+	}
+
+	c.emit(op, c.addNode(call), len(call.Arguments))
+}
+
+/*
+Compile a returned expression, making the calls of functions it ends in in place of the current function
+*/
+func (c *Compiler) compileTailExpression(expression ast.Expression) {
+	switch expression := expression.(type) {
+	case *ast.CallExpression:
+		if !hasSpread(expression.Arguments) {
+			c.compileCall(expression, OpTailCall)
+
+			return
+		}
+
+	case *ast.ChainingExpression:
+		if call, ok := expression.Call(); ok && !hasSpread(call.Arguments) {
+			c.compileExpression(expression.Left)
+
+			for _, argument := range call.Arguments {
+				c.compileExpression(argument)
+			}
+
+			c.emit(OpTailChainCall, c.addNode(call), len(call.Arguments))
+
+			return
+		}
+
+	case *ast.TernaryExpression:
+		c.compileExpression(expression.Condition)
+		jumpNotTruthy := c.emit(OpJumpNotTruthy, 0)
+
+		c.compileTailExpression(expression.Consequence)
+		jump := c.emit(OpJump, 0)
+
+		c.changeOperands(jumpNotTruthy, len(c.scope.instructions))
+		c.compileTailExpression(expression.Alternative)
+
+		c.changeOperands(jump, len(c.scope.instructions))
+
+		return
+	}
+
+	c.compileExpression(expression)
+}
+
+/*
+Check if a return is captured by the surrounding code instead of returning from the function directly
+*/
+func (c *Compiler) capturesReturn() bool {
+	for _, ctrl := range c.scope.controls {
+		if ctrl.kind == captureControl && ctrl.captureReturn {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+Compile the body of a function literal or statement.
+
+Returns the index of the compiled function.
+*/
+func (c *Compiler) compileFunction(node ast.Node, body *ast.BlockStatement) int {
+	c.scope = &compilationScope{outer: c.scope}
+
+	c.compileBlock(body)
+	c.emit(OpNull)
+	c.emit(OpReturnValue)
+
+	function := &Function{Node: node, Instructions: c.scope.instructions, Bytecode: c.bytecode}
+	c.scope = c.scope.outer
+
+	c.bytecode.Functions = append(c.bytecode.Functions, function)
+
+	return len(c.bytecode.Functions) - 1
+}
+
+func (c *Compiler) compileReassignment(node *ast.ReassignmentExpression) {
+	if node.Token.Type == token.ASSIGN {
+		c.compileExpression(node.Value)
+		c.compileAssign(node, node.Name)
+
+		return
+	}
+
+	operator, ok := token.AssignmentInfixOperator(node.Token.Type)
+
+	if !ok {
+		c.emit(OpEval, c.addNode(node))
+		c.emit(OpPop)
+
+		return
+	}
+
+	c.compileGetVariable(node, node.Name)
+	c.compileExpression(node.Value)
+	c.emit(OpInfix, c.addNode(&ast.InfixExpression{
+		Token:    node.Token,
+		Left:     node.Name,
+		Operator: string(operator),
+		Right:    node.Value,
+	}))
+	c.compileAssign(node, node.Name)
+}
+
+/*
+Compile the container and key of an assignment target like arr[0] or obj.key, for dotted targets the key is the name of the property.
+
+Returns false if the target is not a member, which is left to the evaluator.
+*/
+func (c *Compiler) compileMemberTarget(target ast.Expression) bool {
+	switch target := target.(type) {
+	case *ast.IndexExpression:
+		c.compileExpression(target.Left)
+		c.compileExpression(target.Index)
+
+		return true
+	case *ast.ChainingExpression:
+		property, ok := target.Right.(*ast.Identifier)
+
+		if !ok { // coverage-ignore
+			return false
+		}
+
+		c.compileExpression(target.Left)
+		c.emit(OpConstant, c.addConstant(object.NewString(property, property.Value)))
+
+		return true
+	}
+
+	return false
+}
+
+/*
+Check if any of the expressions is spread, which is left to the evaluator
+*/
+func hasSpread(expressions []ast.Expression) bool {
+	for _, expression := range expressions {
+		if _, ok := expression.(*ast.SpreadExpression); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+Compile an expression, leaving its value on the stack
+*/
+func (c *Compiler) compileExpression(expression ast.Expression) {
+	switch expression := expression.(type) {
+	case nil:
+		c.emit(OpNil)
+
+	case *ast.IntegerLiteral:
+		c.emit(OpConstant, c.addConstant(object.NewInteger(expression, expression.Value)))
+
+	case *ast.FloatLiteral:
+		c.emit(OpConstant, c.addConstant(object.NewFloat(expression, expression.Value)))
+
+	case *ast.StringLiteral:
+		c.emit(OpConstant, c.addConstant(object.NewString(expression, expression.Value)))
+
+	case *ast.BooleanLiteral:
+		if expression.Value {
+			c.emit(OpTrue)
+		} else {
+			c.emit(OpFalse)
+		}
+
+	case *ast.NullLiteral:
+		c.emit(OpNull)
+
+	case *ast.BreakExpression:
+		c.emit(OpConstant, c.addConstant(object.NewBreak(expression)))
+
+	case *ast.ContinueExpression:
+		c.emit(OpConstant, c.addConstant(object.NewContinue(expression)))
+
+	case *ast.Identifier:
+		if expression.Local {
+			c.emit(OpGetName, c.addNode(expression))
+		} else {
+			c.emit(OpGetGlobal, c.addNode(expression), c.addGlobal(expression.Value))
+		}
+
+	case *ast.PrefixExpression:
+		c.compileExpression(expression.Right)
+		c.emit(OpPrefix, c.addNode(expression))
+
+	case *ast.InfixExpression:
+		c.compileExpression(expression.Left)
+
+		if expression.Operator == token.AND || expression.Operator == token.OR {
+			op := OpAnd
+
+			if expression.Operator == token.OR {
+				op = OpOr
+			}
+
+			jump := c.emit(op, 0)
+			c.compileExpression(expression.Right)
+			c.changeOperands(jump, len(c.scope.instructions))
+
+			return
+		}
+
+		c.compileExpression(expression.Right)
+		c.emit(OpInfix, c.addNode(expression))
+
+	case *ast.IfExpression:
+		c.compileIfExpression(expression, true)
+
+	case *ast.TernaryExpression:
+		c.compileExpression(expression.Condition)
+		jumpNotTruthy := c.emit(OpJumpNotTruthy, 0)
+
+		c.compileExpression(expression.Consequence)
+		jump := c.emit(OpJump, 0)
+
+		c.changeOperands(jumpNotTruthy, len(c.scope.instructions))
+		c.compileExpression(expression.Alternative)
+
+		c.changeOperands(jump, len(c.scope.instructions))
+
+	case *ast.FunctionLiteral:
+		c.emit(OpClosure, c.compileFunction(expression, expression.Body))
+
+	case *ast.CallExpression:
+		if hasSpread(expression.Arguments) {
+			c.emit(OpEval, c.addNode(expression))
+
+			return
+		}
+
+		c.compileCall(expression, OpCall)
+
+	case *ast.IndexExpression:
+		c.compileExpression(expression.Left)
+		c.compileExpression(expression.Index)
+		c.emit(OpIndex, c.addNode(expression))
+
+	case *ast.ArrayLiteral:
+		if hasSpread(expression.Elements) {
+			c.emit(OpEval, c.addNode(expression))
+
+			return
+		}
+
+		for _, element := range expression.Elements {
+			c.compileExpression(element)
+		}
+
+		c.emit(OpArray, c.addNode(expression), len(expression.Elements))
+
+	case *ast.HashLiteral:
+		if hasSpread(expression.Keys) {
+			c.emit(OpEval, c.addNode(expression))
+
+			return
+		}
+
+		for _, key := range expression.Keys {
+			c.compileExpression(key)
+			c.compileExpression(expression.Pairs[key])
+		}
+
+		c.emit(OpHash, c.addNode(expression), len(expression.Keys))
+
+	case *ast.TemplateLiteral:
+		for _, part := range expression.Parts {
+			if literal, ok := part.(*ast.StringLiteral); ok {
+				c.emit(OpConstant, c.addConstant(object.NewString(literal, literal.Value)))
+
+				continue
+			}
+
+			c.compileExpression(part)
+			c.emit(OpToString, c.addNode(part))
+		}
+
+		c.emit(OpTemplate, c.addNode(expression), len(expression.Parts))
+
+	case *ast.ReassignmentExpression:
+		c.compileReassignment(expression)
+		c.emit(OpNil)
+
+	case *ast.IncrementDecrementExpression:
+		c.compileGetVariable(expression, expression.Identifier)
+		c.emit(OpIncrement, c.addNode(expression))
+		c.compileAssign(expression, expression.Identifier)
+
+	case *ast.MemberReassignmentExpression:
+		if !c.compileMemberTarget(expression.Target) {
+			c.emit(OpEval, c.addNode(expression))
+
+			return
+		}
+
+		c.compileExpression(expression.Value)
+		c.emit(OpSetMember, c.addNode(expression))
+
+	case *ast.MemberIncrementDecrementExpression:
+		if !c.compileMemberTarget(expression.Target) {
+			c.emit(OpEval, c.addNode(expression))
+
+			return
+		}
+
+		c.emit(OpIncrementMember, c.addNode(expression))
+
+	case *ast.ChainingExpression:
+		c.compileChainingExpression(expression)
+
+	default:
+		c.emit(OpEval, c.addNode(expression))
+	}
+}
+
+func (c *Compiler) compileChainingExpression(ce *ast.ChainingExpression) {
+	var right ast.Node = ce.Right
+
+	if statement, ok := right.(*ast.ExpressionStatement); ok {
+		right = statement.Expression
+	}
+
+	switch right := right.(type) {
+	case *ast.CallExpression:
+		if hasSpread(right.Arguments) {
+			break
+		}
+
+		c.compileExpression(ce.Left)
+
+		for _, argument := range right.Arguments {
+			c.compileExpression(argument)
+		}
+
+		c.emit(OpChainCall, c.addNode(right), len(right.Arguments))
+
+		return
+	case *ast.Identifier:
+		c.compileExpression(ce.Left)
+		c.emit(OpChainProperty, c.addNode(right))
+
+		return
+	}
+
+	c.emit(OpEval, c.addNode(ce))
+}
@@ -0,0 +1,311 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iskandervdh/vorn/lexer"
+	"github.com/iskandervdh/vorn/object"
+	"github.com/iskandervdh/vorn/parser"
+	"github.com/iskandervdh/vorn/resolver"
+)
+
+func concatInstructions(instructions ...[]byte) Instructions {
+	out := Instructions{}
+
+	for _, instruction := range instructions {
+		out = append(out, instruction...)
+	}
+
+	return out
+}
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		expected []byte
+	}{
+		{OpConstant, []int{65534}, []byte{byte(OpConstant), 0, 0, 255, 254}},
+		{OpCall, []int{1, 2}, []byte{byte(OpCall), 0, 0, 0, 1, 0, 2}},
+		{OpPop, []int{}, []byte{byte(OpPop)}},
+	}
+
+	for _, tt := range tests {
+		instruction := Make(tt.op, tt.operands...)
+
+		if string(instruction) != string(tt.expected) {
+			t.Errorf("wrong instruction. got %v, want %v", instruction, tt.expected)
+		}
+	}
+}
+
+func TestInstructionsString(t *testing.T) {
+	instructions := concatInstructions(
+		Make(OpConstant, 1),
+		Make(OpCall, 2, 3),
+		Make(OpPop),
+		[]byte{255},
+	)
+
+	expected := `0000 OpConstant 1
+0005 OpCall 2 3
+0012 OpPop
+ERROR: opcode 255 undefined
+`
+
+	if instructions.String() != expected {
+		t.Errorf("instructions wrongly formatted.\nwant %q\ngot  %q", expected, instructions.String())
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		input     string
+		constants []interface{}
+		expected  Instructions
+	}{
+		{
+			`1 + 2;`,
+			[]interface{}{1, 2},
+			concatInstructions(
+				Make(OpConstant, 0),
+				Make(OpConstant, 1),
+				Make(OpInfix, 0),
+				Make(OpPop),
+			),
+		},
+		{
+			`let x = "a"; x;`,
+			[]interface{}{"a"},
+			concatInstructions(
+				Make(OpDeclare, 0),
+				Make(OpConstant, 0),
+				Make(OpDefine, 0),
+				Make(OpNil),
+				Make(OpPop),
+				Make(OpGetGlobal, 1, 0),
+				Make(OpControl, 44, 49, 54),
+				Make(OpJump, 55),
+				Make(OpJump, 55),
+				Make(OpJump, 55),
+				Make(OpReturnValue),
+				Make(OpPop),
+			),
+		},
+		{
+			`while (true) { break; }`,
+			[]interface{}{"break"},
+			concatInstructions(
+				Make(OpTrue),
+				Make(OpJumpNotTruthy, 22),
+				Make(OpConstant, 0),
+				Make(OpPop),
+				Make(OpJump, 22),
+				Make(OpJump, 0),
+				Make(OpNull),
+				Make(OpPop),
+			),
+		},
+		{
+			`x++; x.y = 1; x[0]--;`,
+			[]interface{}{"y", 1, 0},
+			concatInstructions(
+				Make(OpGetGlobal, 0, 0),
+				Make(OpIncrement, 1),
+				Make(OpSetGlobal, 2, 0),
+				Make(OpPop),
+				Make(OpGetGlobal, 3, 0),
+				Make(OpConstant, 0),
+				Make(OpConstant, 1),
+				Make(OpSetMember, 4),
+				Make(OpPop),
+				Make(OpGetGlobal, 5, 0),
+				Make(OpConstant, 2),
+				Make(OpIncrementMember, 6),
+				Make(OpPop),
+			),
+		},
+		{
+			`for (x in y) { break; }`,
+			[]interface{}{"break"},
+			concatInstructions(
+				Make(OpGetGlobal, 1, 0),
+				Make(OpIterate, 0),
+				Make(OpIterNext, 41),
+				Make(OpPop),
+				Make(OpDefine, 2),
+				Make(OpConstant, 0),
+				Make(OpPop),
+				Make(OpJump, 41),
+				Make(OpJump, 14),
+				Make(OpPopIterator),
+				Make(OpNull),
+				Make(OpPop),
+			),
+		},
+		{
+			`false || null;`,
+			[]interface{}{},
+			concatInstructions(
+				Make(OpFalse),
+				Make(OpOr, 7),
+				Make(OpNull),
+				Make(OpControl, 25, 30, 35),
+				Make(OpJump, 36),
+				Make(OpJump, 36),
+				Make(OpJump, 36),
+				Make(OpReturnValue),
+				Make(OpPop),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l, false)
+		bytecode := Compile(p.ParseProgram())
+
+		if bytecode.Main.Instructions.String() != tt.expected.String() {
+			t.Errorf("wrong instructions for %q.\nwant\n%s\ngot\n%s", tt.input, tt.expected, bytecode.Main.Instructions)
+		}
+
+		if len(bytecode.Constants) != len(tt.constants) {
+			t.Errorf("wrong amount of constants for %q. got %d, want %d", tt.input, len(bytecode.Constants), len(tt.constants))
+			continue
+		}
+
+		for i, constant := range tt.constants {
+			var expected string
+
+			switch constant := constant.(type) {
+			case int:
+				expected = object.NewInteger(nil, int64(constant)).Inspect()
+			case string:
+				expected = constant
+			}
+
+			if bytecode.Constants[i].Inspect() != expected {
+				t.Errorf("wrong constant %d for %q. got %s, want %s", i, tt.input, bytecode.Constants[i].Inspect(), expected)
+			}
+		}
+	}
+}
+
+type noBuiltins struct{}
+
+func (noBuiltins) Builtin(name string) (*object.Builtin, bool) { return nil, false }
+
+func TestCompileFunction(t *testing.T) {
+	l := lexer.New(`func add(a, b) { let c = a + b; return c; }`)
+	p := parser.New(l, false)
+	program := p.ParseProgram()
+
+	if errors := resolver.New(noBuiltins{}).Resolve(program); len(errors) != 0 {
+		t.Fatalf("resolver errors: %v", errors)
+	}
+
+	bytecode := Compile(program)
+
+	if len(bytecode.Functions) != 1 {
+		t.Fatalf("wrong amount of functions. got %d, want 1", len(bytecode.Functions))
+	}
+
+	expected := concatInstructions(
+		Make(OpPushScope, 1),
+		Make(OpDeclare, 1),
+		Make(OpGetName, 2),
+		Make(OpGetName, 3),
+		Make(OpInfix, 4),
+		Make(OpDefine, 1),
+		Make(OpGetName, 5),
+		Make(OpReturnValue),
+		Make(OpPopScope),
+		Make(OpNull),
+		Make(OpReturnValue),
+	)
+
+	if bytecode.Functions[0].Instructions.String() != expected.String() {
+		t.Errorf("wrong instructions.\nwant\n%s\ngot\n%s", expected, bytecode.Functions[0].Instructions)
+	}
+}
+
+func TestCompileGlobals(t *testing.T) {
+	program := parser.New(lexer.New(`let a = 1; func f(n) { a += n; return b; } a; let b = 2;`), false).ParseProgram()
+
+	if errors := resolver.New(noBuiltins{}).Resolve(program); len(errors) != 0 {
+		t.Fatalf("resolver errors: %v", errors)
+	}
+
+	bytecode := Compile(program)
+	expected := []string{"a", "b"}
+
+	if strings.Join(bytecode.Globals, " ") != strings.Join(expected, " ") {
+		t.Errorf("wrong globals. got %v, want %v", bytecode.Globals, expected)
+	}
+
+	if instructions := bytecode.Functions[0].Instructions.String(); !strings.Contains(instructions, "OpGetName") {
+		t.Errorf("local variable n is not read by name. got\n%s", instructions)
+	}
+}
+
+func TestCompileWithoutEval(t *testing.T) {
+	inputs := []string{
+		`for (i, x in [1, 2]) { if (x) { break; } continue; }`,
+		`func numbers() { return [1]; } for (i in numbers()) { }`,
+		`func f() { for (x in "ab") { return x; } }`,
+		`try { throw "x"; } catch (e) { e; } finally { 1; }`,
+		`try { 1; } catch { }`,
+		`func f() { try { return 1; } finally { } }`,
+		`throw "x";`,
+		`let x = 1; "a ${x} b";`,
+	}
+
+	for _, input := range inputs {
+		program := parser.New(lexer.New(input), false).ParseProgram()
+
+		if errors := resolver.New(noBuiltins{}).Resolve(program); len(errors) != 0 {
+			t.Fatalf("resolver errors for %q: %v", input, errors)
+		}
+
+		bytecode := Compile(program)
+		functions := append([]*Function{bytecode.Main}, bytecode.Functions...)
+
+		for _, function := range functions {
+			if instructions := function.Instructions.String(); strings.Contains(instructions, "OpEval") {
+				t.Errorf("%q is evaluated by the evaluator. got\n%s", input, instructions)
+			}
+		}
+	}
+}
+
+func TestCompileTailCall(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Opcode
+	}{
+		{"func f(n) { return f(n); }", OpTailCall},
+		{"func f(n) { return f(...n); }", OpEval},
+		{"func f(n) { return n ? 1 : f(n); }", OpTailCall},
+		{"func f(n) { return n.f(n); }", OpTailChainCall},
+		{"func f(n) { return n.f(...n); }", OpEval},
+		{"func f(n) { let x = if (n) { return f(n); }; }", OpCall},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input), false).ParseProgram()
+
+		if errors := resolver.New(noBuiltins{}).Resolve(program); len(errors) != 0 {
+			t.Fatalf("resolver errors for %q: %v", tt.input, errors)
+		}
+
+		bytecode := Compile(program)
+		instructions := bytecode.Functions[0].Instructions.String()
+
+		tailCall := strings.Contains(instructions, "OpTailCall")
+
+		if !strings.Contains(instructions, definitions[tt.expected].Name+" ") || tailCall != (tt.expected == OpTailCall) {
+			t.Errorf("wrong call in %q, want %s. got\n%s", tt.input, definitions[tt.expected].Name, instructions)
+		}
+	}
+}
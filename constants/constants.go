@@ -3,3 +3,10 @@ package constants
 const INDENT_STRING string = "  "
 
 const TRACE = false
+
+// The default maximum amount of nested function calls before a program stops with an error
+const MAX_CALL_DEPTH = 10000
+
+// The highest maximum amount of nested function calls that can be set,
+// deeper calls can overflow the stack of Go when functions are called by builtins like map
+const MAX_CALL_DEPTH_LIMIT = 100000
@@ -16,39 +16,66 @@ func (e *Evaluator) builtinType(node ast.Node, args ...object.Object) object.Obj
 		return object.NewError(node, "wrong number of arguments. got %d, want 1", len(args))
 	}
 
-	typeName := args[0].Type()
+	return object.NewString(node, object.TypeName(args[0]))
+}
+
+/*
+Check if two values are the same, unlike == this compares arrays and objects by identity instead of by their contents
+*/
+func (e *Evaluator) builtinSame(node ast.Node, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return object.NewError(node, "wrong number of arguments. got %d, want 2", len(args))
+	}
 
-	return object.NewString(node, string(typeName))
+	switch args[0].(type) {
+	case *object.Array, *object.Hash:
+		return e.nativeBoolToBooleanObject(args[0] == args[1])
+	default:
+		return e.nativeBoolToBooleanObject(object.Equal(args[0], args[1]))
+	}
 }
 
-func (e *Evaluator) builtinRange(node ast.Node, args ...object.Object) object.Object {
+/*
+Get the start and (exclusive) end of the range described by the arguments to `range`
+*/
+func rangeBounds(node ast.Node, args []object.Object) (int64, int64, *object.Error) {
 	if len(args) < 1 || len(args) > 2 {
-		return object.NewError(node, "wrong number of arguments. got %d, want 1 or 2", len(args))
+		return 0, 0, object.NewError(node, "wrong number of arguments. got %d, want 1 or 2", len(args))
 	}
 
 	if args[0].Type() != object.INTEGER_OBJ {
-		return object.NewError(node, "first argument to `range` must be INTEGER, got %s", args[0].Type())
+		return 0, 0, object.NewError(node, "first argument to `range` must be INTEGER, got %s", object.TypeName(args[0]))
 	}
 
-	firstArg := args[0].(*object.Integer)
-
-	start := firstArg.Value
+	start := args[0].(*object.Integer).Value
 	end := start
 
 	if len(args) == 1 {
 		if start < 0 {
-			return object.NewError(node, "argument to `range` must be non-negative, got %d", start)
+			return 0, 0, object.NewError(node, "argument to `range` must be non-negative, got %d", start)
 		}
 
 		start = 0
 	} else {
 		if args[1].Type() != object.INTEGER_OBJ {
-			return object.NewError(node, "second argument to `range` must be INTEGER, got %s", args[1].Type())
+			return 0, 0, object.NewError(node, "second argument to `range` must be INTEGER, got %s", object.TypeName(args[1]))
 		}
 
 		end = args[1].(*object.Integer).Value
 	}
 
+	return start, end, nil
+}
+
+func (e *Evaluator) builtinRange(node ast.Node, args ...object.Object) object.Object {
+	start, end, err := rangeBounds(node, args)
+
+	if err != nil {
+		return err
+	}
+
+	firstArg := args[0].(*object.Integer)
+
 	elementsLength := end - start
 
 	if start > end {
@@ -91,7 +118,7 @@ func (e *Evaluator) builtinInt(node ast.Node, args ...object.Object) object.Obje
 
 		return object.NewInteger(arg.Node(), integer)
 	default:
-		return object.NewError(node, "argument to `int` not supported, got %s", args[0].Type())
+		return object.NewError(node, "argument to `int` not supported, got %s", object.TypeName(args[0]))
 	}
 }
 
@@ -114,7 +141,7 @@ func (e *Evaluator) builtinFloat(node ast.Node, args ...object.Object) object.Ob
 
 		return object.NewFloat(arg.Node(), float)
 	default:
-		return object.NewError(node, "argument to `float` not supported, got %s", args[0].Type())
+		return object.NewError(node, "argument to `float` not supported, got %s", object.TypeName(args[0]))
 	}
 }
 
@@ -123,7 +150,7 @@ func (e *Evaluator) builtinString(node ast.Node, args ...object.Object) object.O
 		return object.NewError(node, "wrong number of arguments. got %d, want 1", len(args))
 	}
 
-	return object.NewString(node, args[0].Inspect())
+	return e.toString(node, args[0])
 }
 
 func (e *Evaluator) builtinBool(node ast.Node, args ...object.Object) object.Object {
@@ -147,7 +174,7 @@ func (e *Evaluator) builtinBool(node ast.Node, args ...object.Object) object.Obj
 	case *object.Hash:
 		return e.nativeBoolToBooleanObject(len(arg.Pairs) != 0)
 	default:
-		return object.NewError(node, "argument to `bool` not supported, got %s", args[0].Type())
+		return object.NewError(node, "argument to `bool` not supported, got %s", object.TypeName(args[0]))
 	}
 }
 
@@ -164,7 +191,7 @@ func (e *Evaluator) builtinLen(node ast.Node, args ...object.Object) object.Obje
 	case *object.String:
 		return object.NewInteger(arg.Node(), int64(len(arg.Value)))
 	default:
-		return object.NewError(node, "argument to `len` not supported, got %s", args[0].Type())
+		return object.NewError(node, "argument to `len` not supported, got %s", object.TypeName(args[0]))
 	}
 }
 
@@ -176,14 +203,14 @@ func (e *Evaluator) builtinFirst(node ast.Node, args ...object.Object) object.Ob
 	switch arg := args[0].(type) {
 	case *object.Array:
 		if len(arg.Elements) > 0 {
-			return object.Clone(node, arg.Elements[0])
+			return arg.Elements[0]
 		}
 	case *object.String:
 		if len(arg.Value) > 0 {
 			return object.NewString(node, string(arg.Value[0]))
 		}
 	default:
-		return object.NewError(node, "argument to `first` must be ARRAY or STRING, got %s", args[0].Type())
+		return object.NewError(node, "argument to `first` must be ARRAY or STRING, got %s", object.TypeName(args[0]))
 	}
 
 	return object.NULL
@@ -199,7 +226,7 @@ func (e *Evaluator) builtinLast(node ast.Node, args ...object.Object) object.Obj
 		length := len(arg.Elements)
 
 		if length > 0 {
-			return object.Clone(node, arg.Elements[length-1])
+			return arg.Elements[length-1]
 		}
 	case *object.String:
 		length := len(arg.Value)
@@ -208,7 +235,7 @@ func (e *Evaluator) builtinLast(node ast.Node, args ...object.Object) object.Obj
 			return object.NewString(node, string(arg.Value[length-1]))
 		}
 	default:
-		return object.NewError(node, "argument to `last` must be ARRAY or STRING, got %s", args[0].Type())
+		return object.NewError(node, "argument to `last` must be ARRAY or STRING, got %s", object.TypeName(args[0]))
 	}
 
 	return object.NULL
@@ -222,7 +249,7 @@ func (e *Evaluator) builtinRest(node ast.Node, args ...object.Object) object.Obj
 	}
 
 	if args[0].Type() != object.ARRAY_OBJ {
-		return object.NewError(node, "argument to `rest` must be ARRAY, got %s", args[0].Type())
+		return object.NewError(node, "argument to `rest` must be ARRAY, got %s", object.TypeName(args[0]))
 	}
 
 	arr := args[0].(*object.Array)
@@ -230,10 +257,7 @@ func (e *Evaluator) builtinRest(node ast.Node, args ...object.Object) object.Obj
 
 	if length > 0 {
 		elements := make([]object.Object, length-1)
-
-		for i := 1; i < length; i++ {
-			elements[i-1] = object.Clone(node, arr.Elements[i])
-		}
+		copy(elements, arr.Elements[1:])
 
 		return object.NewArray(node, elements)
 	}
@@ -245,7 +269,13 @@ func (e *Evaluator) builtinRest(node ast.Node, args ...object.Object) object.Obj
 
 func (e *Evaluator) builtinPrint(node ast.Node, args ...object.Object) object.Object {
 	for _, arg := range args {
-		fmt.Println(arg.Inspect())
+		str := e.toString(node, arg)
+
+		if isError(str) {
+			return str
+		}
+
+		fmt.Println(str.(*object.String).Value)
 	}
 
 	return object.NULL
@@ -260,6 +290,10 @@ func (e *Evaluator) builtinAbs(node ast.Node, args ...object.Object) object.Obje
 
 	switch arg := args[0].(type) {
 	case *object.Integer:
+		if arg.Value == math.MinInt64 {
+			return object.NewError(node, "integer overflow: abs(%d)", arg.Value)
+		}
+
 		if arg.Value < 0 {
 			return object.NewInteger(arg.Node(), -arg.Value)
 		}
@@ -272,7 +306,7 @@ func (e *Evaluator) builtinAbs(node ast.Node, args ...object.Object) object.Obje
 
 		return arg
 	default:
-		return object.NewError(node, "argument to `abs` must be INTEGER or FLOAT, got %s", args[0].Type())
+		return object.NewError(node, "argument to `abs` must be INTEGER or FLOAT, got %s", object.TypeName(args[0]))
 	}
 }
 
@@ -289,8 +323,25 @@ func powInt(node ast.Node, x int64, y int64) object.Object {
 		return powFloat(node, float64(x), float64(y))
 	}
 
-	for i := int64(0); i < y; i++ {
-		result *= x
+	base := x
+	exponent := y
+	ok := true
+
+	// Exponentiation by squaring, checking every multiplication for overflow
+	for exponent > 0 {
+		if exponent&1 == 1 {
+			if result, ok = mulInt64(result, base); !ok {
+				return object.NewError(node, "integer overflow: pow(%d, %d)", x, y)
+			}
+		}
+
+		exponent >>= 1
+
+		if exponent > 0 {
+			if base, ok = mulInt64(base, base); !ok {
+				return object.NewError(node, "integer overflow: pow(%d, %d)", x, y)
+			}
+		}
 	}
 
 	return object.NewInteger(node, result)
@@ -327,7 +378,7 @@ func (e *Evaluator) builtinPow(node ast.Node, args ...object.Object) object.Obje
 		return powFloat(node, x, float64(y))
 
 	default:
-		return object.NewError(node, "arguments to `pow` must be INTEGER or FLOAT, got %s and %s", args[0].Type(), args[1].Type())
+		return object.NewError(node, "arguments to `pow` must be INTEGER or FLOAT, got %s and %s", object.TypeName(args[0]), object.TypeName(args[1]))
 	}
 }
 
@@ -337,7 +388,7 @@ func (e *Evaluator) builtinSqrt(node ast.Node, args ...object.Object) object.Obj
 	}
 
 	if !object.IsNumber(args[0]) {
-		return object.NewError(node, "argument to `sqrt` must be INTEGER or FLOAT, got %s", args[0].Type())
+		return object.NewError(node, "argument to `sqrt` must be INTEGER or FLOAT, got %s", object.TypeName(args[0]))
 	}
 
 	var x float64
@@ -363,7 +414,7 @@ func (e *Evaluator) builtinSin(node ast.Node, args ...object.Object) object.Obje
 	}
 
 	if !object.IsNumber(args[0]) {
-		return object.NewError(node, "argument to `sin` must be INTEGER or FLOAT, got %s", args[0].Type())
+		return object.NewError(node, "argument to `sin` must be INTEGER or FLOAT, got %s", object.TypeName(args[0]))
 	}
 
 	var x float64
@@ -385,7 +436,7 @@ func (e *Evaluator) builtinCos(node ast.Node, args ...object.Object) object.Obje
 	}
 
 	if !object.IsNumber(args[0]) {
-		return object.NewError(node, "argument to `cos` must be INTEGER or FLOAT, got %s", args[0].Type())
+		return object.NewError(node, "argument to `cos` must be INTEGER or FLOAT, got %s", object.TypeName(args[0]))
 	}
 
 	var x float64
@@ -407,7 +458,7 @@ func (e *Evaluator) builtinTan(node ast.Node, args ...object.Object) object.Obje
 	}
 
 	if !object.IsNumber(args[0]) {
-		return object.NewError(node, "argument to `tan` must be INTEGER or FLOAT, got %s", args[0].Type())
+		return object.NewError(node, "argument to `tan` must be INTEGER or FLOAT, got %s", object.TypeName(args[0]))
 	}
 
 	var x float64
@@ -431,7 +482,7 @@ func (e *Evaluator) builtinSum(node ast.Node, args ...object.Object) object.Obje
 	}
 
 	if args[0].Type() != object.ARRAY_OBJ {
-		return object.NewError(node, "argument to `sum` must be ARRAY, got %s", args[0].Type())
+		return object.NewError(node, "argument to `sum` must be ARRAY, got %s", object.TypeName(args[0]))
 	}
 
 	arr := args[0].(*object.Array)
@@ -440,7 +491,7 @@ func (e *Evaluator) builtinSum(node ast.Node, args ...object.Object) object.Obje
 
 	for _, element := range arr.Elements {
 		if !object.IsNumber(element) {
-			return object.NewError(node, "elements in array must be INTEGER or FLOAT, got %s", element.Type())
+			return object.NewError(node, "elements in array must be INTEGER or FLOAT, got %s", object.TypeName(element))
 		}
 
 		if element.Type() == object.FLOAT_OBJ {
@@ -464,7 +515,7 @@ func (e *Evaluator) builtinMean(node ast.Node, args ...object.Object) object.Obj
 	}
 
 	if args[0].Type() != object.ARRAY_OBJ {
-		return object.NewError(node, "argument to `mean` must be ARRAY, got %s", args[0].Type())
+		return object.NewError(node, "argument to `mean` must be ARRAY, got %s", object.TypeName(args[0]))
 	}
 
 	arr := args[0].(*object.Array)
@@ -477,7 +528,7 @@ func (e *Evaluator) builtinMean(node ast.Node, args ...object.Object) object.Obj
 
 	for _, element := range arr.Elements {
 		if !object.IsNumber(element) {
-			return object.NewError(node, "elements in array must be INTEGER or FLOAT, got %s", element.Type())
+			return object.NewError(node, "elements in array must be INTEGER or FLOAT, got %s", object.TypeName(element))
 		}
 
 		if element.Type() == object.FLOAT_OBJ {
@@ -5,565 +5,631 @@ import (
 )
 
 func TestType(t *testing.T) {
-	input := `type(1)`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `type(1)`
 
-	testStringObject(t, testEval(input), "INTEGER")
+		testStringObject(t, testEval(engine, input), "INTEGER")
 
-	input = `type(1.0)`
+		input = `type(1.0)`
 
-	testStringObject(t, testEval(input), "FLOAT")
+		testStringObject(t, testEval(engine, input), "FLOAT")
 
-	input = `type("hello")`
+		input = `type("hello")`
 
-	testStringObject(t, testEval(input), "STRING")
+		testStringObject(t, testEval(engine, input), "STRING")
 
-	input = `type([1, 2, 3])`
+		input = `type([1, 2, 3])`
 
-	testStringObject(t, testEval(input), "ARRAY")
+		testStringObject(t, testEval(engine, input), "ARRAY")
 
-	input = `type({"key": "value"})`
+		input = `type({"key": "value"})`
 
-	testStringObject(t, testEval(input), "HASH")
+		testStringObject(t, testEval(engine, input), "HASH")
 
-	input = `type(true)`
+		input = `type(true)`
 
-	testStringObject(t, testEval(input), "BOOLEAN")
+		testStringObject(t, testEval(engine, input), "BOOLEAN")
 
-	input = `type(false)`
+		input = `type(false)`
 
-	testStringObject(t, testEval(input), "BOOLEAN")
+		testStringObject(t, testEval(engine, input), "BOOLEAN")
 
-	input = `type(null)`
+		input = `type(null)`
 
-	testStringObject(t, testEval(input), "NULL")
+		testStringObject(t, testEval(engine, input), "NULL")
 
-	input = `type()`
+		input = `type()`
 
-	testErrorObject(t, testEval(input), "[1:6] wrong number of arguments. got 0, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:6] wrong number of arguments. got 0, want 1")
+	})
 }
 
 func TestRange(t *testing.T) {
-	input := `range(5)`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `range(5)`
 
-	testArrayObject(t, testEval(input), []string{"0", "1", "2", "3", "4"})
+		testArrayObject(t, testEval(engine, input), []string{"0", "1", "2", "3", "4"})
 
-	input = `range(0)`
+		input = `range(0)`
 
-	testArrayObject(t, testEval(input), []string{})
+		testArrayObject(t, testEval(engine, input), []string{})
 
-	input = `range(5, 10)`
+		input = `range(5, 10)`
 
-	testArrayObject(t, testEval(input), []string{"5", "6", "7", "8", "9"})
+		testArrayObject(t, testEval(engine, input), []string{"5", "6", "7", "8", "9"})
 
-	input = `range(4, -3)`
+		input = `range(4, -3)`
 
-	testArrayObject(t, testEval(input), []string{"4", "3", "2", "1", "0", "-1", "-2"})
+		testArrayObject(t, testEval(engine, input), []string{"4", "3", "2", "1", "0", "-1", "-2"})
 
-	input = `range(4, 4)`
+		input = `range(4, 4)`
 
-	testArrayObject(t, testEval(input), []string{})
+		testArrayObject(t, testEval(engine, input), []string{})
 
-	input = `range(-2, 3)`
+		input = `range(-2, 3)`
 
-	testArrayObject(t, testEval(input), []string{"-2", "-1", "0", "1", "2"})
+		testArrayObject(t, testEval(engine, input), []string{"-2", "-1", "0", "1", "2"})
 
-	input = `range(-1)`
+		input = `range(-1)`
 
-	testErrorObject(t, testEval(input), "[1:7] argument to `range` must be non-negative, got -1")
+		testErrorObject(t, testEval(engine, input), "[1:7] argument to `range` must be non-negative, got -1")
 
-	input = `range("hello")`
+		input = `range("hello")`
 
-	testErrorObject(t, testEval(input), "[1:7] first argument to `range` must be INTEGER, got STRING")
+		testErrorObject(t, testEval(engine, input), "[1:7] first argument to `range` must be INTEGER, got STRING")
 
-	input = `range()`
+		input = `range()`
 
-	testErrorObject(t, testEval(input), "[1:7] wrong number of arguments. got 0, want 1 or 2")
+		testErrorObject(t, testEval(engine, input), "[1:7] wrong number of arguments. got 0, want 1 or 2")
 
-	input = `range(1, "test")`
+		input = `range(1, "test")`
 
-	testErrorObject(t, testEval(input), "[1:7] second argument to `range` must be INTEGER, got STRING")
+		testErrorObject(t, testEval(engine, input), "[1:7] second argument to `range` must be INTEGER, got STRING")
+	})
 }
 
 func TestInt(t *testing.T) {
-	input := `int(1)`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `int(1)`
 
-	testIntegerObject(t, testEval(input), 1)
+		testIntegerObject(t, testEval(engine, input), 1)
 
-	input = `int(1.0)`
+		input = `int(1.0)`
 
-	testIntegerObject(t, testEval(input), 1)
+		testIntegerObject(t, testEval(engine, input), 1)
 
-	input = `int("1")`
+		input = `int("1")`
 
-	testIntegerObject(t, testEval(input), 1)
+		testIntegerObject(t, testEval(engine, input), 1)
 
-	input = `int("1.0")`
+		input = `int("1.0")`
 
-	testErrorObject(t, testEval(input), "[1:5] could not parse \"1.0\" as INTEGER")
+		testErrorObject(t, testEval(engine, input), "[1:5] could not parse \"1.0\" as INTEGER")
 
-	input = `int("hello")`
+		input = `int("hello")`
 
-	testErrorObject(t, testEval(input), "[1:5] could not parse \"hello\" as INTEGER")
+		testErrorObject(t, testEval(engine, input), "[1:5] could not parse \"hello\" as INTEGER")
 
-	input = `int()`
+		input = `int()`
 
-	testErrorObject(t, testEval(input), "[1:5] wrong number of arguments. got 0, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:5] wrong number of arguments. got 0, want 1")
 
-	input = `int([1])`
+		input = `int([1])`
 
-	testErrorObject(t, testEval(input), "[1:5] argument to `int` not supported, got ARRAY")
+		testErrorObject(t, testEval(engine, input), "[1:5] argument to `int` not supported, got ARRAY")
+	})
 }
 
 func TestFloat(t *testing.T) {
-	input := `float(1)`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `float(1)`
 
-	testFloatObject(t, testEval(input), 1.0)
+		testFloatObject(t, testEval(engine, input), 1.0)
 
-	input = `float(1.0)`
+		input = `float(1.0)`
 
-	testFloatObject(t, testEval(input), 1.0)
+		testFloatObject(t, testEval(engine, input), 1.0)
 
-	input = `float("1")`
+		input = `float("1")`
 
-	testFloatObject(t, testEval(input), 1.0)
+		testFloatObject(t, testEval(engine, input), 1.0)
 
-	input = `float("1.0")`
+		input = `float("1.0")`
 
-	testFloatObject(t, testEval(input), 1.0)
+		testFloatObject(t, testEval(engine, input), 1.0)
 
-	input = `float("hello")`
+		input = `float("hello")`
 
-	testErrorObject(t, testEval(input), "[1:7] could not parse \"hello\" as FLOAT")
+		testErrorObject(t, testEval(engine, input), "[1:7] could not parse \"hello\" as FLOAT")
 
-	input = `float()`
+		input = `float()`
 
-	testErrorObject(t, testEval(input), "[1:7] wrong number of arguments. got 0, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:7] wrong number of arguments. got 0, want 1")
 
-	input = `float([1])`
+		input = `float([1])`
 
-	testErrorObject(t, testEval(input), "[1:7] argument to `float` not supported, got ARRAY")
+		testErrorObject(t, testEval(engine, input), "[1:7] argument to `float` not supported, got ARRAY")
+	})
 }
 
 func TestString(t *testing.T) {
-	input := `string(1)`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `string(1)`
 
-	testStringObject(t, testEval(input), "1")
+		testStringObject(t, testEval(engine, input), "1")
 
-	input = `string(1.0)`
+		input = `string(1.0)`
 
-	testStringObject(t, testEval(input), "1")
+		testStringObject(t, testEval(engine, input), "1")
 
-	input = `string("1")`
+		input = `string("1")`
 
-	testStringObject(t, testEval(input), "1")
+		testStringObject(t, testEval(engine, input), "1")
 
-	input = `string("hello")`
+		input = `string("hello")`
 
-	testStringObject(t, testEval(input), "hello")
+		testStringObject(t, testEval(engine, input), "hello")
 
-	input = `string([1])`
+		input = `string([1])`
 
-	testStringObject(t, testEval(input), "[1]")
+		testStringObject(t, testEval(engine, input), "[1]")
 
-	input = `string({1: 1})`
+		input = `string({1: 1})`
 
-	testStringObject(t, testEval(input), "{1: 1}")
+		testStringObject(t, testEval(engine, input), "{1: 1}")
 
-	input = `string()`
+		input = `string()`
 
-	testErrorObject(t, testEval(input), "[1:8] wrong number of arguments. got 0, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:8] wrong number of arguments. got 0, want 1")
+	})
 }
 
 func TestBool(t *testing.T) {
-	input := `bool(true)`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `bool(true)`
 
-	testBooleanObject(t, testEval(input), true)
+		testBooleanObject(t, testEval(engine, input), true)
 
-	input = `bool(false)`
+		input = `bool(false)`
 
-	testBooleanObject(t, testEval(input), false)
+		testBooleanObject(t, testEval(engine, input), false)
 
-	input = `bool(1)`
+		input = `bool(1)`
 
-	testBooleanObject(t, testEval(input), true)
+		testBooleanObject(t, testEval(engine, input), true)
 
-	input = `bool(0)`
+		input = `bool(0)`
 
-	testBooleanObject(t, testEval(input), false)
+		testBooleanObject(t, testEval(engine, input), false)
 
-	input = `bool(1.0)`
+		input = `bool(1.0)`
 
-	testBooleanObject(t, testEval(input), true)
+		testBooleanObject(t, testEval(engine, input), true)
 
-	input = `bool(0.0)`
+		input = `bool(0.0)`
 
-	testBooleanObject(t, testEval(input), false)
+		testBooleanObject(t, testEval(engine, input), false)
 
-	input = `bool("true")`
+		input = `bool("true")`
 
-	testBooleanObject(t, testEval(input), true)
+		testBooleanObject(t, testEval(engine, input), true)
 
-	input = `bool("false")`
+		input = `bool("false")`
 
-	testBooleanObject(t, testEval(input), true)
+		testBooleanObject(t, testEval(engine, input), true)
 
-	input = `bool("hello")`
+		input = `bool("hello")`
 
-	testBooleanObject(t, testEval(input), true)
+		testBooleanObject(t, testEval(engine, input), true)
 
-	input = `bool([1])`
+		input = `bool([1])`
 
-	testBooleanObject(t, testEval(input), true)
+		testBooleanObject(t, testEval(engine, input), true)
 
-	input = `bool([])`
+		input = `bool([])`
 
-	testBooleanObject(t, testEval(input), false)
+		testBooleanObject(t, testEval(engine, input), false)
 
-	input = `bool({1: 1})`
+		input = `bool({1: 1})`
 
-	testBooleanObject(t, testEval(input), true)
+		testBooleanObject(t, testEval(engine, input), true)
 
-	input = `bool({})`
+		input = `bool({})`
 
-	testBooleanObject(t, testEval(input), false)
+		testBooleanObject(t, testEval(engine, input), false)
 
-	input = `bool(null)`
+		input = `bool(null)`
 
-	testBooleanObject(t, testEval(input), false)
+		testBooleanObject(t, testEval(engine, input), false)
 
-	input = `bool()`
+		input = `bool()`
 
-	testErrorObject(t, testEval(input), "[1:6] wrong number of arguments. got 0, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:6] wrong number of arguments. got 0, want 1")
 
-	input = `bool(continue)`
+		input = `bool(continue)`
 
-	testErrorObject(t, testEval(input), "[1:6] argument to `bool` not supported, got CONTINUE")
+		testErrorObject(t, testEval(engine, input), "[1:6] argument to `bool` not supported, got CONTINUE")
+	})
 }
 
 func TestLen(t *testing.T) {
-	input := `len([1, 2, 3, 4])`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `len([1, 2, 3, 4])`
 
-	testIntegerObject(t, testEval(input), 4)
+		testIntegerObject(t, testEval(engine, input), 4)
 
-	input = `len("hello")`
+		input = `len("hello")`
 
-	testIntegerObject(t, testEval(input), 5)
+		testIntegerObject(t, testEval(engine, input), 5)
 
-	input = `len("hello" + " world")`
+		input = `len("hello" + " world")`
 
-	testIntegerObject(t, testEval(input), 11)
+		testIntegerObject(t, testEval(engine, input), 11)
 
-	input = `len([])`
+		input = `len([])`
 
-	testIntegerObject(t, testEval(input), 0)
+		testIntegerObject(t, testEval(engine, input), 0)
 
-	input = `len("")`
+		input = `len("")`
 
-	testIntegerObject(t, testEval(input), 0)
+		testIntegerObject(t, testEval(engine, input), 0)
 
-	input = `len(1)`
+		input = `len(1)`
 
-	result := testEval(input)
+		result := testEval(engine, input)
 
-	testErrorObject(t, result, "[1:5] argument to `len` not supported, got INTEGER")
+		testErrorObject(t, result, "[1:5] argument to `len` not supported, got INTEGER")
 
-	input = `len([1, 2, 3], [4, 5, 6])`
+		input = `len([1, 2, 3], [4, 5, 6])`
 
-	testErrorObject(t, testEval(input), "[1:5] wrong number of arguments. got 2, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:5] wrong number of arguments. got 2, want 1")
+	})
 }
 
 func TestFirst(t *testing.T) {
-	input := `first([1, 2, 3, 4])`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `first([1, 2, 3, 4])`
 
-	testIntegerObject(t, testEval(input), 1)
+		testIntegerObject(t, testEval(engine, input), 1)
 
-	input = `first([])`
+		input = `first([])`
 
-	testNullObject(t, testEval(input))
+		testNullObject(t, testEval(engine, input))
 
-	input = `first("hello")`
+		input = `first("hello")`
 
-	testStringObject(t, testEval(input), "h")
+		testStringObject(t, testEval(engine, input), "h")
 
-	input = `first(1234)`
+		input = `first(1234)`
 
-	testErrorObject(t, testEval(input), "[1:7] argument to `first` must be ARRAY or STRING, got INTEGER")
+		testErrorObject(t, testEval(engine, input), "[1:7] argument to `first` must be ARRAY or STRING, got INTEGER")
 
-	input = `first([1, 2, 3], [4, 5, 6])`
+		input = `first([1, 2, 3], [4, 5, 6])`
 
-	testErrorObject(t, testEval(input), "[1:7] wrong number of arguments. got 2, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:7] wrong number of arguments. got 2, want 1")
+	})
 }
 
 func TestLast(t *testing.T) {
-	input := `last([1, 2, 3, 4])`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `last([1, 2, 3, 4])`
 
-	testIntegerObject(t, testEval(input), 4)
+		testIntegerObject(t, testEval(engine, input), 4)
 
-	input = `last([])`
+		input = `last([])`
 
-	testNullObject(t, testEval(input))
+		testNullObject(t, testEval(engine, input))
 
-	input = `last("hello")`
+		input = `last("hello")`
 
-	testStringObject(t, testEval(input), "o")
+		testStringObject(t, testEval(engine, input), "o")
 
-	input = `last(1234)`
+		input = `last(1234)`
 
-	testErrorObject(t, testEval(input), "[1:6] argument to `last` must be ARRAY or STRING, got INTEGER")
+		testErrorObject(t, testEval(engine, input), "[1:6] argument to `last` must be ARRAY or STRING, got INTEGER")
 
-	input = `last([1, 2, 3], [4, 5, 6])`
+		input = `last([1, 2, 3], [4, 5, 6])`
 
-	testErrorObject(t, testEval(input), "[1:6] wrong number of arguments. got 2, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:6] wrong number of arguments. got 2, want 1")
+	})
 }
 
 func TestRest(t *testing.T) {
-	input := `rest([1, 2, 3, 4])`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `rest([1, 2, 3, 4])`
 
-	testArrayObject(t, testEval(input), []string{"2", "3", "4"})
+		testArrayObject(t, testEval(engine, input), []string{"2", "3", "4"})
 
-	input = `rest([])`
+		input = `rest([])`
 
-	testNullObject(t, testEval(input))
+		testNullObject(t, testEval(engine, input))
 
-	input = `rest(1234)`
+		input = `rest(1234)`
 
-	testErrorObject(t, testEval(input), "[1:6] argument to `rest` must be ARRAY, got INTEGER")
+		testErrorObject(t, testEval(engine, input), "[1:6] argument to `rest` must be ARRAY, got INTEGER")
 
-	input = `rest([1, 2, 3], [4, 5, 6])`
+		input = `rest([1, 2, 3], [4, 5, 6])`
 
-	testErrorObject(t, testEval(input), "[1:6] wrong number of arguments. got 2, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:6] wrong number of arguments. got 2, want 1")
+	})
 }
 
 func TestPrint(t *testing.T) {
-	input := `print("hello", "world")`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `print("hello", "world")`
 
-	testNullObject(t, testEval(input))
+		testNullObject(t, testEval(engine, input))
 
-	input = `print("hello", "world", 1)`
+		input = `print("hello", "world", 1)`
 
-	testNullObject(t, testEval(input))
+		testNullObject(t, testEval(engine, input))
+	})
 }
 
 func TestAbs(t *testing.T) {
-	input := `abs(1)`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `abs(1)`
 
-	testIntegerObject(t, testEval(input), 1)
+		testIntegerObject(t, testEval(engine, input), 1)
 
-	input = `abs(-1)`
+		input = `abs(-1)`
 
-	testIntegerObject(t, testEval(input), 1)
+		testIntegerObject(t, testEval(engine, input), 1)
 
-	input = `abs(1.0)`
+		input = `abs(1.0)`
 
-	testFloatObject(t, testEval(input), 1.0)
+		testFloatObject(t, testEval(engine, input), 1.0)
 
-	input = `abs(-1.0)`
+		input = `abs(-1.0)`
 
-	testFloatObject(t, testEval(input), 1.0)
+		testFloatObject(t, testEval(engine, input), 1.0)
 
-	input = `abs("test")`
+		input = `abs("test")`
 
-	testErrorObject(t, testEval(input), "[1:5] argument to `abs` must be INTEGER or FLOAT, got STRING")
+		testErrorObject(t, testEval(engine, input), "[1:5] argument to `abs` must be INTEGER or FLOAT, got STRING")
 
-	input = `abs()`
+		input = `abs()`
 
-	testErrorObject(t, testEval(input), "[1:5] wrong number of arguments. got 0, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:5] wrong number of arguments. got 0, want 1")
+
+		input = `abs(-9223372036854775807 - 1)`
+
+		testErrorObject(t, testEval(engine, input), "[1:5] integer overflow: abs(-9223372036854775808)")
+
+		input = `abs(-9223372036854775807)`
+
+		testIntegerObject(t, testEval(engine, input), 9223372036854775807)
+	})
 }
 
 func TestPow(t *testing.T) {
-	input := `pow(2, 3)`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `pow(2, 3)`
+
+		testIntegerObject(t, testEval(engine, input), 8)
+
+		input = `pow(2, 0)`
+
+		testIntegerObject(t, testEval(engine, input), 1)
+
+		input = `pow(2, -1)`
+
+		testFloatObject(t, testEval(engine, input), 0.5)
+
+		input = `pow(-2, 3)`
+
+		testIntegerObject(t, testEval(engine, input), -8)
+
+		input = `pow(-2, 0)`
+
+		testIntegerObject(t, testEval(engine, input), 1)
+
+		input = `pow(-2, -1)`
 
-	testIntegerObject(t, testEval(input), 8)
+		testFloatObject(t, testEval(engine, input), -0.5)
 
-	input = `pow(2, 0)`
+		input = `pow(2, 3.0)`
 
-	testIntegerObject(t, testEval(input), 1)
+		testFloatObject(t, testEval(engine, input), 8.0)
 
-	input = `pow(2, -1)`
+		input = `pow(2.0, 3)`
 
-	testFloatObject(t, testEval(input), 0.5)
+		testFloatObject(t, testEval(engine, input), 8.0)
 
-	input = `pow(-2, 3)`
+		input = `pow(2.0, 3.0)`
 
-	testIntegerObject(t, testEval(input), -8)
+		testFloatObject(t, testEval(engine, input), 8.0)
 
-	input = `pow(-2, 0)`
+		input = `pow(2, 62)`
 
-	testIntegerObject(t, testEval(input), 1)
+		testIntegerObject(t, testEval(engine, input), 4611686018427387904)
 
-	input = `pow(-2, -1)`
+		input = `pow(-2, 63)`
 
-	testFloatObject(t, testEval(input), -0.5)
+		testIntegerObject(t, testEval(engine, input), -9223372036854775808)
 
-	input = `pow(2, 3.0)`
+		input = `pow(1, 9223372036854775807)`
 
-	testFloatObject(t, testEval(input), 8.0)
+		testIntegerObject(t, testEval(engine, input), 1)
 
-	input = `pow(2.0, 3)`
+		input = `pow(2, 63)`
 
-	testFloatObject(t, testEval(input), 8.0)
+		testErrorObject(t, testEval(engine, input), "[1:5] integer overflow: pow(2, 63)")
 
-	input = `pow(2.0, 3.0)`
+		input = `pow(10, 100)`
 
-	testFloatObject(t, testEval(input), 8.0)
+		testErrorObject(t, testEval(engine, input), "[1:5] integer overflow: pow(10, 100)")
 
-	input = `pow(2)`
+		input = `pow(2)`
 
-	testErrorObject(t, testEval(input), "[1:5] wrong number of arguments. got 1, want 2")
+		testErrorObject(t, testEval(engine, input), "[1:5] wrong number of arguments. got 1, want 2")
 
-	input = `pow("test", 3)`
+		input = `pow("test", 3)`
 
-	testErrorObject(t, testEval(input), "[1:5] arguments to `pow` must be INTEGER or FLOAT, got STRING and INTEGER")
+		testErrorObject(t, testEval(engine, input), "[1:5] arguments to `pow` must be INTEGER or FLOAT, got STRING and INTEGER")
 
-	input = `pow(2, "test")`
+		input = `pow(2, "test")`
 
-	testErrorObject(t, testEval(input), "[1:5] arguments to `pow` must be INTEGER or FLOAT, got INTEGER and STRING")
+		testErrorObject(t, testEval(engine, input), "[1:5] arguments to `pow` must be INTEGER or FLOAT, got INTEGER and STRING")
+	})
 }
 
 func TestSqrt(t *testing.T) {
-	input := `sqrt(4)`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `sqrt(4)`
 
-	testFloatObject(t, testEval(input), 2.0)
+		testFloatObject(t, testEval(engine, input), 2.0)
 
-	input = `sqrt(0)`
+		input = `sqrt(0)`
 
-	testFloatObject(t, testEval(input), 0.0)
+		testFloatObject(t, testEval(engine, input), 0.0)
 
-	input = `sqrt(-1)`
+		input = `sqrt(-1)`
 
-	testErrorObject(t, testEval(input), "[1:6] argument to `sqrt` must be non-negative, got -1")
+		testErrorObject(t, testEval(engine, input), "[1:6] argument to `sqrt` must be non-negative, got -1")
 
-	input = `sqrt(4.0)`
+		input = `sqrt(4.0)`
 
-	testFloatObject(t, testEval(input), 2.0)
+		testFloatObject(t, testEval(engine, input), 2.0)
 
-	input = `sqrt("test")`
+		input = `sqrt("test")`
 
-	testErrorObject(t, testEval(input), "[1:6] argument to `sqrt` must be INTEGER or FLOAT, got STRING")
+		testErrorObject(t, testEval(engine, input), "[1:6] argument to `sqrt` must be INTEGER or FLOAT, got STRING")
 
-	input = `sqrt(4, 5)`
+		input = `sqrt(4, 5)`
 
-	testErrorObject(t, testEval(input), "[1:6] wrong number of arguments. got 2, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:6] wrong number of arguments. got 2, want 1")
+	})
 }
 
 func TestSin(t *testing.T) {
-	input := `sin(0)`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `sin(0)`
 
-	testFloatObject(t, testEval(input), 0.0)
+		testFloatObject(t, testEval(engine, input), 0.0)
 
-	input = `sin(1)`
+		input = `sin(1)`
 
-	testFloatObject(t, testEval(input), 0.8414709848078965)
+		testFloatObject(t, testEval(engine, input), 0.8414709848078965)
 
-	input = `sin(1.0)`
+		input = `sin(1.0)`
 
-	testFloatObject(t, testEval(input), 0.8414709848078965)
+		testFloatObject(t, testEval(engine, input), 0.8414709848078965)
 
-	input = `sin("test")`
+		input = `sin("test")`
 
-	testErrorObject(t, testEval(input), "[1:5] argument to `sin` must be INTEGER or FLOAT, got STRING")
+		testErrorObject(t, testEval(engine, input), "[1:5] argument to `sin` must be INTEGER or FLOAT, got STRING")
 
-	input = `sin()`
+		input = `sin()`
 
-	testErrorObject(t, testEval(input), "[1:5] wrong number of arguments. got 0, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:5] wrong number of arguments. got 0, want 1")
+	})
 }
 
 func TestCos(t *testing.T) {
-	input := `cos(0)`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `cos(0)`
 
-	testFloatObject(t, testEval(input), 1.0)
+		testFloatObject(t, testEval(engine, input), 1.0)
 
-	input = `cos(1)`
+		input = `cos(1)`
 
-	testFloatObject(t, testEval(input), 0.5403023058681398)
+		testFloatObject(t, testEval(engine, input), 0.5403023058681398)
 
-	input = `cos(1.0)`
+		input = `cos(1.0)`
 
-	testFloatObject(t, testEval(input), 0.5403023058681398)
+		testFloatObject(t, testEval(engine, input), 0.5403023058681398)
 
-	input = `cos("test")`
+		input = `cos("test")`
 
-	testErrorObject(t, testEval(input), "[1:5] argument to `cos` must be INTEGER or FLOAT, got STRING")
+		testErrorObject(t, testEval(engine, input), "[1:5] argument to `cos` must be INTEGER or FLOAT, got STRING")
 
-	input = `cos()`
+		input = `cos()`
 
-	testErrorObject(t, testEval(input), "[1:5] wrong number of arguments. got 0, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:5] wrong number of arguments. got 0, want 1")
+	})
 }
 
 func TestTan(t *testing.T) {
-	input := `tan(0)`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `tan(0)`
 
-	testFloatObject(t, testEval(input), 0.0)
+		testFloatObject(t, testEval(engine, input), 0.0)
 
-	input = `tan(1)`
+		input = `tan(1)`
 
-	testFloatObject(t, testEval(input), 1.557407724654902)
+		testFloatObject(t, testEval(engine, input), 1.557407724654902)
 
-	input = `tan(1.0)`
+		input = `tan(1.0)`
 
-	testFloatObject(t, testEval(input), 1.557407724654902)
+		testFloatObject(t, testEval(engine, input), 1.557407724654902)
 
-	input = `tan("test")`
+		input = `tan("test")`
 
-	testErrorObject(t, testEval(input), "[1:5] argument to `tan` must be INTEGER or FLOAT, got STRING")
+		testErrorObject(t, testEval(engine, input), "[1:5] argument to `tan` must be INTEGER or FLOAT, got STRING")
 
-	input = `tan()`
+		input = `tan()`
 
-	testErrorObject(t, testEval(input), "[1:5] wrong number of arguments. got 0, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:5] wrong number of arguments. got 0, want 1")
+	})
 }
 
 func TestSum(t *testing.T) {
-	input := `sum([1, 2, 3, 4])`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `sum([1, 2, 3, 4])`
 
-	testIntegerObject(t, testEval(input), 10)
+		testIntegerObject(t, testEval(engine, input), 10)
 
-	input = `sum([])`
+		input = `sum([])`
 
-	testIntegerObject(t, testEval(input), 0)
+		testIntegerObject(t, testEval(engine, input), 0)
 
-	input = `sum([1, 2, 3, 4.5])`
+		input = `sum([1, 2, 3, 4.5])`
 
-	testFloatObject(t, testEval(input), 10.5)
+		testFloatObject(t, testEval(engine, input), 10.5)
 
-	input = `sum([1, 2, 3, 4], [5, 6, 7, 8])`
+		input = `sum([1, 2, 3, 4], [5, 6, 7, 8])`
 
-	testErrorObject(t, testEval(input), "[1:5] wrong number of arguments. got 2, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:5] wrong number of arguments. got 2, want 1")
 
-	input = `sum(1)`
+		input = `sum(1)`
 
-	testErrorObject(t, testEval(input), "[1:5] argument to `sum` must be ARRAY, got INTEGER")
+		testErrorObject(t, testEval(engine, input), "[1:5] argument to `sum` must be ARRAY, got INTEGER")
 
-	input = `sum([1, 2, 3, "4"])`
+		input = `sum([1, 2, 3, "4"])`
 
-	testErrorObject(t, testEval(input), "[1:5] elements in array must be INTEGER or FLOAT, got STRING")
+		testErrorObject(t, testEval(engine, input), "[1:5] elements in array must be INTEGER or FLOAT, got STRING")
+	})
 }
 
 func TestMean(t *testing.T) {
-	input := `mean([1, 2, 3, 4])`
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `mean([1, 2, 3, 4])`
 
-	testFloatObject(t, testEval(input), 2.5)
+		testFloatObject(t, testEval(engine, input), 2.5)
 
-	input = `mean([1, 2, 3, 4, 5])`
+		input = `mean([1, 2, 3, 4, 5])`
 
-	testIntegerObject(t, testEval(input), 3)
+		testIntegerObject(t, testEval(engine, input), 3)
 
-	input = `mean([])`
+		input = `mean([])`
 
-	testIntegerObject(t, testEval(input), 0)
+		testIntegerObject(t, testEval(engine, input), 0)
 
-	input = `mean([1, 2, 3, 4.5])`
+		input = `mean([1, 2, 3, 4.5])`
 
-	testFloatObject(t, testEval(input), 2.625)
+		testFloatObject(t, testEval(engine, input), 2.625)
 
-	input = `mean([1, 2, 3, 4], [5, 6, 7, 8])`
+		input = `mean([1, 2, 3, 4], [5, 6, 7, 8])`
 
-	testErrorObject(t, testEval(input), "[1:6] wrong number of arguments. got 2, want 1")
+		testErrorObject(t, testEval(engine, input), "[1:6] wrong number of arguments. got 2, want 1")
 
-	input = `mean(1)`
+		input = `mean(1)`
 
-	testErrorObject(t, testEval(input), "[1:6] argument to `mean` must be ARRAY, got INTEGER")
+		testErrorObject(t, testEval(engine, input), "[1:6] argument to `mean` must be ARRAY, got INTEGER")
 
-	input = `mean([1, 2, 3, "4"])`
+		input = `mean([1, 2, 3, "4"])`
 
-	testErrorObject(t, testEval(input), "[1:6] elements in array must be INTEGER or FLOAT, got STRING")
+		testErrorObject(t, testEval(engine, input), "[1:6] elements in array must be INTEGER or FLOAT, got STRING")
+	})
 }
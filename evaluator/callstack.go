@@ -0,0 +1,114 @@
+package evaluator
+
+import (
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/object"
+)
+
+/*
+Get the name of a function, builtin or struct for the call stack
+*/
+func callName(function object.Object) string {
+	switch function := function.(type) {
+	case *object.Function:
+		if function.Name != "" {
+			return function.Name
+		}
+	case *object.Builtin:
+		if function.Name != "" {
+			return function.Name
+		}
+	case *object.Struct:
+		return function.Name
+	}
+
+	return "<anonymous>"
+}
+
+/*
+Get the file the code of a called function, builtin or struct is in
+*/
+func (e *Evaluator) callFile(function object.Object) string {
+	if function, ok := function.(*object.Function); ok {
+		return function.File
+	}
+
+	return e.file
+}
+
+/*
+Push a call named name made at node onto the call stack, running the code in file until the call is popped.
+
+Returns an error at node when the call would exceed the maximum call depth.
+*/
+func (e *Evaluator) pushCall(node ast.Node, name string, file string) *object.Error {
+	if len(e.callStack) >= e.maxCallDepth {
+		return object.NewError(node, "maximum recursion depth exceeded")
+	}
+
+	e.callStack = append(e.callStack, object.Frame{Name: name, Node: node, File: e.file})
+	e.callerFiles = append(e.callerFiles, e.file)
+	e.file = file
+
+	return nil
+}
+
+func (e *Evaluator) popCall() {
+	e.callStack = e.callStack[:len(e.callStack)-1]
+	e.file = e.callerFiles[len(e.callerFiles)-1]
+	e.callerFiles = e.callerFiles[:len(e.callerFiles)-1]
+}
+
+/*
+Record the call stack in the error a call returns.
+
+The stack is recorded by the innermost call the error is returned from, which is where the error happened,
+so the calls it is returned from after that keep it.
+*/
+func (e *Evaluator) recordCallStack(result object.Object) object.Object {
+	if err, ok := result.(*object.Error); ok && err.Stack == nil {
+		err.Stack = make([]object.Frame, len(e.callStack))
+		copy(err.Stack, e.callStack)
+	}
+
+	return e.recordFile(result)
+}
+
+/*
+Record the file that is running in the error a file, call or program returns, unless the error already has a file
+*/
+func (e *Evaluator) recordFile(result object.Object) object.Object {
+	if err, ok := result.(*object.Error); ok && err.File == "" {
+		err.File = e.file
+	}
+
+	return result
+}
+
+/*
+Call a builtin or a method of a string, array or object, which is named name on the call stack
+*/
+func (e *Evaluator) callNative(node ast.Node, name string, call func() object.Object) object.Object {
+	if err := e.pushCall(node, name, e.file); err != nil {
+		return err
+	}
+
+	defer e.popCall()
+
+	return e.recordCallStack(runNative(node, call))
+}
+
+/*
+Run a builtin or method, turning a panic of the function into an error at its call.
+
+The error points to the call in every engine, instead of the statement or instruction that made it.
+*/
+func runNative(node ast.Node, call func() object.Object) (result object.Object) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result = object.NewError(node, "internal error: %v", recovered)
+		}
+	}()
+
+	return call()
+}
@@ -13,16 +13,39 @@ import (
  * Array chaining functions
  */
 
+/*
+Get the maximum amount of arguments a callback accepts, -1 if it accepts any amount of arguments.
+
+Arguments with a default value are counted, so they are passed when available.
+*/
 func getCallbackArgumentsCount(f object.Object, chainingExpression string) (int, *object.Error) {
 	if f.Type() == object.BUILTIN_OBJ {
 		return f.(*object.Builtin).ArgumentsCount, nil
 	} else if f.Type() == object.FUNCTION_OBJ {
-		return len(f.(*object.Function).Arguments), nil
+		return f.(*object.Function).MaxArguments(), nil
 	} else {
-		return -1, object.NewError(f.Node(), "%s callback must be a function, got %s", chainingExpression, f.Type())
+		return -1, object.NewError(f.Node(), "%s callback must be a function, got %s", chainingExpression, object.TypeName(f))
 	}
 }
 
+/*
+Check if a callback that accepts argumentsCount arguments can be called with at least the given amount of arguments
+*/
+func acceptsArguments(argumentsCount int, amount int) bool {
+	return argumentsCount == -1 || argumentsCount >= amount
+}
+
+/*
+Get the arguments to call a callback with, leaving out the arguments the callback does not accept
+*/
+func callbackArguments(args []object.Object, argumentsCount int) []object.Object {
+	if argumentsCount == -1 || argumentsCount > len(args) {
+		return args
+	}
+
+	return args[:argumentsCount]
+}
+
 func (e *Evaluator) sortObjects(elements []object.Object, reverse bool) []object.Object {
 	// Copy the elements to a new slice to avoid modifying the original array
 	sorted := make([]object.Object, len(elements))
@@ -126,7 +149,7 @@ func (e *Evaluator) arrayConcat(arr *object.Array, args ...object.Object) object
 
 	for _, arg := range args {
 		if arg.Type() != object.ARRAY_OBJ {
-			return object.NewError(arr.Node(), "argument to `Array.concat()` must be ARRAY, got %s", arg.Type())
+			return object.NewError(arr.Node(), "argument to `Array.concat()` must be ARRAY, got %s", object.TypeName(arg))
 		}
 
 		concatenated = append(concatenated, arg.(*object.Array).Elements...)
@@ -147,7 +170,7 @@ func (e *Evaluator) arrayMap(arr *object.Array, args ...object.Object) object.Ob
 		return err
 	}
 
-	if callbackArgumentsCount < 1 {
+	if !acceptsArguments(callbackArgumentsCount, 1) {
 		return object.NewError(f.Node(), "Array.map() callback must take at least 1 argument")
 	}
 
@@ -174,7 +197,7 @@ func (e *Evaluator) arrayMap(arr *object.Array, args ...object.Object) object.Ob
 		args := []object.Object{el, object.NewInteger(arr.Node(), int64(i)), arr}
 
 		// Handle functions that require only a certain amount of arguments
-		value := e.applyFunction(callExpression, f, args[:callbackArgumentsCount])
+		value := e.applyFunction(callExpression, f, callbackArguments(args, callbackArgumentsCount))
 
 		// If the value is an error, return the error
 		if _, ok := value.(*object.Error); ok {
@@ -199,7 +222,7 @@ func (e *Evaluator) arrayFilter(arr *object.Array, args ...object.Object) object
 		return err
 	}
 
-	if callbackArgumentsCount < 1 {
+	if !acceptsArguments(callbackArgumentsCount, 1) {
 		return object.NewError(f.Node(), "Array.filter() callback must take at least 1 argument")
 	}
 
@@ -226,7 +249,7 @@ func (e *Evaluator) arrayFilter(arr *object.Array, args ...object.Object) object
 		args := []object.Object{el, object.NewInteger(arr.Node(), int64(i)), arr}
 
 		// Handle functions that require only a certain amount of arguments
-		value := e.applyFunction(callExpression, f, args[:callbackArgumentsCount])
+		value := e.applyFunction(callExpression, f, callbackArguments(args, callbackArgumentsCount))
 
 		if _, ok := value.(*object.Error); ok {
 			return value
@@ -256,7 +279,7 @@ func (e *Evaluator) arrayReduce(arr *object.Array, args ...object.Object) object
 		return err
 	}
 
-	if callbackArgumentsCount < 2 {
+	if !acceptsArguments(callbackArgumentsCount, 2) {
 		return object.NewError(f.Node(), "Array.reduce() callback must take at least 2 arguments")
 	}
 
@@ -281,7 +304,7 @@ func (e *Evaluator) arrayReduce(arr *object.Array, args ...object.Object) object
 		args := []object.Object{accumulator, el, object.NewInteger(arr.Node(), int64(i)), arr}
 
 		// Handle functions that require only a certain amount of arguments
-		accumulator = e.applyFunction(callExpression, f, args[:callbackArgumentsCount])
+		accumulator = e.applyFunction(callExpression, f, callbackArguments(args, callbackArgumentsCount))
 
 		// If the value is an error, return the error
 		if _, ok := accumulator.(*object.Error); ok {
@@ -298,7 +321,13 @@ func (e *Evaluator) arrayContains(arr *object.Array, args ...object.Object) obje
 	}
 
 	for _, el := range arr.Elements {
-		if el.Inspect() == args[0].Inspect() {
+		equal, err := e.equal(arr.Node(), el, args[0])
+
+		if err != nil {
+			return err
+		}
+
+		if equal {
 			return object.TRUE
 		}
 	}
@@ -312,7 +341,13 @@ func (e *Evaluator) arrayIndexOf(arr *object.Array, args ...object.Object) objec
 	}
 
 	for i, el := range arr.Elements {
-		if el.Inspect() == args[0].Inspect() {
+		equal, err := e.equal(arr.Node(), el, args[0])
+
+		if err != nil {
+			return err
+		}
+
+		if equal {
 			return object.NewInteger(arr.Node(), int64(i))
 		}
 	}
@@ -333,7 +368,7 @@ func (e *Evaluator) arrayFind(arr *object.Array, args ...object.Object) object.O
 		return err
 	}
 
-	if callbackArgumentsCount < 1 {
+	if !acceptsArguments(callbackArgumentsCount, 1) {
 		return object.NewError(f.Node(), "Array.find() callback must take at least 1 argument")
 	}
 
@@ -358,7 +393,7 @@ func (e *Evaluator) arrayFind(arr *object.Array, args ...object.Object) object.O
 		args := []object.Object{el, object.NewInteger(arr.Node(), int64(i)), arr}
 
 		// Handle functions that require only a certain amount of arguments
-		value := e.applyFunction(callExpression, f, args[:callbackArgumentsCount])
+		value := e.applyFunction(callExpression, f, callbackArguments(args, callbackArgumentsCount))
 
 		// If the value is an error, return the error
 		if _, ok := value.(*object.Error); ok {
@@ -385,7 +420,7 @@ func (e *Evaluator) arrayJoin(arr *object.Array, args ...object.Object) object.O
 
 	if len(args) == 1 {
 		if args[0].Type() != object.STRING_OBJ {
-			return object.NewError(arr.Node(), "argument to `Array.join()` must be STRING, got %s", args[0].Type())
+			return object.NewError(arr.Node(), "argument to `Array.join()` must be STRING, got %s", object.TypeName(args[0]))
 		}
 
 		separator = args[0].(*object.String).Value
@@ -422,7 +457,7 @@ func (e *Evaluator) arraySlice(arr *object.Array, args ...object.Object) object.
 	}
 
 	if args[0].Type() != object.INTEGER_OBJ {
-		return object.NewError(arr.Node(), "first argument to `Array.slice()` must be INTEGER, got %s", args[0].Type())
+		return object.NewError(arr.Node(), "first argument to `Array.slice()` must be INTEGER, got %s", object.TypeName(args[0]))
 	}
 
 	start := int(args[0].(*object.Integer).Value)
@@ -430,7 +465,7 @@ func (e *Evaluator) arraySlice(arr *object.Array, args ...object.Object) object.
 
 	if len(args) == 2 {
 		if args[1].Type() != object.INTEGER_OBJ {
-			return object.NewError(arr.Node(), "second argument to `Array.slice()` must be INTEGER, got %s", args[1].Type())
+			return object.NewError(arr.Node(), "second argument to `Array.slice()` must be INTEGER, got %s", object.TypeName(args[1]))
 		}
 
 		end = int(args[1].(*object.Integer).Value)
@@ -469,14 +504,14 @@ func (e *Evaluator) arraySort(arr *object.Array, args ...object.Object) object.O
 
 			return arr
 		} else if args[0].Type() != object.FUNCTION_OBJ && args[0].Type() != object.BUILTIN_OBJ {
-			return object.NewError(arr.Node(), "argument to `Array.sort()` must be BOOLEAN, FUNCTION or BUILTIN, got %s", args[0].Type())
+			return object.NewError(arr.Node(), "argument to `Array.sort()` must be BOOLEAN, FUNCTION or BUILTIN, got %s", object.TypeName(args[0]))
 		}
 	}
 
 	f := args[0]
 	callbackArgumentsCount, _ := getCallbackArgumentsCount(f, "Array.sort()")
 
-	if callbackArgumentsCount < 2 {
+	if !acceptsArguments(callbackArgumentsCount, 2) {
 		return object.NewError(f.Node(), "Array.sort() callback must take at least 2 arguments")
 	}
 
@@ -506,7 +541,7 @@ func (e *Evaluator) arraySort(arr *object.Array, args ...object.Object) object.O
 		args := []object.Object{arr.Elements[i], arr.Elements[j], object.NewInteger(arr.Node(), int64(i)), object.NewInteger(arr.Node(), int64(j)), arr}
 
 		// Handle functions that require only a certain amount of arguments
-		value := e.applyFunction(callExpression, f, args[:callbackArgumentsCount])
+		value := e.applyFunction(callExpression, f, callbackArguments(args, callbackArgumentsCount))
 
 		// If the value is an error, return the error
 		if e, ok := value.(*object.Error); ok {
@@ -536,7 +571,7 @@ func (e *Evaluator) arrayAny(arr *object.Array, args ...object.Object) object.Ob
 		return err
 	}
 
-	if callbackArgumentsCount < 1 {
+	if !acceptsArguments(callbackArgumentsCount, 1) {
 		return object.NewError(f.Node(), "Array.any() callback must take at least 1 argument")
 	}
 
@@ -561,7 +596,7 @@ func (e *Evaluator) arrayAny(arr *object.Array, args ...object.Object) object.Ob
 		args := []object.Object{el, object.NewInteger(arr.Node(), int64(i)), arr}
 
 		// Handle functions that require only a certain amount of arguments
-		value := e.applyFunction(callExpression, f, args[:callbackArgumentsCount])
+		value := e.applyFunction(callExpression, f, callbackArguments(args, callbackArgumentsCount))
 
 		// If the value is an error, return the error
 		if _, ok := value.(*object.Error); ok {
@@ -591,7 +626,7 @@ func (e *Evaluator) arrayEvery(arr *object.Array, args ...object.Object) object.
 		return err
 	}
 
-	if callbackArgumentsCount < 1 {
+	if !acceptsArguments(callbackArgumentsCount, 1) {
 		return object.NewError(f.Node(), "Array.every() callback must take at least 1 argument")
 	}
 
@@ -616,7 +651,7 @@ func (e *Evaluator) arrayEvery(arr *object.Array, args ...object.Object) object.
 		args := []object.Object{el, object.NewInteger(arr.Node(), int64(i)), arr}
 
 		// Handle functions that require only a certain amount of arguments
-		value := e.applyFunction(callExpression, f, args[:callbackArgumentsCount])
+		value := e.applyFunction(callExpression, f, callbackArguments(args, callbackArgumentsCount))
 
 		// If the value is an error, return the error
 		if _, ok := value.(*object.Error); ok {
@@ -671,7 +706,7 @@ func (e *Evaluator) stringSplit(str *object.String, args ...object.Object) objec
 
 	if len(args) == 1 {
 		if args[0].Type() != object.STRING_OBJ {
-			return object.NewError(str.Node(), "argument to `String.split()` must be STRING, got %s", args[0].Type())
+			return object.NewError(str.Node(), "argument to `String.split()` must be STRING, got %s", object.TypeName(args[0]))
 		}
 
 		separator = args[0].(*object.String).Value
@@ -693,7 +728,7 @@ func (e *Evaluator) stringContains(str *object.String, args ...object.Object) ob
 	}
 
 	if args[0].Type() != object.STRING_OBJ {
-		return object.NewError(str.Node(), "argument to `String.contains()` must be STRING, got %s", args[0].Type())
+		return object.NewError(str.Node(), "argument to `String.contains()` must be STRING, got %s", object.TypeName(args[0]))
 	}
 
 	return e.nativeBoolToBooleanObject(strings.Contains(str.Value, args[0].(*object.String).Value))
@@ -705,11 +740,11 @@ func (e *Evaluator) stringReplace(str *object.String, args ...object.Object) obj
 	}
 
 	if args[0].Type() != object.STRING_OBJ {
-		return object.NewError(str.Node(), "first argument to `String.replace()` must be STRING, got %s", args[0].Type())
+		return object.NewError(str.Node(), "first argument to `String.replace()` must be STRING, got %s", object.TypeName(args[0]))
 	}
 
 	if args[1].Type() != object.STRING_OBJ {
-		return object.NewError(str.Node(), "second argument to `String.replace()` must be STRING, got %s", args[1].Type())
+		return object.NewError(str.Node(), "second argument to `String.replace()` must be STRING, got %s", object.TypeName(args[1]))
 	}
 
 	return object.NewString(str.Node(), strings.ReplaceAll(str.Value, args[0].(*object.String).Value, args[1].(*object.String).Value))
@@ -745,7 +780,7 @@ func (e *Evaluator) stringRepeat(str *object.String, args ...object.Object) obje
 	}
 
 	if args[0].Type() != object.INTEGER_OBJ {
-		return object.NewError(str.Node(), "argument to `String.repeat()` must be INTEGER, got %s", args[0].Type())
+		return object.NewError(str.Node(), "argument to `String.repeat()` must be INTEGER, got %s", object.TypeName(args[0]))
 	}
 
 	intValue := int(args[0].(*object.Integer).Value)
@@ -777,7 +812,7 @@ func (e *Evaluator) stringSlice(str *object.String, args ...object.Object) objec
 	}
 
 	if args[0].Type() != object.INTEGER_OBJ {
-		return object.NewError(str.Node(), "first argument to `String.slice()` must be INTEGER, got %s", args[0].Type())
+		return object.NewError(str.Node(), "first argument to `String.slice()` must be INTEGER, got %s", object.TypeName(args[0]))
 	}
 
 	start := int(args[0].(*object.Integer).Value)
@@ -785,7 +820,7 @@ func (e *Evaluator) stringSlice(str *object.String, args ...object.Object) objec
 
 	if len(args) == 2 {
 		if args[1].Type() != object.INTEGER_OBJ {
-			return object.NewError(str.Node(), "second argument to `String.slice()` must be INTEGER, got %s", args[1].Type())
+			return object.NewError(str.Node(), "second argument to `String.slice()` must be INTEGER, got %s", object.TypeName(args[1]))
 		}
 
 		end = int(args[1].(*object.Integer).Value)
@@ -812,7 +847,7 @@ func (e *Evaluator) stringStartsWith(str *object.String, args ...object.Object)
 	}
 
 	if args[0].Type() != object.STRING_OBJ {
-		return object.NewError(str.Node(), "argument to `String.startsWith()` must be STRING, got %s", args[0].Type())
+		return object.NewError(str.Node(), "argument to `String.startsWith()` must be STRING, got %s", object.TypeName(args[0]))
 	}
 
 	return e.nativeBoolToBooleanObject(strings.HasPrefix(str.Value, args[0].(*object.String).Value))
@@ -824,7 +859,7 @@ func (e *Evaluator) stringEndsWith(str *object.String, args ...object.Object) ob
 	}
 
 	if args[0].Type() != object.STRING_OBJ {
-		return object.NewError(str.Node(), "argument to `String.endsWith()` must be STRING, got %s", args[0].Type())
+		return object.NewError(str.Node(), "argument to `String.endsWith()` must be STRING, got %s", object.TypeName(args[0]))
 	}
 
 	return e.nativeBoolToBooleanObject(strings.HasSuffix(str.Value, args[0].(*object.String).Value))
@@ -839,13 +874,10 @@ func (e *Evaluator) objectKeys(hash *object.Hash, args ...object.Object) object.
 		return object.NewError(hash.Node(), "Object.keys() takes no arguments")
 	}
 
-	keys := make([]object.Object, len(hash.Pairs))
-
-	i := 0
+	keys := make([]object.Object, len(hash.Keys))
 
-	for key := range hash.Pairs {
+	for i, key := range hash.Keys {
 		keys[i] = object.NewString(hash.Node(), hash.Pairs[key].Key.Inspect())
-		i++
 	}
 
 	return object.NewArray(hash.Node(), keys)
@@ -856,14 +888,10 @@ func (e *Evaluator) objectValues(hash *object.Hash, args ...object.Object) objec
 		return object.NewError(hash.Node(), "Object.values() takes no arguments")
 	}
 
-	values := make([]object.Object, len(hash.Pairs))
+	values := make([]object.Object, len(hash.Keys))
 
-	i := 0
-
-	for _, pair := range hash.Pairs {
-		values[i] = pair.Value
-
-		i++
+	for i, key := range hash.Keys {
+		values[i] = hash.Pairs[key].Value
 	}
 
 	return object.NewArray(hash.Node(), values)
@@ -874,14 +902,11 @@ func (e *Evaluator) objectItems(hash *object.Hash, args ...object.Object) object
 		return object.NewError(hash.Node(), "Object.items() takes no arguments")
 	}
 
-	items := make([]object.Object, len(hash.Pairs))
-
-	i := 0
-
-	for key, pair := range hash.Pairs {
-		items[i] = object.NewArray(hash.Node(), []object.Object{object.NewString(hash.Node(), hash.Pairs[key].Key.Inspect()), pair.Value})
+	items := make([]object.Object, len(hash.Keys))
 
-		i++
+	for i, key := range hash.Keys {
+		pair := hash.Pairs[key]
+		items[i] = object.NewArray(hash.Node(), []object.Object{object.NewString(hash.Node(), pair.Key.Inspect()), pair.Value})
 	}
 
 	return object.NewArray(hash.Node(), items)
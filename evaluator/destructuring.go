@@ -0,0 +1,104 @@
+package evaluator
+
+import (
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/object"
+)
+
+/*
+Bind a value to all the names in a pattern, which is an Identifier, ArrayPattern or HashPattern.
+
+Elements that are missing or null get their default value, which is evaluated after the
+elements before it are bound so it can refer to them.
+*/
+func (e *Evaluator) destructure(pattern ast.Expression, value object.Object, env *object.Environment) *object.Error {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		env.Define(pattern, value)
+
+		return nil
+	case *ast.ArrayPattern:
+		array, ok := value.(*object.Array)
+
+		if !ok {
+			return object.NewError(pattern, "can not destructure %s as an array, expected ARRAY", object.TypeName(value))
+		}
+
+		for i, element := range pattern.Elements {
+			var elementValue object.Object = object.NULL
+
+			if i < len(array.Elements) {
+				elementValue = array.Elements[i]
+			}
+
+			if err := e.destructureElement(element, elementValue, env); err != nil {
+				return err
+			}
+		}
+
+		if pattern.Rest != nil {
+			rest := []object.Object{}
+
+			if len(array.Elements) > len(pattern.Elements) {
+				rest = append(rest, array.Elements[len(pattern.Elements):]...)
+			}
+
+			env.Define(pattern.Rest, object.NewArray(pattern.Rest, rest))
+		}
+
+		return nil
+	case *ast.HashPattern:
+		hash, ok := value.(*object.Hash)
+
+		if !ok {
+			return object.NewError(pattern, "can not destructure %s as an object, expected HASH", object.TypeName(value))
+		}
+
+		used := map[object.HashKey]bool{}
+
+		for _, element := range pattern.Elements {
+			var elementValue object.Object = object.NULL
+
+			if property, ok := hashProperty(element.Key, hash, element.Key.Value); ok {
+				elementValue = property
+			}
+
+			used[object.NewString(element.Key, element.Key.Value).HashKey()] = true
+
+			if err := e.destructureElement(element, elementValue, env); err != nil {
+				return err
+			}
+		}
+
+		if pattern.Rest != nil {
+			rest := object.NewHash(pattern.Rest)
+
+			for _, hashed := range hash.Keys {
+				if !used[hashed] {
+					rest.Set(hashed, hash.Pairs[hashed])
+				}
+			}
+
+			env.Define(pattern.Rest, rest)
+		}
+
+		return nil
+	default: // coverage-ignore
+		return object.NewError(pattern, "invalid destructuring pattern %s", pattern.String())
+	}
+}
+
+/*
+Bind the value of a single element of a pattern, using the default value of the element if the value is null
+*/
+func (e *Evaluator) destructureElement(element *ast.PatternElement, value object.Object, env *object.Environment) *object.Error {
+	if value.Type() == object.NULL_OBJ && element.Default != nil {
+		value = e.Eval(element.Default, env)
+
+		if isError(value) {
+			return value.(*object.Error)
+		}
+	}
+
+	return e.destructure(element.Target, value, env)
+}
@@ -0,0 +1,44 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/compiler"
+	"github.com/iskandervdh/vorn/object"
+	"github.com/iskandervdh/vorn/optimizer"
+	"github.com/iskandervdh/vorn/resolver"
+	"github.com/iskandervdh/vorn/vm"
+)
+
+// The engines a program can be run with: the evaluator, the vm and the evaluator on the optimized program
+var engines = []string{"evaluator", "vm", "optimized"}
+
+/*
+Run a test with every engine as a subtest named after the engine, so all engines are held to the same expectations
+*/
+func testEngines(t *testing.T, test func(t *testing.T, engine string)) {
+	for _, engine := range engines {
+		t.Run(engine, func(t *testing.T) {
+			test(t, engine)
+		})
+	}
+}
+
+/*
+Resolve a program and run it with the engine, returning the first error of the resolver if there are any
+*/
+func run(engine string, e *Evaluator, program *ast.Program, env *object.Environment) object.Object {
+	if errors := resolver.New(e).Resolve(program); len(errors) != 0 {
+		return errors[0]
+	}
+
+	switch engine {
+	case "vm":
+		return vm.New(e).Run(compiler.Compile(program), env)
+	case "optimized":
+		optimizer.New(e).Optimize(program)
+	}
+
+	return e.Eval(program, env)
+}
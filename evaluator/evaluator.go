@@ -2,8 +2,11 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"strings"
 
 	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/constants"
 	"github.com/iskandervdh/vorn/object"
 	"github.com/iskandervdh/vorn/token"
 )
@@ -18,15 +21,29 @@ type Evaluator struct {
 	stringChainingFunctions map[string]StringChainingFunction
 	arrayChainingFunctions  map[string]ArrayChainingFunction
 	objectChainingFunctions map[string]ObjectChainingFunction
+
+	modules     map[string]*object.Module // evaluated modules by their absolute path
+	importStack []string                  // absolute paths of the files that are being evaluated
+
+	callStack    []object.Frame // function calls that have not returned yet, outermost first
+	callerFiles  []string       // the file each call on the call stack returns to
+	file         string         // the file of the code that is running, empty when no file is set
+	maxCallDepth int
+
+	callCompiled func(node ast.Node, function *object.Function, args []object.Object) object.Object // calls functions compiled by the vm, nil when the vm is not running
 }
 
 func New() *Evaluator {
-	e := &Evaluator{}
+	e := &Evaluator{
+		modules:      map[string]*object.Module{},
+		maxCallDepth: constants.MAX_CALL_DEPTH,
+	}
 
 	e.builtins = map[string]*object.Builtin{
 		// Common
 		"type":  {Function: e.builtinType, ArgumentsCount: 1},
 		"range": {Function: e.builtinRange, ArgumentsCount: -1}, // Variable amount of arguments
+		"same":  {Function: e.builtinSame, ArgumentsCount: 2},
 
 		// Conversions
 		"int":    {Function: e.builtinInt, ArgumentsCount: 1},
@@ -56,6 +73,10 @@ func New() *Evaluator {
 		"mean": {Function: e.builtinMean, ArgumentsCount: 1},
 	}
 
+	for name, builtin := range e.builtins {
+		builtin.Name = name
+	}
+
 	e.stringChainingFunctions = map[string]StringChainingFunction{
 		"length":     e.stringLength,
 		"upper":      e.stringUpper,
@@ -103,28 +124,61 @@ func New() *Evaluator {
 	return e
 }
 
-func (e *Evaluator) evalProgram(program *ast.Program, env *object.Environment) object.Object {
-	var result object.Object
+/*
+Set the maximum amount of nested function calls.
+
+Calls that would exceed it stop the program with an error instead of exhausting the memory of the interpreter.
+*/
+func (e *Evaluator) SetMaxCallDepth(depth int) {
+	e.maxCallDepth = depth
+}
+
+/*
+Recover from a panic in the interpreter itself while evaluating a statement.
+
+Instead of crashing with a Go stack trace the panic is turned into an error
+at the location of the statement that was being evaluated.
+*/
+func recoverInternalError(statement *ast.Statement, result *object.Object) {
+	recovered := recover()
+
+	if recovered == nil {
+		return
+	}
+
+	if *statement == nil { // coverage-ignore
+		panic(recovered)
+	}
+
+	*result = object.NewError(*statement, "internal error: %v", recovered)
+}
+
+func (e *Evaluator) evalProgram(program *ast.Program, env *object.Environment) (result object.Object) {
+	var statement ast.Statement
 
-	for _, statement := range program.Statements {
+	defer recoverInternalError(&statement, &result)
+
+	for _, statement = range program.Statements {
 		result = e.Eval(statement, env)
 
 		switch result := result.(type) {
 		case *object.ReturnValue:
 			return result.Value
 		case *object.Error:
-			return result
+			return e.recordFile(result)
 		}
 	}
 
 	return result
 }
 
-func (e *Evaluator) evalBlockStatement(block *ast.BlockStatement, parentEnv *object.Environment) object.Object {
-	var result object.Object
-	env := object.NewEnclosedEnvironment(parentEnv)
+func (e *Evaluator) evalBlockStatement(block *ast.BlockStatement, parentEnv *object.Environment) (result object.Object) {
+	var statement ast.Statement
+	env := object.NewScope(parentEnv, block.Slots)
 
-	for _, statement := range block.Statements {
+	defer recoverInternalError(&statement, &result)
+
+	for _, statement = range block.Statements {
 		result = e.Eval(statement, env)
 
 		if result != nil {
@@ -211,6 +265,275 @@ func (e *Evaluator) evalForStatement(fs *ast.ForStatement, env *object.Environme
 	return object.NULL
 }
 
+/*
+Get a function that returns the next key and value of a for-in loop each time it is called,
+until there are no elements left.
+
+A call to `range` is iterated without creating the array of numbers.
+*/
+func (e *Evaluator) forInIterator(fs *ast.ForInStatement, env *object.Environment) (func() (object.Object, object.Object, bool), *object.Error) {
+	call, ok := fs.Iterable.(*ast.CallExpression)
+
+	if !ok {
+		return e.iterator(fs, e.Eval(fs.Iterable, env))
+	}
+
+	// The function is evaluated only once, whether it turns out to be range or not
+	function := e.Eval(call.Function, env)
+
+	if isError(function) {
+		return nil, function.(*object.Error)
+	}
+
+	args, err := e.evalExpressions(call.Arguments, env)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if function == e.builtins["range"] {
+		return rangeIterator(call, args)
+	}
+
+	return e.iterator(fs, e.applyFunction(call, function, args))
+}
+
+/*
+Get a function that returns the next index and number of a call to `range` each time it is called
+*/
+func rangeIterator(call *ast.CallExpression, args []object.Object) (func() (object.Object, object.Object, bool), *object.Error) {
+	start, end, err := rangeBounds(call, args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	step := int64(1)
+
+	if start > end {
+		step = -1
+	}
+
+	current := start
+	index := int64(0)
+
+	return func() (object.Object, object.Object, bool) {
+		if current == end {
+			return nil, nil, false
+		}
+
+		key, value := object.NewInteger(call, index), object.NewInteger(call, current)
+		current += step
+		index++
+
+		return key, value, true
+	}, nil
+}
+
+/*
+Get a function that returns the next key and value of the evaluated iterable of a for-in loop each time it is called.
+
+Arrays give their indices and elements, strings the indices and characters and objects their keys and values.
+*/
+func (e *Evaluator) iterator(fs *ast.ForInStatement, iterable object.Object) (func() (object.Object, object.Object, bool), *object.Error) {
+	if isError(iterable) {
+		return nil, iterable.(*object.Error)
+	}
+
+	var keys, values []object.Object
+
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		values = iterable.Elements
+
+		for i := range values {
+			keys = append(keys, object.NewInteger(fs.Iterable, int64(i)))
+		}
+	case *object.String:
+		for i, char := range []rune(iterable.Value) {
+			keys = append(keys, object.NewInteger(fs.Iterable, int64(i)))
+			values = append(values, object.NewString(fs.Iterable, string(char)))
+		}
+	case *object.Hash:
+		for _, key := range iterable.Keys {
+			pair := iterable.Pairs[key]
+			keys = append(keys, pair.Key)
+			values = append(values, pair.Value)
+		}
+
+		// Without a separate key variable the loop variable is the key of the object
+		if fs.Key == nil {
+			values = keys
+		}
+	default:
+		return nil, object.NewError(fs.Iterable, "can not iterate over %s, expected ARRAY, STRING or HASH", object.TypeName(iterable))
+	}
+
+	index := 0
+
+	return func() (object.Object, object.Object, bool) {
+		if index >= len(values) {
+			return nil, nil, false
+		}
+
+		index++
+
+		return keys[index-1], values[index-1], true
+	}, nil
+}
+
+/*
+Evaluate a for-in loop, binding the loop variables in a new environment for every iteration
+so closures created in the body keep the values of their own iteration
+*/
+func (e *Evaluator) evalForInStatement(fs *ast.ForInStatement, env *object.Environment) object.Object {
+	next, err := e.forInIterator(fs, env)
+
+	if err != nil {
+		return err
+	}
+
+	for {
+		key, value, ok := next()
+
+		if !ok {
+			break
+		}
+
+		iterationEnv := object.NewScope(env, fs.Slots)
+
+		if fs.Key != nil {
+			iterationEnv.Define(fs.Key, key)
+		}
+
+		iterationEnv.Define(fs.Value, value)
+
+		result := e.evalBlockStatement(fs.Body, iterationEnv)
+
+		if result != nil {
+			resultType := result.Type()
+
+			if resultType == object.CONTINUE_OBJ {
+				continue
+			} else if resultType == object.BREAK_OBJ {
+				break
+			} else if resultType == object.RETURN_VALUE_OBJ || resultType == object.ERROR_OBJ {
+				return result
+			}
+		}
+	}
+
+	return object.NULL
+}
+
+func (e *Evaluator) evalThrowStatement(ts *ast.ThrowStatement, env *object.Environment) object.Object {
+	value := e.Eval(ts.Value, env)
+
+	if isError(value) {
+		return value
+	}
+
+	return throw(ts, value, env)
+}
+
+/*
+Create the error a throw statement throws with the evaluated value
+*/
+func throw(ts *ast.ThrowStatement, value object.Object, env *object.Environment) *object.Error {
+	var message string
+
+	switch value := value.(type) {
+	case *object.String:
+		message = value.Value
+	case *object.Hash:
+		// Rethrowing a caught error keeps its original message
+		pair, ok := value.Pairs[object.NewString(ts, "message").HashKey()]
+
+		if ok && pair.Value.Type() == object.STRING_OBJ {
+			message = pair.Value.(*object.String).Value
+		} else {
+			message = value.Inspect()
+		}
+
+		// and where it happened and the calls that led to it
+		if caught, ok := env.CaughtError(value); ok {
+			err := object.NewError(caught.Node(), "%s", message)
+			err.Value = caught.Value
+			err.Stack = caught.Stack
+			err.File = caught.File
+
+			return err
+		}
+	default:
+		message = value.Inspect()
+	}
+
+	err := object.NewError(ts, "%s", message)
+	err.Value = value
+
+	return err
+}
+
+/*
+Convert an error into a value that is bound to the parameter of a catch block in the environment of the block.
+
+The value is an object with the message, line and column of the error
+and the value that was thrown (null for runtime errors).
+The environment remembers the original error, so throwing the value again inside the block keeps it.
+*/
+func (e *Evaluator) caughtErrorObject(node ast.Node, err *object.Error, env *object.Environment) *object.Hash {
+	hash := object.NewHash(node)
+
+	set := func(key string, value object.Object) {
+		keyObject := object.NewString(node, key)
+		hash.Set(keyObject.HashKey(), object.HashPair{Key: keyObject, Value: value})
+	}
+
+	var value object.Object = object.NULL
+
+	if err.Value != nil {
+		value = err.Value
+	}
+
+	set("message", object.NewString(node, err.Description))
+	set("line", object.NewInteger(node, int64(err.Node().Line())))
+	set("column", object.NewInteger(node, int64(err.Node().Column())))
+	set("value", value)
+	env.Catch(hash, err)
+
+	return hash
+}
+
+func (e *Evaluator) evalTryStatement(ts *ast.TryStatement, env *object.Environment) object.Object {
+	result := e.evalBlockStatement(ts.Block, env)
+
+	if err, ok := result.(*object.Error); ok && ts.CatchBlock != nil {
+		catchEnv := object.NewScope(env, ts.CatchSlots)
+
+		if ts.CatchParameter != nil {
+			catchEnv.Define(ts.CatchParameter, e.caughtErrorObject(ts.CatchParameter, err, catchEnv))
+		}
+
+		result = e.evalBlockStatement(ts.CatchBlock, catchEnv)
+	}
+
+	if ts.FinallyBlock != nil {
+		finallyResult := e.evalBlockStatement(ts.FinallyBlock, env)
+
+		// A return, break, continue or error inside the finally block
+		// takes precedence over the result of the try and catch blocks
+		if finallyResult != nil {
+			rt := finallyResult.Type()
+
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ || rt == object.BREAK_OBJ || rt == object.CONTINUE_OBJ {
+				return finallyResult
+			}
+		}
+	}
+
+	return result
+}
+
 func (e *Evaluator) nativeBoolToBooleanObject(input bool) *object.Boolean {
 	if input {
 		return object.TRUE
@@ -232,10 +555,16 @@ func (e *Evaluator) evalExclamationOperatorExpression(right object.Object) objec
 	}
 }
 
-func (e *Evaluator) evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+func (e *Evaluator) evalMinusPrefixOperatorExpression(node *ast.PrefixExpression, right object.Object) object.Object {
 	switch right.Type() {
 	case object.INTEGER_OBJ:
 		value := right.(*object.Integer).Value
+
+		// The negation of the smallest integer does not fit in an integer
+		if value == math.MinInt64 {
+			return object.NewError(node, "integer overflow: -(%d)", value)
+		}
+
 		return object.NewInteger(right.Node(), -value)
 
 	case object.FLOAT_OBJ:
@@ -243,7 +572,7 @@ func (e *Evaluator) evalMinusPrefixOperatorExpression(right object.Object) objec
 		return object.NewFloat(right.Node(), -value)
 
 	default:
-		return object.NewError(right.Node(), "unknown operator: -%s", right.Type())
+		return object.NewError(right.Node(), "unknown operator: -%s", object.TypeName(right))
 	}
 }
 
@@ -254,7 +583,7 @@ func (e *Evaluator) evalBitwiseNotOperatorExpression(right object.Object) object
 		return object.NewInteger(right.Node(), ^value)
 
 	default:
-		return object.NewError(right.Node(), "unknown operator: ~%s", right.Type())
+		return object.NewError(right.Node(), "unknown operator: ~%s", object.TypeName(right))
 	}
 }
 
@@ -263,11 +592,11 @@ func (e *Evaluator) evalPrefixExpression(node *ast.PrefixExpression, right objec
 	case token.EXCLAMATION:
 		return e.evalExclamationOperatorExpression(right)
 	case token.MINUS:
-		return e.evalMinusPrefixOperatorExpression(right)
+		return e.evalMinusPrefixOperatorExpression(node, right)
 	case token.BITWISE_NOT:
 		return e.evalBitwiseNotOperatorExpression(right)
 	default:
-		return object.NewError(node, "unknown operator: %s%s", node.Operator, right.Type())
+		return object.NewError(node, "unknown operator: %s%s", node.Operator, object.TypeName(right))
 	}
 }
 
@@ -277,14 +606,40 @@ func (e *Evaluator) evalIntegerInfixExpression(node *ast.InfixExpression, left o
 
 	switch node.Operator {
 	case token.PLUS:
-		return object.NewInteger(node, leftVal+rightVal)
+		result, ok := addInt64(leftVal, rightVal)
+
+		if !ok {
+			return object.NewError(node, "integer overflow: %d + %d", leftVal, rightVal)
+		}
+
+		return object.NewInteger(node, result)
 	case token.MINUS:
-		return object.NewInteger(node, leftVal-rightVal)
+		result, ok := subInt64(leftVal, rightVal)
+
+		if !ok {
+			return object.NewError(node, "integer overflow: %d - %d", leftVal, rightVal)
+		}
+
+		return object.NewInteger(node, result)
 	case token.ASTERISK:
-		return object.NewInteger(node, leftVal*rightVal)
+		result, ok := mulInt64(leftVal, rightVal)
+
+		if !ok {
+			return object.NewError(node, "integer overflow: %d * %d", leftVal, rightVal)
+		}
+
+		return object.NewInteger(node, result)
 	case token.SLASH:
+		if rightVal == 0 {
+			return object.NewError(node, "division by zero")
+		}
+
 		return object.NewFloat(node, float64(leftVal)/float64(rightVal))
 	case token.PERCENT:
+		if rightVal == 0 {
+			return object.NewError(node, "modulo by zero")
+		}
+
 		return object.NewInteger(node, leftVal%rightVal)
 	case token.BITWISE_OR:
 		return object.NewInteger(node, leftVal|rightVal)
@@ -293,8 +648,23 @@ func (e *Evaluator) evalIntegerInfixExpression(node *ast.InfixExpression, left o
 	case token.BITWISE_XOR:
 		return object.NewInteger(node, leftVal^rightVal)
 	case token.LEFT_SHIFT:
-		return object.NewInteger(node, leftVal<<rightVal)
+		if rightVal < 0 {
+			return object.NewError(node, "negative shift amount: %d", rightVal)
+		}
+
+		result := leftVal << rightVal
+
+		// Bits that are shifted out, including every bit when shifting by 64 or more, are an overflow
+		if result>>rightVal != leftVal {
+			return object.NewError(node, "integer overflow: %d << %d", leftVal, rightVal)
+		}
+
+		return object.NewInteger(node, result)
 	case token.RIGHT_SHIFT:
+		if rightVal < 0 {
+			return object.NewError(node, "negative shift amount: %d", rightVal)
+		}
+
 		return object.NewInteger(node, leftVal>>rightVal)
 	case token.LT:
 		return e.nativeBoolToBooleanObject(leftVal < rightVal)
@@ -309,8 +679,43 @@ func (e *Evaluator) evalIntegerInfixExpression(node *ast.InfixExpression, left o
 	case token.NOT_EQ:
 		return e.nativeBoolToBooleanObject(leftVal != rightVal)
 	default:
-		return object.NewError(node, "unknown operator: %s %s %s", left.Type(), node.Operator, right.Type())
+		return object.NewError(node, "unknown operator: %s %s %s", object.TypeName(left), node.Operator, object.TypeName(right))
+	}
+}
+
+/*
+Add two integers, returning false if the result overflows
+*/
+func addInt64(a, b int64) (int64, bool) {
+	result := a + b
+
+	return result, (result > a) == (b > 0)
+}
+
+/*
+Subtract two integers, returning false if the result overflows
+*/
+func subInt64(a, b int64) (int64, bool) {
+	result := a - b
+
+	return result, (result < a) == (b > 0)
+}
+
+/*
+Multiply two integers, returning false if the result overflows
+*/
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
 	}
+
+	result := a * b
+
+	if result/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return result, false
+	}
+
+	return result, true
 }
 
 func (e *Evaluator) evalFloatInfixExpression(node *ast.InfixExpression, left object.Object, right object.Object) object.Object {
@@ -325,6 +730,10 @@ func (e *Evaluator) evalFloatInfixExpression(node *ast.InfixExpression, left obj
 	case token.ASTERISK:
 		return object.NewFloat(node, leftVal*rightVal)
 	case token.SLASH:
+		if rightVal == 0 {
+			return object.NewError(node, "division by zero")
+		}
+
 		return object.NewFloat(node, leftVal/rightVal)
 	case token.LT:
 		return e.nativeBoolToBooleanObject(leftVal < rightVal)
@@ -339,7 +748,7 @@ func (e *Evaluator) evalFloatInfixExpression(node *ast.InfixExpression, left obj
 	case token.NOT_EQ:
 		return e.nativeBoolToBooleanObject(leftVal != rightVal)
 	default:
-		return object.NewError(node, "unknown operator: %s %s %s", left.Type(), node.Operator, right.Type())
+		return object.NewError(node, "unknown operator: %s %s %s", object.TypeName(left), node.Operator, object.TypeName(right))
 	}
 }
 
@@ -362,7 +771,7 @@ func (e *Evaluator) evalNumberInfixExpression(node *ast.InfixExpression, left ob
 		return e.evalFloatInfixExpression(node, left, object.NewFloat(right.Node(), rightValue))
 
 	default:
-		return object.NewError(node, "type mismatch: %s %s %s", left.Type(), node.Operator, right.Type())
+		return object.NewError(node, "type mismatch: %s %s %s", object.TypeName(left), node.Operator, object.TypeName(right))
 	}
 }
 
@@ -378,40 +787,61 @@ func (e *Evaluator) evalStringInfixExpression(node *ast.InfixExpression, left, r
 	case token.NOT_EQ:
 		return e.nativeBoolToBooleanObject(leftVal != rightVal)
 	default:
-		return object.NewError(node, "unknown operator: %s %s %s", left.Type(), node.Operator, right.Type())
+		return object.NewError(node, "unknown operator: %s %s %s", object.TypeName(left), node.Operator, object.TypeName(right))
 	}
 }
 
 func (e *Evaluator) evalInfixExpression(node *ast.InfixExpression, left, right object.Object) object.Object {
 	switch {
-	case node.Operator == token.OR:
-		return e.nativeBoolToBooleanObject(
-			e.builtinBool(node, left).(*object.Boolean).Value || e.builtinBool(node, right).(*object.Boolean).Value,
-		)
-
-	case node.Operator == token.AND:
-		return e.nativeBoolToBooleanObject(
-			e.builtinBool(node, left).(*object.Boolean).Value && e.builtinBool(node, right).(*object.Boolean).Value,
-		)
-
 	case object.IsNumber(left) && object.IsNumber(right):
 		return e.evalNumberInfixExpression(node, left, right)
 
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return e.evalStringInfixExpression(node, left, right)
+	}
 
-	case node.Operator == token.EQ:
-		return e.nativeBoolToBooleanObject(left == right)
+	// Checked after numbers and strings to keep the most common operations fast
+	if isInstance(left) || isInstance(right) {
+		if result, ok := e.evalInfixMetamethod(node, left, right); ok {
+			return result
+		}
+	}
+
+	switch {
+	case node.Operator == token.EQ || node.Operator == token.NOT_EQ:
+		equal, err := e.equal(node, left, right)
 
-	case node.Operator == token.NOT_EQ:
-		return e.nativeBoolToBooleanObject(left != right)
+		if err != nil {
+			return err
+		}
+
+		return e.nativeBoolToBooleanObject(equal == (node.Operator == token.EQ))
 
 	case left.Type() != right.Type():
-		return object.NewError(node, "type mismatch: %s %s %s", left.Type(), node.Operator, right.Type())
+		return object.NewError(node, "type mismatch: %s %s %s", object.TypeName(left), node.Operator, object.TypeName(right))
 
 	default:
-		return object.NewError(node, "unknown operator: %s %s %s", left.Type(), node.Operator, right.Type())
+		return object.NewError(node, "unknown operator: %s %s %s", object.TypeName(left), node.Operator, object.TypeName(right))
+	}
+}
+
+/*
+Evaluate a logical && or || expression.
+
+The right-hand side is only evaluated when the left-hand side does not already determine the result.
+The value of the operand that determined the result is returned, so `a || b` returns a when a is truthy and b otherwise,
+and `a && b` returns a when a is falsy and b otherwise.
+*/
+func (e *Evaluator) evalLogicalExpression(node *ast.InfixExpression, left object.Object, env *object.Environment) object.Object {
+	if node.Operator == token.OR && isTruthy(left) {
+		return left
+	}
+
+	if node.Operator == token.AND && !isTruthy(left) {
+		return left
 	}
+
+	return e.Eval(node.Right, env)
 }
 
 func (e *Evaluator) evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
@@ -431,6 +861,54 @@ func (e *Evaluator) evalIfExpression(ie *ast.IfExpression, env *object.Environme
 
 }
 
+/*
+Evaluate an interpolated string by concatenating its literal parts and the string representation of its interpolations
+*/
+func (e *Evaluator) evalTemplateLiteral(tl *ast.TemplateLiteral, env *object.Environment) object.Object {
+	var out strings.Builder
+
+	for _, part := range tl.Parts {
+		if literal, ok := part.(*ast.StringLiteral); ok {
+			out.WriteString(literal.Value)
+
+			continue
+		}
+
+		value := e.Eval(part, env)
+
+		if isError(value) {
+			return value
+		}
+
+		str := e.toString(part, value)
+
+		if isError(str) {
+			return str
+		}
+
+		out.WriteString(str.(*object.String).Value)
+	}
+
+	return object.NewString(tl, out.String())
+}
+
+/*
+Evaluate a ternary expression, only evaluating the branch that is chosen by the condition
+*/
+func (e *Evaluator) evalTernaryExpression(te *ast.TernaryExpression, env *object.Environment) object.Object {
+	condition := e.Eval(te.Condition, env)
+
+	if isError(condition) {
+		return condition
+	}
+
+	if isTruthy(condition) {
+		return e.Eval(te.Consequence, env)
+	}
+
+	return e.Eval(te.Alternative, env)
+}
+
 func isTruthy(obj object.Object) bool {
 	switch obj {
 	case object.NULL:
@@ -444,6 +922,12 @@ func isTruthy(obj object.Object) bool {
 	}
 }
 
+func isInstance(obj object.Object) bool {
+	_, ok := obj.(*object.Instance)
+
+	return ok
+}
+
 func isError(obj object.Object) bool {
 	if obj != nil {
 		return obj.Type() == object.ERROR_OBJ
@@ -453,7 +937,7 @@ func isError(obj object.Object) bool {
 }
 
 func (e *Evaluator) evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
-	if val, _, ok := env.Get(node.Value); ok {
+	if val, ok := env.Lookup(node); ok {
 		return val
 	}
 
@@ -468,6 +952,17 @@ func (e *Evaluator) evalExpressions(expressions []ast.Expression, env *object.En
 	var result []object.Object
 
 	for _, expression := range expressions {
+		if spread, ok := expression.(*ast.SpreadExpression); ok {
+			elements, err := e.evalSpreadExpression(spread, env)
+
+			if err != nil {
+				return []object.Object{}, err
+			}
+
+			result = append(result, elements...)
+			continue
+		}
+
 		evaluated := e.Eval(expression, env)
 
 		if isError(evaluated) {
@@ -480,14 +975,125 @@ func (e *Evaluator) evalExpressions(expressions []ast.Expression, env *object.En
 	return result, nil
 }
 
-func (e *Evaluator) extendFunctionEnv(function *object.Function, args []object.Object) *object.Environment {
-	env := object.NewEnclosedEnvironment(function.Env)
+/*
+Evaluate a spread expression inside an array literal or call arguments, returning the elements it spreads into.
+Strings are spread into their characters.
+*/
+func (e *Evaluator) evalSpreadExpression(spread *ast.SpreadExpression, env *object.Environment) ([]object.Object, *object.Error) {
+	value := e.Eval(spread.Value, env)
+
+	if isError(value) {
+		return nil, value.(*object.Error)
+	}
+
+	switch value := value.(type) {
+	case *object.Array:
+		return value.Elements, nil
+	case *object.String:
+		elements := []object.Object{}
+
+		for _, char := range value.Value {
+			elements = append(elements, object.NewString(spread, string(char)))
+		}
+
+		return elements, nil
+	}
+
+	return nil, object.NewError(spread, "can not spread %s, expected ARRAY or STRING", object.TypeName(value))
+}
+
+/*
+Create the environment a function is called in, binding the given arguments to the argument names of the function.
+
+Missing optional arguments get their default value, which is evaluated in the new environment so it can refer
+to earlier arguments. Any remaining arguments are collected in an array for the rest argument.
+*/
+func (e *Evaluator) extendFunctionEnv(node ast.Node, function *object.Function, args []object.Object) (*object.Environment, *object.Error) {
+	if err := checkArgumentsCount(node, function, len(args)); err != nil {
+		return nil, err
+	}
+
+	env := object.NewScope(function.Env, function.Slots)
 
 	for i, argument := range function.Arguments {
-		env.Set(argument.Value, args[i])
+		var value object.Object
+
+		if i < len(args) {
+			value = args[i]
+		} else {
+			value = e.Eval(function.Defaults[argument.Value], env)
+
+			if isError(value) {
+				return nil, value.(*object.Error)
+			}
+		}
+
+		if pattern, ok := function.Patterns[argument.Value]; ok {
+			if err := e.destructure(pattern, value, env); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		env.Define(argument, value)
 	}
 
-	return env
+	if function.Rest != nil {
+		rest := []object.Object{}
+
+		if len(args) > len(function.Arguments) {
+			rest = append(rest, args[len(function.Arguments):]...)
+		}
+
+		env.Define(function.Rest, object.NewArray(function.Rest, rest))
+	}
+
+	return env, nil
+}
+
+/*
+Check if a function can be called with the given amount of arguments
+*/
+func checkArgumentsCount(node ast.Node, function *object.Function, count int) *object.Error {
+	return checkArgumentsRange(node, function.DisplayName(), count, function.MinArguments(), function.MaxArguments())
+}
+
+/*
+Check if the amount of arguments is between min and max, where a max of -1 means there is no maximum
+*/
+func checkArgumentsRange(node ast.Node, name string, count int, min int, max int) *object.Error {
+	if count >= min && (max == -1 || count <= max) {
+		return nil
+	}
+
+	var want string
+
+	switch {
+	case max == -1:
+		want = fmt.Sprintf("at least %d", min)
+	case min == max:
+		want = fmt.Sprintf("%d", min)
+	default:
+		want = fmt.Sprintf("%d to %d", min, max)
+	}
+
+	return object.NewError(node, "wrong number of arguments for %s. got %d, want %s", name, count, want)
+}
+
+/*
+Create the function defined by a function statement, using name in error messages
+*/
+func (e *Evaluator) functionFromStatement(node *ast.FunctionStatement, name string, env *object.Environment) *object.Function {
+	function := object.NewFunction(node, node.Arguments, node.Body, env)
+	function.Name = name
+	function.File = e.file
+	function.Defaults = node.Defaults
+	function.Rest = node.Rest
+	function.Patterns = node.Patterns
+	function.Slots = node.Slots
+
+	return function
 }
 
 func (e *Evaluator) unwrapReturnValue(obj object.Object) object.Object {
@@ -498,31 +1104,154 @@ func (e *Evaluator) unwrapReturnValue(obj object.Object) object.Object {
 	return obj
 }
 
-func (e *Evaluator) applyFunction(node *ast.CallExpression, function object.Object, args []object.Object) object.Object {
+func (e *Evaluator) applyFunction(node ast.Node, function object.Object, args []object.Object) object.Object {
 	switch function := function.(type) {
 	case *object.Function:
-		extendedEnv := e.extendFunctionEnv(function, args)
-		evaluated := e.Eval(function.Body, extendedEnv)
-
-		return e.unwrapReturnValue(evaluated)
+		return e.callFunction(node, function, args)
 	case *object.Builtin:
-		return function.Function(node, args...)
+		return e.callNative(node, callName(function), func() object.Object { return function.Function(node, args...) })
+	case *object.Struct:
+		return e.instantiate(node, function, args)
 	}
 
-	return object.NewError(node, "not a function: %s", function.Type())
+	return object.NewError(node, "not a function: %s", object.TypeName(function))
 }
 
-func (e *Evaluator) evalArrayIndexExpression(array, index object.Object) object.Object {
-	arrayObject := array.(*object.Array)
-	idx := index.(*object.Integer).Value
-	max := int64(len(arrayObject.Elements) - 1)
+/*
+Call a function, replacing the call with the calls it returns in tail position until it returns a value.
+
+Returns an error at the call when the maximum call depth is exceeded.
+*/
+func (e *Evaluator) callFunction(node ast.Node, function *object.Function, args []object.Object) object.Object {
+	// Functions compiled by the vm are run by the vm, like the callbacks of builtins and chaining functions
+	if function.Compiled != nil && e.callCompiled != nil {
+		return e.callCompiled(node, function, args)
+	}
+
+	if err := e.pushCall(node, callName(function), function.File); err != nil {
+		return err
+	}
+
+	defer e.popCall()
+
+	for {
+		extendedEnv, err := e.extendFunctionEnv(node, function, args)
 
-	// Negative index support from end of array
-	if idx < 0 {
-		idx = max + idx + 1
+		// Errors of the arguments are errors of the call, which happen before the function runs
+		if err != nil {
+			return err
+		}
+
+		evaluated := e.unwrapReturnValue(e.Eval(function.Body, extendedEnv))
+		tailCall, ok := evaluated.(*object.TailCall)
+
+		if !ok {
+			return e.recordCallStack(evaluated)
+		}
+
+		// The tail call takes the place of the call on the call stack
+		node, function, args = tailCall.Node(), tailCall.Function, tailCall.Arguments
+		e.callStack[len(e.callStack)-1] = object.Frame{Name: callName(function), Node: node, File: e.file}
+		e.file = function.File
+	}
+}
+
+/*
+Evaluate a return statement that returns a call in tail position, like f(x), self.m(x) or c ? f(x) : y.
+
+Calls of functions are returned as a tail call, so the function that returns it is replaced by the call
+instead of calling it while the function is still running. Other calls are made immediately.
+*/
+func (e *Evaluator) evalTailCall(node *ast.ReturnStatement, expression ast.Expression, env *object.Environment) object.Object {
+	value := e.evalTailExpression(expression, env)
+
+	if isError(value) {
+		return value
 	}
 
-	if idx > max {
+	if tailCall, ok := value.(*object.TailCall); ok {
+		return object.NewReturnValue(node, tailCall)
+	}
+
+	return object.NewReturnValue(node, value)
+}
+
+/*
+Evaluate a returned expression, giving a tail call instead of the value for the call of a function it ends in
+*/
+func (e *Evaluator) evalTailExpression(expression ast.Expression, env *object.Environment) object.Object {
+	switch expression := expression.(type) {
+	case *ast.CallExpression:
+		function := e.Eval(expression.Function, env)
+
+		if isError(function) {
+			return function
+		}
+
+		args, err := e.evalExpressions(expression.Arguments, env)
+
+		if err != nil {
+			return err
+		}
+
+		if function, ok := function.(*object.Function); ok {
+			return object.NewTailCall(expression, function, args)
+		}
+
+		return e.applyFunction(expression, function, args)
+
+	case *ast.ChainingExpression:
+		call, ok := expression.Call()
+
+		if !ok {
+			break
+		}
+
+		left := e.Eval(expression.Left, env)
+
+		if isError(left) {
+			return left
+		}
+
+		args, err := e.evalExpressions(call.Arguments, env)
+
+		if err != nil {
+			return err
+		}
+
+		if function, ok := e.chainingMethod(call, left); ok {
+			return object.NewTailCall(call, function, args)
+		}
+
+		return e.callChainingFunction(call, left, args)
+
+	case *ast.TernaryExpression:
+		condition := e.Eval(expression.Condition, env)
+
+		if isError(condition) {
+			return condition
+		}
+
+		if isTruthy(condition) {
+			return e.evalTailExpression(expression.Consequence, env)
+		}
+
+		return e.evalTailExpression(expression.Alternative, env)
+	}
+
+	return e.Eval(expression, env)
+}
+
+/*
+Get an element of an array, counting negative indices from the end of the array.
+
+Indices outside of the array, before its start or after its end, give null.
+*/
+func (e *Evaluator) evalArrayIndexExpression(array, index object.Object) object.Object {
+	arrayObject := array.(*object.Array)
+	idx, err := arrayElementIndex(index.Node(), arrayObject, index)
+
+	if err != nil {
 		return object.NULL
 	}
 
@@ -531,14 +1260,13 @@ func (e *Evaluator) evalArrayIndexExpression(array, index object.Object) object.
 
 func (e *Evaluator) evalHashIndexExpression(hash, index object.Object) object.Object {
 	hashObject := hash.(*object.Hash)
-	key, ok := index.(object.Hashable)
+	key, ok := object.HashKeyOf(index)
 
 	if !ok {
-		fmt.Println(index.Type())
-		return object.NewError(index.Node(), "unusable as object key: %s", index.Type())
+		return object.NewError(index.Node(), "unusable as object key: %s", object.TypeName(index))
 	}
 
-	pair, ok := hashObject.Pairs[key.HashKey()]
+	pair, ok := hashObject.Pairs[key]
 
 	if !ok {
 		return object.NULL
@@ -547,31 +1275,54 @@ func (e *Evaluator) evalHashIndexExpression(hash, index object.Object) object.Ob
 	return pair.Value
 }
 
-func (e *Evaluator) evalIndexExpression(left, index object.Object) object.Object {
+func (e *Evaluator) evalIndexExpression(node *ast.IndexExpression, left, index object.Object) object.Object {
 	switch {
+	case isInstance(left):
+		return e.evalInstanceIndexExpression(node, left.(*object.Instance), index)
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return e.evalArrayIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return e.evalHashIndexExpression(left, index)
 	default:
-		return object.NewError(index.Node(), "index operator not supported: %s", left.Type())
+		return object.NewError(index.Node(), "index operator not supported: %s", object.TypeName(left))
 	}
 }
 
 func (e *Evaluator) evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
+	hash := object.NewHash(node)
+
+	for _, keyNode := range node.Keys {
+		if spread, ok := keyNode.(*ast.SpreadExpression); ok {
+			value := e.Eval(spread.Value, env)
+
+			if isError(value) {
+				return value
+			}
+
+			spreadHash, ok := value.(*object.Hash)
+
+			if !ok {
+				return object.NewError(spread, "can not spread %s in object, expected HASH", object.TypeName(value))
+			}
+
+			for _, hashed := range spreadHash.Keys {
+				hash.Set(hashed, spreadHash.Pairs[hashed])
+			}
+
+			continue
+		}
 
-	for keyNode, valueNode := range node.Pairs {
+		valueNode := node.Pairs[keyNode]
 		key := e.Eval(keyNode, env)
 
 		if isError(key) {
 			return key
 		}
 
-		hashKey, ok := key.(object.Hashable)
+		hashed, ok := object.HashKeyOf(key)
 
 		if !ok {
-			return object.NewError(node, "unusable as object key: %s", key.Type())
+			return object.NewError(node, "unusable as object key: %s", object.TypeName(key))
 		}
 
 		value := e.Eval(valueNode, env)
@@ -580,22 +1331,47 @@ func (e *Evaluator) evalHashLiteral(node *ast.HashLiteral, env *object.Environme
 			return value
 		}
 
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: key, Value: value}
+		hash.Set(hashed, object.HashPair{Key: key, Value: value})
 	}
 
-	return object.NewHash(node, pairs)
+	return hash
 }
 
 func (e *Evaluator) evalChainingCallExpression(left ast.Node, rightCallExpression *ast.CallExpression, env *object.Environment) object.Object {
 	leftValue := e.Eval(left, env)
+
+	if isError(leftValue) {
+		return leftValue
+	}
+
 	args, err := e.evalExpressions(rightCallExpression.Arguments, env)
 
 	if err != nil {
 		return err
 	}
 
+	return e.callChainingFunction(rightCallExpression, leftValue, args)
+}
+
+/*
+Call the method of a chaining call like left.method(args) on the already evaluated left-hand side and arguments
+*/
+func (e *Evaluator) callChainingFunction(rightCallExpression *ast.CallExpression, leftValue object.Object, args []object.Object) object.Object {
 	switch leftValue := leftValue.(type) {
+	case *object.Module:
+		identifier, ok := rightCallExpression.Function.(*ast.Identifier)
+
+		if !ok { // coverage-ignore
+			break
+		}
+
+		member := e.moduleMember(leftValue, identifier)
+
+		if isError(member) {
+			return member
+		}
+
+		return e.applyFunction(rightCallExpression, member, args)
 	case *object.String:
 		chainingFunction, ok := e.stringChainingFunctions[rightCallExpression.Function.TokenLiteral()]
 
@@ -603,7 +1379,9 @@ func (e *Evaluator) evalChainingCallExpression(left ast.Node, rightCallExpressio
 			return object.NewError(rightCallExpression.Function, "String has no method %s", rightCallExpression.Function.TokenLiteral())
 		}
 
-		return chainingFunction(leftValue, args...)
+		return e.callNative(rightCallExpression, "String."+rightCallExpression.Function.TokenLiteral(), func() object.Object {
+			return chainingFunction(leftValue, args...)
+		})
 	case *object.Array:
 		chainingFunction, ok := e.arrayChainingFunctions[rightCallExpression.Function.TokenLiteral()]
 
@@ -611,22 +1389,65 @@ func (e *Evaluator) evalChainingCallExpression(left ast.Node, rightCallExpressio
 			return object.NewError(rightCallExpression.Function, "Array has no method %s", rightCallExpression.Function.TokenLiteral())
 		}
 
-		return chainingFunction(leftValue, args...)
+		return e.callNative(rightCallExpression, "Array."+rightCallExpression.Function.TokenLiteral(), func() object.Object {
+			return chainingFunction(leftValue, args...)
+		})
 	case *object.Hash:
-		chainingFunction, ok := e.objectChainingFunctions[rightCallExpression.Function.TokenLiteral()]
+		// Functions stored on the object itself take precedence over the built-in object methods
+		member, ok := hashProperty(rightCallExpression.Function, leftValue, rightCallExpression.Function.TokenLiteral())
 
-		if !ok {
-			return object.NewError(rightCallExpression.Function, "Object has no method %s", rightCallExpression.Function.TokenLiteral())
+		if ok && (member.Type() == object.FUNCTION_OBJ || member.Type() == object.BUILTIN_OBJ) {
+			return e.applyFunction(rightCallExpression, member, args)
+		}
+
+		chainingFunction, isMethod := e.objectChainingFunctions[rightCallExpression.Function.TokenLiteral()]
+
+		if isMethod {
+			return e.callNative(rightCallExpression, "Object."+rightCallExpression.Function.TokenLiteral(), func() object.Object {
+				return chainingFunction(leftValue, args...)
+			})
+		}
+
+		if ok {
+			return object.NewError(rightCallExpression.Function, "not a function: %s", object.TypeName(member))
+		}
+
+		return object.NewError(rightCallExpression.Function, "Object has no method %s", rightCallExpression.Function.TokenLiteral())
+	case *object.Instance:
+		return e.callInstanceMethod(leftValue, rightCallExpression, args)
+	}
+
+	return object.NewError(rightCallExpression.Function, "chaining operator not supported: %s.%s", object.TypeName(leftValue), rightCallExpression.Function.TokenLiteral())
+}
+
+/*
+Get the function a chaining call like left.method(args) calls, if it calls a function instead of a built-in method
+*/
+func (e *Evaluator) chainingMethod(call *ast.CallExpression, leftValue object.Object) (*object.Function, bool) {
+	var member object.Object
+
+	switch leftValue := leftValue.(type) {
+	case *object.Module:
+		if identifier, ok := call.Function.(*ast.Identifier); ok {
+			member = e.moduleMember(leftValue, identifier)
+		}
+	case *object.Hash:
+		member, _ = hashProperty(call.Function, leftValue, call.Function.TokenLiteral())
+	case *object.Instance:
+		if method, ok := leftValue.Struct.Methods[call.Function.TokenLiteral()]; ok {
+			return bindMethod(leftValue, method), true
 		}
 
-		return chainingFunction(leftValue, args...)
+		member = leftValue.Fields[call.Function.TokenLiteral()]
 	}
 
-	return object.NewError(rightCallExpression.Function, "chaining operator not supported: %s.%s", leftValue.Type(), rightCallExpression.Function.TokenLiteral())
+	function, ok := member.(*object.Function)
+
+	return function, ok
 }
 
 func (e *Evaluator) evalReassignmentExpression(node *ast.ReassignmentExpression, env *object.Environment) object.Object {
-	left, environment, defined := env.Get(node.Name.Value)
+	left, defined := env.Lookup(node.Name)
 
 	if !defined {
 		return object.NewError(node, "variable %s has not been initialized.", node.Name.Value)
@@ -639,39 +1460,19 @@ func (e *Evaluator) evalReassignmentExpression(node *ast.ReassignmentExpression,
 	}
 
 	if node.Token.Type == token.ASSIGN {
-		environment.Set(node.Name.Value, value)
+		env.Assign(node.Name, value)
 
 		return nil
 	}
 
-	var operator token.TokenType
-
-	switch node.Token.Type {
-	case token.PLUS_ASSIGN:
-		operator = token.PLUS
-	case token.MINUS_ASSIGN:
-		operator = token.MINUS
-	case token.MULTIPLY_ASSIGN:
-		operator = token.ASTERISK
-	case token.DIVIDE_ASSIGN:
-		operator = token.SLASH
-	case token.MODULO_ASSIGN:
-		operator = token.PERCENT
-	case token.BITWISE_OR_ASSIGN:
-		operator = token.BITWISE_OR
-	case token.BITWISE_AND_ASSIGN:
-		operator = token.BITWISE_AND
-	case token.BITWISE_XOR_ASSIGN:
-		operator = token.BITWISE_XOR
-	case token.LEFT_SHIFT_ASSIGN:
-		operator = token.LEFT_SHIFT
-	case token.RIGHT_SHIFT_ASSIGN:
-		operator = token.RIGHT_SHIFT
-	default:
+	operator, ok := token.AssignmentInfixOperator(node.Token.Type)
+
+	if !ok {
 		return object.NewError(node, "unknown operator: %s", node.Token.Literal)
 	}
 
 	result := e.evalInfixExpression(&ast.InfixExpression{
+		Token:    node.Token,
 		Left:     node.Name,
 		Operator: string(operator),
 		Right:    node.Value,
@@ -681,72 +1482,139 @@ func (e *Evaluator) evalReassignmentExpression(node *ast.ReassignmentExpression,
 		return result
 	}
 
-	environment.Set(node.Name.Value, result)
+	env.Assign(node.Name, result)
 
 	return nil
 }
 
-func (e *Evaluator) evalIncrementDecrementExpression(node ast.Node, env *object.Environment) object.Object {
-	identifier, _, defined := env.Get(node.(*ast.IncrementDecrementExpression).Identifier.Value)
+/*
+Evaluate ++ or -- on a variable.
+
+Numbers are immutable, so the variable is bound to a new number instead of changing the number it holds.
+Other variables, array elements or closures that hold the old number are not affected.
+*/
+func (e *Evaluator) evalIncrementDecrementExpression(node *ast.IncrementDecrementExpression, env *object.Environment) object.Object {
+	current, defined := env.Lookup(node.Identifier)
 
 	if !defined {
-		return object.NewError(node, "variable %s has not been initialized.", node.(*ast.IncrementDecrementExpression).Identifier.Value)
+		return object.NewError(node, "variable %s has not been initialized.", node.Identifier.Value)
 	}
 
-	switch identifier := identifier.(type) {
+	updated := incrementDecrement(node, node.Token, current)
+
+	if isError(updated) {
+		return updated
+	}
+
+	env.Assign(node.Identifier, updated)
+
+	if node.Before {
+		return current
+	}
+
+	return updated
+}
+
+/*
+Get the number after applying the ++ or -- operator of token to it, like += 1 or -= 1 would.
+
+Returns an error when the number is not a number or the result does not fit in an integer.
+*/
+func incrementDecrement(node ast.Node, operator token.Token, current object.Object) object.Object {
+	switch current := current.(type) {
 	case *object.Integer:
-		valueBefore := identifier.Value
+		if operator.Type == token.DECREMENT {
+			result, ok := subInt64(current.Value, 1)
 
-		switch node.(*ast.IncrementDecrementExpression).Token.Type {
-		case token.INCREMENT:
-			identifier.Value++
-		case token.DECREMENT:
-			identifier.Value--
+			if !ok {
+				return object.NewError(node, "integer overflow: %d - 1", current.Value)
+			}
+
+			return object.NewInteger(node, result)
 		}
 
-		if node.(*ast.IncrementDecrementExpression).Before {
-			return object.NewInteger(node, valueBefore)
+		result, ok := addInt64(current.Value, 1)
+
+		if !ok {
+			return object.NewError(node, "integer overflow: %d + 1", current.Value)
 		}
 
-		return object.NewInteger(node, identifier.Value)
+		return object.NewInteger(node, result)
 	case *object.Float:
-		valueBefore := identifier.Value
-
-		switch node.(*ast.IncrementDecrementExpression).Token.Type {
-		case token.INCREMENT:
-			identifier.Value++
-		case token.DECREMENT:
-			identifier.Value--
+		if operator.Type == token.DECREMENT {
+			return object.NewFloat(node, current.Value-1)
 		}
 
-		if node.(*ast.IncrementDecrementExpression).Before {
-			return object.NewFloat(node, valueBefore)
+		return object.NewFloat(node, current.Value+1)
+	}
+
+	return object.NewError(node, "unknown operator: %s", operator.Literal)
+}
+
+func (e *Evaluator) evalChainingIdentifierExpression(left ast.Node, right *ast.Identifier, env *object.Environment) object.Object {
+	leftValue := e.Eval(left, env)
+
+	if isError(leftValue) {
+		return leftValue
+	}
+
+	return e.chainingProperty(right, leftValue)
+}
+
+/*
+Get the property of a chaining expression like left.name on the already evaluated left-hand side
+*/
+func (e *Evaluator) chainingProperty(right *ast.Identifier, leftValue object.Object) object.Object {
+	switch leftValue := leftValue.(type) {
+	case *object.Module:
+		return e.moduleMember(leftValue, right)
+	case *object.Instance:
+		return e.instanceMember(leftValue, right)
+	case *object.Hash:
+		value, ok := hashProperty(right, leftValue, right.Value)
+
+		if !ok {
+			return object.NULL
 		}
 
-		return object.NewFloat(node, identifier.Value)
-	default:
-		return object.NewError(node, "unknown operator: %s", node.(*ast.IncrementDecrementExpression).Token.Literal)
+		return value
 	}
+
+	return object.NewError(right, "chaining operator not supported: %s.%s", object.TypeName(leftValue), right.Value)
+}
+
+/*
+Get the value of the string key name from an object, used for dot property access like obj.name
+*/
+func hashProperty(node ast.Node, hash *object.Hash, name string) (object.Object, bool) {
+	pair, ok := hash.Pairs[object.NewString(node, name).HashKey()]
+
+	if !ok {
+		return nil, false
+	}
+
+	return pair.Value, true
 }
 
 func (e *Evaluator) evalChainingExpression(left ast.Node, right ast.Node, env *object.Environment) object.Object {
 	switch right := right.(type) {
 	case *ast.CallExpression:
 		return e.evalChainingCallExpression(left, right, env)
+	case *ast.Identifier:
+		return e.evalChainingIdentifierExpression(left, right, env)
 	case *ast.ExpressionStatement:
 		switch rightExpression := right.Expression.(type) {
 		case *ast.CallExpression:
 			return e.evalChainingCallExpression(left, rightExpression, env)
 
 		case *ast.Identifier:
-			// TODO: implement chaining ident operator
-			return object.NewError(right, "chaining ident operator not supported: %s.%s", left.TokenLiteral(), rightExpression.Value)
+			return e.evalChainingIdentifierExpression(left, rightExpression, env)
 		}
 	}
 
 	leftValue := e.Eval(left, env)
 
-	return object.NewError(right, "chaining operator not supported: %s.%s", leftValue.Type(), right.String())
+	return object.NewError(right, "chaining operator not supported: %s.%s", object.TypeName(leftValue), right.String())
 }
 
 func (e *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
@@ -762,6 +1630,10 @@ func (e *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
 		return e.evalBlockStatement(node, env)
 
 	case *ast.ReturnStatement:
+		if node.TailCall {
+			return e.evalTailCall(node, node.ReturnValue, env)
+		}
+
 		value := e.Eval(node.ReturnValue, env)
 
 		if isError(value) {
@@ -771,10 +1643,10 @@ func (e *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
 		return object.NewReturnValue(node, value)
 
 	case *ast.VariableStatement:
-		_, defined := env.GetFromCurrent(node.Name.Value)
-
-		if defined {
-			return object.NewError(node, "identifier already defined: %s", node.Name.Value)
+		for _, name := range node.Names() {
+			if env.IsDefined(name) {
+				return object.NewError(node, "identifier already defined: %s", name.Value)
+			}
 		}
 
 		value := e.Eval(node.Value, env)
@@ -783,21 +1655,30 @@ func (e *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
 			return value
 		}
 
-		env.Set(node.Name.Value, value)
+		if node.Pattern != nil {
+			if err := e.destructure(node.Pattern, value, env); err != nil {
+				return err
+			}
 
-	case *ast.FunctionStatement:
-		_, defined := env.GetFromCurrent(node.Name.Value)
+			return nil
+		}
 
-		if defined {
-			return object.NewError(node, "identifier already defined: %s", node.Name.Value)
+		// Name functions defined like `let add = func(a, b) { ... }` after their variable
+		if _, ok := node.Value.(*ast.FunctionLiteral); ok {
+			value.(*object.Function).Name = node.Name.Value
 		}
 
-		args := node.Arguments
-		body := node.Body
+		env.Define(node.Name, value)
 
-		function := object.NewFunction(node, args, body, env)
+	case *ast.FunctionStatement:
+		if env.IsDefined(node.Name) {
+			return object.NewError(node, "identifier already defined: %s", node.Name.Value)
+		}
+
+		env.Define(node.Name, e.functionFromStatement(node, node.Name.Value, env))
 
-		env.Set(node.Name.Value, function)
+	case *ast.StructStatement:
+		return e.evalStructStatement(node, env)
 
 	case *ast.WhileStatement:
 		return e.evalWhileStatement(node, env)
@@ -805,6 +1686,21 @@ func (e *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.ForStatement:
 		return e.evalForStatement(node, env)
 
+	case *ast.ForInStatement:
+		return e.evalForInStatement(node, env)
+
+	case *ast.TryStatement:
+		return e.evalTryStatement(node, env)
+
+	case *ast.ThrowStatement:
+		return e.evalThrowStatement(node, env)
+
+	case *ast.ImportStatement:
+		return e.evalImportStatement(node, env)
+
+	case *ast.ExportStatement:
+		return e.Eval(node.Statement, env)
+
 	// Expressions
 	case *ast.IntegerLiteral:
 		return object.NewInteger(node, node.Value)
@@ -849,6 +1745,10 @@ func (e *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
 			return left
 		}
 
+		if node.Operator == token.AND || node.Operator == token.OR {
+			return e.evalLogicalExpression(node, left, env)
+		}
+
 		right := e.Eval(node.Right, env)
 
 		if isError(right) {
@@ -873,7 +1773,14 @@ func (e *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
 		args := node.Arguments
 		body := node.Body
 
-		return object.NewFunction(node, args, body, env)
+		function := object.NewFunction(node, args, body, env)
+		function.File = e.file
+		function.Defaults = node.Defaults
+		function.Rest = node.Rest
+		function.Patterns = node.Patterns
+		function.Slots = node.Slots
+
+		return function
 
 	case *ast.CallExpression:
 		function := e.Eval(node.Function, env)
@@ -903,7 +1810,7 @@ func (e *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
 			return index
 		}
 
-		return e.evalIndexExpression(left, index)
+		return e.evalIndexExpression(node, left, index)
 
 	case *ast.ReassignmentExpression:
 		return e.evalReassignmentExpression(node, env)
@@ -911,6 +1818,21 @@ func (e *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.IncrementDecrementExpression:
 		return e.evalIncrementDecrementExpression(node, env)
 
+	case *ast.TemplateLiteral:
+		return e.evalTemplateLiteral(node, env)
+
+	case *ast.MatchExpression:
+		return e.evalMatchExpression(node, env)
+
+	case *ast.TernaryExpression:
+		return e.evalTernaryExpression(node, env)
+
+	case *ast.MemberReassignmentExpression:
+		return e.evalMemberReassignmentExpression(node, env)
+
+	case *ast.MemberIncrementDecrementExpression:
+		return e.evalMemberIncrementDecrementExpression(node, env)
+
 	case *ast.ChainingExpression:
 		return e.evalChainingExpression(node.Left, node.Right, env)
 	}
@@ -1,22 +1,24 @@
 package evaluator
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/iskandervdh/vorn/ast"
 	"github.com/iskandervdh/vorn/lexer"
 	"github.com/iskandervdh/vorn/object"
 	"github.com/iskandervdh/vorn/parser"
+	"github.com/iskandervdh/vorn/token"
 )
 
-func testEval(input string) object.Object {
+func testEval(engine string, input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l, false)
 	program := p.ParseProgram()
 	env := object.NewEnvironment()
 
-	e := New()
-
-	return e.Eval(program, env)
+	return run(engine, New(), program, env)
 }
 
 func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
@@ -131,499 +133,1270 @@ func testErrorObject(t *testing.T, obj object.Object, expected string) bool {
 }
 
 func TestEvalIntegerExpression(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected int64
-	}{
-		{"5", 5},
-		{"10", 10},
-		{"-5", -5},
-		{"-10", -10},
-		{"5 + 5 + 5 + 5 - 10", 10},
-		{"2 * 2 * 2 * 2 * 2", 32},
-		{"-50 + 100 + -50", 0},
-		{"5 * 2 + 10", 20},
-		{"5 + 2 * 10", 25},
-		{"20 + 2 * -10", 0},
-		// {"50 / 2 * 2 + 10", 60},
-		{"2 * (5 + 10)", 30},
-		{"3 * 3 * 3 + 10", 37},
-		{"3 * (3 * 3) + 10", 37},
-		// {"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected int64
+		}{
+			{"5", 5},
+			{"10", 10},
+			{"-5", -5},
+			{"-10", -10},
+			{"5 + 5 + 5 + 5 - 10", 10},
+			{"2 * 2 * 2 * 2 * 2", 32},
+			{"-50 + 100 + -50", 0},
+			{"5 * 2 + 10", 20},
+			{"5 + 2 * 10", 25},
+			{"20 + 2 * -10", 0},
+			// {"50 / 2 * 2 + 10", 60},
+			{"2 * (5 + 10)", 30},
+			{"3 * 3 * 3 + 10", 37},
+			{"3 * (3 * 3) + 10", 37},
+			// {"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+			{"0xFF", 255},
+			{"0b1010", 10},
+			{"0o755", 493},
+			{"1_000_000", 1000000},
+			{"0xFF & 0b1111", 15},
+			{"-0x10", -16},
+		}
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		testIntegerObject(t, evaluated, test.expected)
-	}
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			testIntegerObject(t, evaluated, test.expected)
+		}
+	})
 }
 
 func TestEvalFloatExpression(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected float64
-	}{
-		{"5.0", 5.0},
-		{"10.0", 10.0},
-		{"-5.0", -5.0},
-		{"-10.0", -10.0},
-		{"5.5 + 5 + 5 + 5 - 10", 10.5},
-		{"2.5 * 2 * 2 * 2 * 2.225", 44.5},
-		{"-50.0 + 100 + -50", 0.0},
-		{"5.0 * 2 + 10", 20.0},
-		{"5 + 2 * 10.0", 25.0},
-		{"20 + 2 * -10.0", 0.0},
-		{"50 / 2 * 2 + 10.0", 60.0},
-		{"2 * (5 + 10.2)", 30.4},
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected float64
+		}{
+			{"5.0", 5.0},
+			{"10.0", 10.0},
+			{"-5.0", -5.0},
+			{"-10.0", -10.0},
+			{"5.5 + 5 + 5 + 5 - 10", 10.5},
+			{"2.5 * 2 * 2 * 2 * 2.225", 44.5},
+			{"-50.0 + 100 + -50", 0.0},
+			{"5.0 * 2 + 10", 20.0},
+			{"5 + 2 * 10.0", 25.0},
+			{"20 + 2 * -10.0", 0.0},
+			{"50 / 2 * 2 + 10.0", 60.0},
+			{"2 * (5 + 10.2)", 30.4},
+			{"1e3", 1000.0},
+			{"2.5E-1", 0.25},
+			{"1_000.5", 1000.5},
+		}
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		testFloatObject(t, evaluated, test.expected)
-	}
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			testFloatObject(t, evaluated, test.expected)
+		}
+	})
 }
 
 func TestEvalBooleanExpression(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected bool
-	}{
-		{"true", true},
-		{"false", false},
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected bool
+		}{
+			{"true", true},
+			{"false", false},
+		}
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		testBooleanObject(t, evaluated, test.expected)
-	}
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			testBooleanObject(t, evaluated, test.expected)
+		}
+	})
 }
 
 func TestBooleanOperators(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected bool
-	}{
-		{"true", true},
-		{"false", false},
-		{"1 < 2", true},
-		{"1 > 2", false},
-		{"1 < 1", false},
-		{"1 > 1", false},
-		{"1 == 1", true},
-		{"1 != 1", false},
-		{"1 == 2", false},
-		{"1 != 2", true},
-		{"true == true", true},
-		{"false == false", true},
-		{"true == false", false},
-		{"true != false", true},
-		{"false != true", true},
-		{"(1 < 2) == true", true},
-		{"(1 < 2) == false", false},
-		{"(1 > 2) == true", false},
-		{"(1 > 2) == false", true},
-	}
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		testBooleanObject(t, evaluated, test.expected)
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected bool
+		}{
+			{"true", true},
+			{"false", false},
+			{"1 < 2", true},
+			{"1 > 2", false},
+			{"1 < 1", false},
+			{"1 > 1", false},
+			{"1 == 1", true},
+			{"1 != 1", false},
+			{"1 == 2", false},
+			{"1 != 2", true},
+			{"true == true", true},
+			{"false == false", true},
+			{"true == false", false},
+			{"true != false", true},
+			{"false != true", true},
+			{"(1 < 2) == true", true},
+			{"(1 < 2) == false", false},
+			{"(1 > 2) == true", false},
+			{"(1 > 2) == false", true},
+		}
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			testBooleanObject(t, evaluated, test.expected)
+		}
+	})
 }
 
 func TestIfElseExpressions(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected interface{}
-	}{
-		{"if (true) { 10 }", nil},
-		{"if (false) { 10 }", nil},
-		{"if (1) { 10 }", nil},
-		{"if (1 < 2) { 10 }", nil},
-		{"if (1 > 2) { 10 }", nil},
-		{"if (1 > 2) { 10 } else { 20 }", nil},
-		{"if (1 < 2) { 10 } else { 20 }", nil},
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"if (true) { 10 }", nil},
+			{"if (false) { 10 }", nil},
+			{"if (1) { 10 }", nil},
+			{"if (1 < 2) { 10 }", nil},
+			{"if (1 > 2) { 10 }", nil},
+			{"if (1 > 2) { 10 } else { 20 }", nil},
+			{"if (1 < 2) { 10 } else { 20 }", nil},
+		}
+
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			integer, ok := test.expected.(int)
+
+			if ok {
+				testIntegerObject(t, evaluated, int64(integer))
+			} else {
+				testNullObject(t, evaluated)
+			}
+		}
+	})
+}
+
+func TestTernaryExpressions(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"true ? 1 : 2", 1},
+			{"false ? 1 : 2", 2},
+			{"null ? 1 : 2", 2},
+			{"1 < 2 ? 10 : 20", 10},
+			{"1 > 2 ? 10 : 20", 20},
+			{"false ? 1 : true ? 2 : 3", 2},
+			{"true ? false ? 1 : 2 : 3", 2},
+			{"(true ? 1 : 2) + 10", 11},
+			{`let h = {"a": 1 > 2 ? 1 : 2}; h["a"]`, 2},
+			{"let x = 0; let a = [0]; false ? a[0] = 1 : 5; a[0]", 0},
+			{"true ? 1 : 1 + true", 1},
+			{"false ? 1 + true : 2", 2},
+			{"func f(n) { return n <= 1 ? 1 : n * f(n - 1); }; f(5)", 120},
+			{"false ? 1 : 2 + true", "[1:16] type mismatch: INTEGER + BOOLEAN"},
+			{"1 + true ? 1 : 2", "[1:4] type mismatch: INTEGER + BOOLEAN"},
+		}
+
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			integer, ok := test.expected.(int)
+
+			if ok {
+				testIntegerObject(t, evaluated, int64(integer))
+				continue
+			}
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		integer, ok := test.expected.(int)
+			err, ok := test.expected.(string)
+
+			if ok {
+				testErrorObject(t, evaluated, err)
+				continue
+			}
 
-		if ok {
-			testIntegerObject(t, evaluated, int64(integer))
-		} else {
 			testNullObject(t, evaluated)
 		}
-	}
+	})
 }
 
 func TestWhileExpressions(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected interface{}
-	}{
-		{"let i = 0; while (i < 10) { i = i + 1; }; i;", 10},
-		{"let i = 0; while (i < 10) { i = i + 1; if (i == 4) { break; } }; i;", 4},
-		{"let x = 0; let i = 0; while (i < 4) { i = i + 1; if (i != 3) { continue; } x = i; }; x;", 3},
-		{`while (1 + "") { 1 }`, "[1:11] type mismatch: INTEGER + STRING"},
-		{`while (1) { 1 + "" }`, "[1:16] type mismatch: INTEGER + STRING"},
-		{`func(x) { while (x) { return x; } }(1)`, 1},
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"let i = 0; while (i < 10) { i = i + 1; }; i;", 10},
+			{"let i = 0; while (i < 10) { i = i + 1; if (i == 4) { break; } }; i;", 4},
+			{"let x = 0; let i = 0; while (i < 4) { i = i + 1; if (i != 3) { continue; } x = i; }; x;", 3},
+			{`while (1 + "") { 1 }`, "[1:11] type mismatch: INTEGER + STRING"},
+			{`while (1) { 1 + "" }`, "[1:16] type mismatch: INTEGER + STRING"},
+			{`func(x) { while (x) { return x; } }(1)`, 1},
+		}
+
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			integer, ok := test.expected.(int)
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		integer, ok := test.expected.(int)
+			if ok {
+				testIntegerObject(t, evaluated, int64(integer))
+				continue
+			}
 
-		if ok {
-			testIntegerObject(t, evaluated, int64(integer))
-			continue
+			err, ok := test.expected.(string)
+
+			if ok {
+				testErrorObject(t, evaluated, err)
+				continue
+			}
+
+			testNullObject(t, evaluated)
+		}
+	})
+}
+
+func TestFor(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"let x = 0; for (let i = 0; i < 10; i = i + 1) { x = x + 1; }; x;", 10},
+			{"let x = 0; for (let i = 0; i < 10; i = i + 1) { if (i == 4) { x = i; break; } }; x;", 4},
+			{"let x = 0; for (let i = 0; i < 4; i = i + 1) { if (i != 3) { continue; } x = i; }; x;", 3},
+			{"let i = 0; for (; i < 10; i = i + 1) { }; i;", 10},
+			{"let i = 0; for (; i < 10;) { i = i + 1; }; i;", 10},
+			{`for (let i = 0; i < 1 + ""; i = i + 1) { 1 }`, "[1:24] type mismatch: INTEGER + STRING"},
+			{`func(x) { for (let i = 0; i < x; i = i + 1) { return x; } }(1)`, 1},
 		}
 
-		err, ok := test.expected.(string)
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			integer, ok := test.expected.(int)
+
+			if ok {
+				testIntegerObject(t, evaluated, int64(integer))
+				continue
+			}
+
+			err, ok := test.expected.(string)
+
+			if ok {
+				testErrorObject(t, evaluated, err)
+				continue
+			}
 
-		if ok {
-			testErrorObject(t, evaluated, err)
-			continue
+			testNullObject(t, evaluated)
 		}
+	})
+}
 
-		testNullObject(t, evaluated)
-	}
+func TestForIn(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"let x = 0; for (n in [1, 2, 3]) { x += n; } x;", 6},
+			{"let x = 0; for (i, n in [5, 6, 7]) { x += i; } x;", 3},
+			{"let x = 0; for (n in []) { x += 1; } x;", 0},
+			{`let x = ""; for (c in "héllo") { x = c + x; } x == "olléh";`, true},
+			{`let x = 0; for (i, c in "héllo") { x = i; } x;`, 4},
+			{`let x = 0; for (k in {"a": 1, "b": 2}) { x += len(k); } x;`, 2},
+			{`let x = 0; for (k, v in {"a": 1, "b": 2}) { x += v; } x;`, 3},
+			{"let x = 0; for (i in range(5)) { x += i; } x;", 10},
+			{"let x = 0; for (i in range(5, 2)) { x += i; } x;", 12},
+			{"let x = 0; for (i, n in range(10, 13)) { x += i; } x;", 3},
+			{"let x = 0; for (i in range(1000000000000)) { if (i == 3) { break; } x += i; } x;", 3},
+			{"let x = 0; for (n in [1, 2, 3, 4]) { if (n % 2 == 0) { continue; } x += n; } x;", 4},
+			{"func f() { for (n in [1, 2, 3]) { if (n == 2) { return n * 10; } } } f();", 20},
+			{"let fs = []; for (n in [1, 2, 3]) { fs = fs.append(func() { return n; }); } fs[0]() + fs[2]();", 4},
+			{"let range = func(n) { return [n]; }; let x = 0; for (i in range(5)) { x += i; } x;", 5},
+			{"let calls = 0; func pick() { calls++; return func() { return [1, 2]; }; } for (v in pick()()) { } calls;", 1},
+			{"let calls = 0; func pick() { calls++; return range; } let x = 0; for (i in pick()(4)) { x += i; } calls * 10 + x;", 16},
+			{"for (n in 5) { }", "[1:12] can not iterate over INTEGER, expected ARRAY, STRING or HASH"},
+			{"for (n in range(-1)) { }", "[1:17] argument to `range` must be non-negative, got -1"},
+			{"for (n in x) { }", "[1:12] identifier not found: x"},
+			{"for (n in range(y)) { }", "[1:18] identifier not found: y"},
+			{"for (n in [1]) { n + true; }", "[1:21] type mismatch: INTEGER + BOOLEAN"},
+			{"for (n in [1]) { }", nil},
+		}
+
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+
+			switch expected := test.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case bool:
+				testBooleanObject(t, evaluated, expected)
+			case string:
+				testErrorObject(t, evaluated, expected)
+			default:
+				testNullObject(t, evaluated)
+			}
+		}
+	})
 }
 
-func TestFor(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected interface{}
-	}{
-		{"let x = 0; for (let i = 0; i < 10; i = i + 1) { x = x + 1; }; x;", 10},
-		{"let x = 0; for (let i = 0; i < 10; i = i + 1) { if (i == 4) { x = i; break; } }; x;", 4},
-		{"let x = 0; for (let i = 0; i < 4; i = i + 1) { if (i != 3) { continue; } x = i; }; x;", 3},
-		{"let i = 0; for (; i < 10; i = i + 1) { }; i;", 10},
-		{"let i = 0; for (; i < 10;) { i = i + 1; }; i;", 10},
-		{`for (let i = 0; i < 1 + ""; i = i + 1) { 1 }`, "[1:24] type mismatch: INTEGER + STRING"},
-		{`func(x) { for (let i = 0; i < x; i = i + 1) { return x; } }(1)`, 1},
+func TestMatchExpressions(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"match 1 { 1 => 10, _ => 20 };", 10},
+			{"match 5 { 1, 2 => 10, 4, 5 => 20, _ => 30 };", 20},
+			{"match 7 { 1 => 10, _ => 30 };", 30},
+			{"match -1 { -1 => 10, 1 => 20 };", 10},
+			{"match 2.0 { 2 => 10, _ => 20 };", 10},
+			{"match 2 { 2.0 => 10, _ => 20 };", 10},
+			{"match 2.5 { 2.5 => 10, _ => 20 };", 10},
+			{`match "b" { "a" => 1, "b" => 2 };`, 2},
+			{"match null { 0 => 1, null => 2 };", 2},
+			{"match false { true => 1, false => 2 };", 2},
+			{"match 3 { 1 => 10 };", nil},
+			{"match 42 { n => n + 1 };", 43},
+			{"match [1, 2] { [x] => x, [x, y] => x + y, _ => 0 };", 3},
+			{"match [1, 2, 3] { [x, y] => 0, [x, ...rest] => rest.length() };", 2},
+			{"match [1, 2] { [1, x] => x * 10, _ => 0 };", 20},
+			{"match [3, 2] { [1, x] => x * 10, _ => 0 };", 0},
+			{"match 5 { [x] => x, _ => 0 };", 0},
+			{`match {"kind": "circle", "r": 3} { {kind: "square", size} => size, {kind: "circle", r} => r * r };`, 9},
+			{`match {"kind": "square"} { {kind: "circle", r} => r, {kind} => len(kind) };`, 6},
+			{`match {"a": 1, "b": 2} { {a, ...rest} => rest["b"] };`, 2},
+			{`match 5 { {a} => a, _ => 0 };`, 0},
+			{"match 150 { n if n > 100 => 1, n if n > 10 => 2, _ => 3 };", 1},
+			{"match 50 { n if n > 100 => 1, n if n > 10 => 2, _ => 3 };", 2},
+			{"match [4, 3] { [x, y] if x < y => y, [x, y] => x };", 4},
+			{"let x = 1; match 2 { x => x }; x;", 1},
+			{"let v = match 3 { 1 => 10, _ => 20 }; v + 1;", 21},
+			{"match y { _ => 1 };", "[1:8] identifier not found: y"},
+			{"match 1 { n if n + true => 1 };", "[1:19] type mismatch: INTEGER + BOOLEAN"},
+			{"match 1 { n => n + true };", "[1:19] type mismatch: INTEGER + BOOLEAN"},
+		}
+
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+
+			switch expected := test.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case string:
+				testErrorObject(t, evaluated, expected)
+			default:
+				testNullObject(t, evaluated)
+			}
+		}
+	})
+}
+
+func TestStructs(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		point := `struct Point {
+	x, y = 0;
+
+	func sum() {
+		return self.x + self.y;
 	}
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		integer, ok := test.expected.(int)
+	func move(dx, dy) {
+		self.x += dx;
+		self.y = self.y + dy;
+		return self;
+	}
+}
+`
+
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{point + "let p = Point(3, 4); p.x;", 3},
+			{point + "let p = Point(3, 4); p.y;", 4},
+			{point + "let p = Point(3); p.y;", 0},
+			{point + "let p = Point(3, 4); p.sum();", 7},
+			{point + "let p = Point(1, 2); p.move(1, 1); p.sum();", 5},
+			{point + "let p = Point(1, 2); p.move(1, 1).move(1, 1).x;", 3},
+			{point + "let p = Point(1, 2); let q = p; q.x = 10; p.x;", 10},
+			{point + "let p = Point(1, 2); let sum = p.sum; p.x = 5; sum();", 7},
+			{point + "let p = Point(1, 2); p[\"y\"] = 5; p.y;", 5},
+			{point + "let p = Point(1, 2); p.y *= 4; p.y;", 8},
+			{point + "let p = Point(1, 2); p.unknown;", "[14:25] Point has no field unknown"},
+			{point + "let p = Point(1, 2); p.unknown = 1;", "[14:33] Point has no field unknown"},
+			{point + "let p = Point(1, 2); p.unknown();", "[14:25] Point has no method unknown"},
+			{point + "let p = Point(1, 2); p.x();", "[14:25] not a function: INTEGER"},
+			{point + "let p = Point(1, 2); p[0] = 1;", "[14:28] field name must be STRING, got INTEGER"},
+			{point + "Point();", "[14:7] wrong number of arguments for Point. got 0, want 1 to 2"},
+			{point + "Point(1, 2, 3);", "[14:7] wrong number of arguments for Point. got 3, want 1 to 2"},
+			{point + "Point(1, 2).move(1);", "[14:18] wrong number of arguments for Point.move. got 1, want 2"},
+			{point + "let Point = 1;", "[14:2] identifier already defined: Point"},
+			{"struct Point { x; } struct Point { y; }", "[1:22] identifier already defined: Point"},
+			{"struct Point { x; } Point = 5;", "[1:28] can not reassign struct Point."},
+			{"struct Box { value; func get() { return self.value; } } let b = Box(func() { return 42; }); b.value();", 42},
+			{"struct Counter { count; func init(start = 10) { self.count = start * 2; } } Counter().count;", 20},
+			{"struct Counter { count; func init(start = 10) { self.count = start * 2; } } Counter(3).count;", 6},
+			{"struct Counter { count = 1; func init() { } } Counter().count;", 1},
+			{"struct Counter { count; func init() { } } Counter().count;", nil},
+			{"struct Counter { count; func init() { } } Counter(1);", "[1:51] wrong number of arguments for Counter.init. got 1, want 0"},
+			{"struct Pair { a, b = a; } Pair(1).b;", "[1:23] identifier not found: a"},
+			{"struct INTEGER { x; } INTEGER(1) + 1;", "[1:35] type mismatch: INTEGER + INTEGER"},
+			{`struct STRING { x; } STRING(1) + "a";`, "[1:33] type mismatch: STRING + STRING"},
+			{"struct STRING { x; } STRING(1).length();", "[1:33] STRING has no method length"},
+			{"struct ARRAY { x; } ARRAY(1)[0];", "[1:30] field name must be STRING, got INTEGER"},
+			{"struct A { x; } struct B { x; } A(1) < B(1);", "[1:39] unknown operator: A < B"},
+			{"struct A { x; } -A(1);", "[1:20] unknown operator: -A"},
+		}
+
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+
+			switch expected := test.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case string:
+				testErrorObject(t, evaluated, expected)
+			default:
+				testNullObject(t, evaluated)
+			}
+		}
+	})
+}
 
-		if ok {
-			testIntegerObject(t, evaluated, int64(integer))
-			continue
+func TestStructObjects(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `struct Point { x, y = 0; }
+let p = Point(1, [2, 3]);
+[type(p), string(p), type(Point), string(Point)];
+`
+		builtinName := `struct INTEGER { x; }
+let i = INTEGER(1);
+[type(i), string(i), string(i == 1), string(i == INTEGER(1))];
+`
+		tests := []struct {
+			input    string
+			expected []string
+		}{
+			{input, []string{"Point", "Point{x: 1, y: [2, 3]}", "STRUCT", "struct Point"}},
+			{builtinName, []string{"INTEGER", "INTEGER{x: 1}", "false", "false"}},
 		}
 
-		err, ok := test.expected.(string)
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			array, ok := evaluated.(*object.Array)
 
-		if ok {
-			testErrorObject(t, evaluated, err)
-			continue
+			if !ok {
+				t.Fatalf("object is not Array. got %T (%+v)", evaluated, evaluated)
+			}
+
+			for i, expected := range test.expected {
+				testStringObject(t, array.Elements[i], expected)
+			}
 		}
+	})
+}
 
-		testNullObject(t, evaluated)
-	}
+func TestMetamethods(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		vec := `struct Vec {
+	x, y;
+
+	func __add(other) { return Vec(self.x + other.x, self.y + other.y); }
+	func __sub(other) { return Vec(self.x - other.x, self.y - other.y); }
+	func __mul(k) { return Vec(self.x * k, self.y * k); }
+	func __div(k) { return Vec(self.x / k, self.y / k); }
+	func __mod(k) { return Vec(self.x % k, self.y % k); }
+	func __eq(other) { return type(other) == "Vec" && self.x == other.x && self.y == other.y; }
+	func __lt(other) { return self.x < other.x; }
+	func __index(i) { return i == 0 ? self.x : self.y; }
+	func __str() { return "<" + string(self.x) + ", " + string(self.y) + ">"; }
+}
+`
+
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{vec + "(Vec(1, 2) + Vec(3, 4)).y;", 6},
+			{vec + "(Vec(1, 2) - Vec(3, 5)).y;", -3},
+			{vec + "(Vec(1, 2) * 3).x;", 3},
+			{vec + "(Vec(4, 8) / 2).y == 4;", true},
+			{vec + "(Vec(5, 8) % 3).x;", 2},
+			{vec + "let v = Vec(1, 1); v += Vec(1, 2); v.y;", 3},
+			{vec + "Vec(1, 2) == Vec(1, 2);", true},
+			{vec + "Vec(1, 2) == Vec(2, 2);", false},
+			{vec + "Vec(1, 2) != Vec(2, 2);", true},
+			{vec + "Vec(1, 2) == 1;", false},
+			{vec + "1 == Vec(1, 2);", false},
+			{vec + "Vec(1, 2) < Vec(2, 0);", true},
+			{vec + "Vec(1, 2) > Vec(2, 0);", false},
+			{vec + "Vec(2, 2) <= Vec(2, 0);", true},
+			{vec + "Vec(3, 2) <= Vec(2, 0);", false},
+			{vec + "Vec(2, 2) >= Vec(2, 0);", true},
+			{vec + "Vec(1, 2) >= Vec(2, 0);", false},
+			{vec + "Vec(1, 2)[0];", 1},
+			{vec + "Vec(1, 2)[1];", 2},
+			{vec + "2 * Vec(1, 2);", "[14:4] type mismatch: INTEGER * Vec"},
+			{vec + "Vec(1, 2) & Vec(1, 2);", "[14:12] unknown operator: Vec & Vec"},
+			{vec + "[Vec(1, 2)] == [Vec(1, 2)];", true},
+			{vec + "[Vec(1, 2)] != [Vec(1, 3)];", true},
+			{vec + `{"a": [Vec(1, 2)]} == {"a": [Vec(1, 2)]};`, true},
+			{vec + "[Vec(1, 2)].contains(Vec(1, 2));", true},
+			{vec + "[Vec(1, 2)].contains(Vec(2, 2));", false},
+			{vec + "[1, Vec(3, 4)].indexOf(Vec(3, 4));", 1},
+			{"struct Point { x, y; } [Point(1, 2)] == [Point(1, 2)];", false},
+			{"struct Point { x, y; } let p = Point(1, 2); [p].contains(p);", true},
+			{"struct A { func __eq(other) { return 1 + true; } } [A()] == [A()];", "[1:41] type mismatch: INTEGER + BOOLEAN"},
+			{"struct A { func __eq(other) { return 1 + true; } } [A()].contains(1);", "[1:41] type mismatch: INTEGER + BOOLEAN"},
+			{"struct Point { x, y; } let p = Point(1, 2); p == p;", true},
+			{"struct Point { x, y; } Point(1, 2) == Point(1, 2);", false},
+			{"struct Point { x, y; } Point(1, 2) < Point(1, 2);", "[1:37] unknown operator: Point < Point"},
+			{`struct Point { x, y; } Point(1, 2)["y"];`, 2},
+			{`struct Point { x, y; } Point(1, 2)["z"];`, "[1:36] Point has no field z"},
+			{"struct A { func __add(other) { return other + true; } } A() + 1;", "[1:46] type mismatch: INTEGER + BOOLEAN"},
+			{"struct A { func __str() { return 1; } } string(A());", "[1:48] __str of A must return STRING, got INTEGER"},
+			{"struct A { func __str() { return 1; } } print(A());", "[1:47] __str of A must return STRING, got INTEGER"},
+			{`struct A { func __str() { return 1; } } "${A()}";`, "[1:46] __str of A must return STRING, got INTEGER"},
+			{"struct A { func __str() { return 1; } } string([1, A()]);", "[1:48] __str of A must return STRING, got INTEGER"},
+		}
+
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+
+			switch expected := test.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case bool:
+				testBooleanObject(t, evaluated, expected)
+			case string:
+				testErrorObject(t, evaluated, expected)
+			default:
+				testNullObject(t, evaluated)
+			}
+		}
+
+		stringTests := []struct {
+			input    string
+			expected string
+		}{
+			{vec + "string(Vec(1, 2));", "<1, 2>"},
+			{vec + `"v = ${Vec(1, 2) + Vec(1, 1)}";`, "v = <2, 3>"},
+			{"struct Point { x, y; } string(Point(1, 2));", "Point{x: 1, y: 2}"},
+			{vec + "string([Vec(1, 2), 3]);", "[<1, 2>, 3]"},
+			{vec + `string({"k": Vec(1, 2)});`, "{k: <1, 2>}"},
+			{vec + `"${[Vec(1, 2)]}";`, "[<1, 2>]"},
+			{vec + "struct Line { from, to; } string(Line(Vec(0, 0), Vec(1, 1)));", "Line{from: <0, 0>, to: <1, 1>}"},
+		}
+
+		for _, test := range stringTests {
+			testStringObject(t, testEval(engine, test.input), test.expected)
+		}
+	})
 }
 
 func TestExclamationOperator(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected bool
-	}{
-		{"!true", false},
-		{"!false", true},
-		{"!5", false},
-		{"!!true", true},
-		{"!!false", false},
-		{"!!5", true},
-		{"!first([])", true},
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected bool
+		}{
+			{"!true", false},
+			{"!false", true},
+			{"!5", false},
+			{"!!true", true},
+			{"!!false", false},
+			{"!!5", true},
+			{"!first([])", true},
+		}
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		testBooleanObject(t, evaluated, test.expected)
-	}
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			testBooleanObject(t, evaluated, test.expected)
+		}
+	})
 }
 
 func TestBitwiseNotOperator(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected int64
-	}{
-		{"~0", -1},
-		{"~1", -2},
-		{"~2", -3},
-		{"~-1", 0},
-		{"~-2", 1},
-		{"~-3", 2},
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected int64
+		}{
+			{"~0", -1},
+			{"~1", -2},
+			{"~2", -3},
+			{"~-1", 0},
+			{"~-2", 1},
+			{"~-3", 2},
+		}
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		testIntegerObject(t, evaluated, test.expected)
-	}
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			testIntegerObject(t, evaluated, test.expected)
+		}
+	})
 }
 
 func TestIntegerReturningInfixExpression(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected int64
-	}{
-		{"1 + 1", 2},
-		{"1 - 1", 0},
-		{"1 * 1", 1},
-		{`1 % 1`, 0.0},
-		{"1 & 1", 1},
-		{"1 & 2", 0},
-		{"1 | 1", 1},
-		{"1 | 2", 3},
-		{"1 ^ 1", 0},
-		{"1 ^ 2", 3},
-		{"1 << 1", 2},
-		{"1 << 2", 4},
-		{"4 >> 1", 2},
-		{"4 >> 2", 1},
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected int64
+		}{
+			{"1 + 1", 2},
+			{"1 - 1", 0},
+			{"1 * 1", 1},
+			{`1 % 1`, 0.0},
+			{"1 & 1", 1},
+			{"1 & 2", 0},
+			{"1 | 1", 1},
+			{"1 | 2", 3},
+			{"1 ^ 1", 0},
+			{"1 ^ 2", 3},
+			{"1 << 1", 2},
+			{"1 << 2", 4},
+			{"4 >> 1", 2},
+			{"4 >> 2", 1},
+		}
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		testIntegerObject(t, evaluated, test.expected)
-	}
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			testIntegerObject(t, evaluated, test.expected)
+		}
+	})
 }
 
 func TestFloatReturningInfixExpression(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected float64
-	}{
-		{"1.0 + 1.0", 2.0},
-		{"1.0 - 1.0", 0.0},
-		{"1.0 * 1.0", 1.0},
-		{"1.0 / 1.0", 1.0},
-		{`1 / 1`, 1.0},
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected float64
+		}{
+			{"1.0 + 1.0", 2.0},
+			{"1.0 - 1.0", 0.0},
+			{"1.0 * 1.0", 1.0},
+			{"1.0 / 1.0", 1.0},
+			{`1 / 1`, 1.0},
+		}
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		testFloatObject(t, evaluated, test.expected)
-	}
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			testFloatObject(t, evaluated, test.expected)
+		}
+	})
 }
 
 func TestBooleanReturningInfixExpression(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected bool
-	}{
-		{"1 == 1", true},
-		{"1 != 1", false},
-		{"1 == 2", false},
-		{"1 != 2", true},
-		{"true == true", true},
-		{"true != true", false},
-		{"true == false", false},
-		{"true != false", true},
-		{"false == false", true},
-		{"false != false", false},
-		{"false == true", false},
-		{"false != true", true},
-		{`1.0 == 1.0`, true},
-		{`1.0 != 1.0`, false},
-		{`1.0 == 2.0`, false},
-		{`1.0 != 2.0`, true},
-		{`1 < 2`, true},
-		{`1 > 2`, false},
-		{`1 <= 2`, true},
-		{`1 >= 2`, false},
-		{`1 <= 1`, true},
-		{`1 >= 1`, true},
-		{`1 < 1`, false},
-		{`1 > 1`, false},
-		{`1.0 < 2.0`, true},
-		{`1.0 > 2.0`, false},
-		{`1.0 <= 2.0`, true},
-		{`1.0 >= 2.0`, false},
-		{`1.0 <= 1.0`, true},
-		{`1.0 >= 1.0`, true},
-		{`1.0 < 1.0`, false},
-		{`1.0 > 1.0`, false},
-		{`"hello" == "hello"`, true},
-		{`"hello" != "hello"`, false},
-		{`"hello" == "world"`, false},
-		{`"hello" != "world"`, true},
-		{`1 || 0`, true},
-		{`1 || 1`, true},
-		{`0 || 1`, true},
-		{`0 || 0`, false},
-		{`1 && 0`, false},
-		{`1 && 1`, true},
-		{`0 && 1`, false},
-		{`0 && 0`, false},
-		{`true || false`, true},
-		{`true && false`, false},
-		{`false || true`, true},
-		{`false && true`, false},
-		{`true || true`, true},
-		{`true && true`, true},
-		{`false || false`, false},
-		{`null || false`, false},
-		{`null && false`, false},
-		{`null || true`, true},
-		{`null && true`, false},
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected bool
+		}{
+			{"1 == 1", true},
+			{"1 != 1", false},
+			{"1 == 2", false},
+			{"1 != 2", true},
+			{"true == true", true},
+			{"true != true", false},
+			{"true == false", false},
+			{"true != false", true},
+			{"false == false", true},
+			{"false != false", false},
+			{"false == true", false},
+			{"false != true", true},
+			{`1.0 == 1.0`, true},
+			{`1.0 != 1.0`, false},
+			{`1.0 == 2.0`, false},
+			{`1.0 != 2.0`, true},
+			{`1 < 2`, true},
+			{`1 > 2`, false},
+			{`1 <= 2`, true},
+			{`1 >= 2`, false},
+			{`1 <= 1`, true},
+			{`1 >= 1`, true},
+			{`1 < 1`, false},
+			{`1 > 1`, false},
+			{`1.0 < 2.0`, true},
+			{`1.0 > 2.0`, false},
+			{`1.0 <= 2.0`, true},
+			{`1.0 >= 2.0`, false},
+			{`1.0 <= 1.0`, true},
+			{`1.0 >= 1.0`, true},
+			{`1.0 < 1.0`, false},
+			{`1.0 > 1.0`, false},
+			{`"hello" == "hello"`, true},
+			{`"hello" != "hello"`, false},
+			{`"hello" == "world"`, false},
+			{`"hello" != "world"`, true},
+			{`true || false`, true},
+			{`true && false`, false},
+			{`false || true`, true},
+			{`false && true`, false},
+			{`true || true`, true},
+			{`true && true`, true},
+			{`false || false`, false},
+			{`null || false`, false},
+			{`null || true`, true},
+			{`1 < 2 && 2 < 3`, true},
+			{`1 > 2 || 2 < 3`, true},
+		}
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		testBooleanObject(t, evaluated, test.expected)
-	}
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			testBooleanObject(t, evaluated, test.expected)
+		}
+	})
+}
+
+func TestDeepEquality(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"[1, 2] == [1, 2]", true},
+			{"[1, 2] != [1, 2]", false},
+			{"[1, 2] == [2, 1]", false},
+			{"[1, 2] == [1, 2, 3]", false},
+			{"[1, [2, 3]] == [1, [2, 3]]", true},
+			{"[1, [2, 3]] == [1, [2, 4]]", false},
+			{"[1, 2.0] == [1.0, 2]", true},
+			{`[1, "a", true, null] == [1, "a", true, null]`, true},
+			{`[1] == ["1"]`, false},
+			{"[] == []", true},
+			{"[] == {}", false},
+			{"[1] == 1", false},
+			{`{"a": 1, "b": [2]} == {"b": [2], "a": 1}`, true},
+			{`{"a": 1} == {"a": 2}`, false},
+			{`{"a": 1} == {"b": 1}`, false},
+			{`{"a": 1} == {"a": 1, "b": 2}`, false},
+			{"let f = func() {}; [f] == [f];", true},
+			{"[func() {}] == [func() {}]", false},
+			{"let a = [1]; a[0] = a; let b = [1]; b[0] = b; a == b;", true},
+			{"let a = [1, 2]; a[0] = a; let b = [1, 3]; b[0] = b; a == b;", false},
+			{"let h = {}; h.self = h; let g = {}; g.self = g; h == g;", true},
+			{"[1, 2].contains(2.0)", true},
+			{`[1, 2].contains("1")`, false},
+			{"[[1, 2], [3]].contains([3])", true},
+			{"[[1, 2], [3]].indexOf([3]) == 1", true},
+			{`[{"a": 1}].indexOf({"a": 1}) == 0`, true},
+			{"same([1], [1])", false},
+			{"let a = [1]; same(a, a);", true},
+			{"let a = [1]; let b = a; same(a, b);", true},
+			{`same({"a": 1}, {"a": 1})`, false},
+			{"same(1, 1)", true},
+			{`same("a", "a")`, true},
+			{"same(null, null)", true},
+			{"same(1)", "[1:6] wrong number of arguments. got 1, want 2"},
+			{`let h = {[1, 2]: "a"}; h[[1, 2]] == "a";`, true},
+			{`let h = {}; h[[1, [2]]] = 1; h[[1, [2]]] += 1; h[[1, [2]]] == 2;`, true},
+			{`let h = {{"a": 1, "b": 2}: 1}; h[{"b": 2, "a": 1}] == 1;`, true},
+			{`let h = {[1, 2]: "a"}; h[[2, 1]] == null;`, true},
+			{`let a = [1]; a[0] = a; let h = {}; h[a];`, "[1:10] unusable as object key: ARRAY"},
+			{`{[len]: 1}`, "[1:2] unusable as object key: ARRAY"},
+			{`let h = {[1]: "a"}; h[[1.0]] == "a";`, true},
+			{`let h = {1.0: "a"}; h[1] == "a";`, true},
+			{`let h = {1.5: "a", 1: "b"}; h[1.5] == "a" && h[1.0] == "b";`, true},
+			{`{1: 1} == {1.0: 1}`, true},
+			{`let h = {[[1], 2]: "a", [1, [2]]: "b"}; h[[[1], 2]] == "a" && h[[1, [2]]] == "b";`, true},
+			{`let h = {{"a": 1, "b": 2}: "x", {"a": 2, "b": 1}: "y"}; h[{"a": 1, "b": 2}] == "x" && h[{"a": 2, "b": 1}] == "y";`, true},
+			{`let k = [1]; let h = {}; h[k] = "a"; k.append(2); h[[1]] == "a" && h[k] == null;`, true},
+			{`let k = [1]; let h = {}; h[k] = "a"; k[0] = 2; string(h) == "{[1]: a}";`, true},
+			{`let h = {}; h[float("nan")]`, "[1:22] unusable as object key: FLOAT"},
+		}
+
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+
+			switch expected := test.expected.(type) {
+			case bool:
+				testBooleanObject(t, evaluated, expected)
+			case string:
+				testErrorObject(t, evaluated, expected)
+			}
+		}
+	})
 }
 
 func TestReturnStatements(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected int64
-	}{
-		{"return 10;", 10},
-		{"return 10; 9;", 10},
-		{"return 2 * 5; 9;", 10},
-		{"9; return 2 * 5; 9;", 10},
-		{
-			`
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected int64
+		}{
+			{"return 10;", 10},
+			{"return 10; 9;", 10},
+			{"return 2 * 5; 9;", 10},
+			{"9; return 2 * 5; 9;", 10},
+			{
+				`
 if (10 > 1) {
 	return 10;
 } else {
 	return 1;
 }`,
-			10,
-		},
-	}
-	for _, tt := range tests {
-		evaluated := testEval(tt.input)
-		testIntegerObject(t, evaluated, tt.expected)
-	}
+				10,
+			},
+		}
+		for _, tt := range tests {
+			evaluated := testEval(engine, tt.input)
+			testIntegerObject(t, evaluated, tt.expected)
+		}
+	})
+}
+
+func TestTailCalls(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected int64
+		}{
+			// Deeper than the maximum call depth, which only works when the calls replace each other
+			{"func count(n, total) { if (n == 0) { return total; } return count(n - 1, total + 1); } count(50000, 0);", 50000},
+			{"func even(n) { if (n == 0) { return 1; } return odd(n - 1); } func odd(n) { if (n == 0) { return 0; } return even(n - 1); } even(30001);", 0},
+			{"func f(n) { while (true) { if (n == 0) { return 7; } return f(n - 1); } } f(20000);", 7},
+			{"func f(n) { for (x in [n]) { if (x == 0) { return 3; } return f(x - 1); } } f(20000);", 3},
+			{"func f(n) { return n == 0 ? 5 : g(n); } func g(n) { return f(n - 1); } f(3);", 5},
+			{"func f(n) { return len([n]); } f(1);", 1},
+			{"struct C { n; func down() { if (self.n == 0) { return 9; } return C(self.n - 1).down(); } } C(3).down();", 9},
+			{"struct C { func down(n) { if (n == 0) { return 9; } return self.down(n - 1); } } C().down(50000);", 9},
+			{"let o = {\"down\": func(n) { return n == 0 ? 6 : o.down(n - 1); }}; o.down(50000);", 6},
+			{"func f(n) { return n == 0 ? 4 : f(n - 1); } f(50000);", 4},
+			{"func f(n) { return n > 0 ? (n % 2 == 0 ? f(n - 1) : f(n - 2)) : 8; } f(50000);", 8},
+			{"func f(n) { return n > 0 ? [n - 1].map(f)[0] : 2; } f(1);", 2},
+		}
+
+		for _, tt := range tests {
+			testIntegerObject(t, testEval(engine, tt.input), tt.expected)
+		}
+	})
+}
+
+func TestMaxCallDepth(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected string
+		}{
+			{"func f(n) { if (n == 0) { return 0; } return 1 + f(n - 1); } f(5);", ""},
+			{"func f(n) { if (n == 0) { return 0; } return 1 + f(n - 1); } f(20);", "[1:52] maximum recursion depth exceeded"},
+			// The callback is replaced by its tail call of f, so the call of the callback exceeds the depth
+			{"func f() { return [1].map(func(x) { return f(); }); } f();", "[1:20] maximum recursion depth exceeded"},
+			{"func f(n) { try { if (n == 0) { return 0; } return f(n - 1); } catch (e) { return -1; } } f(20);", ""},
+		}
+
+		for _, tt := range tests {
+			program := parser.New(lexer.New(tt.input), false).ParseProgram()
+			e := New()
+			e.SetMaxCallDepth(10)
+
+			evaluated := run(engine, e, program, object.NewEnvironment())
+
+			if tt.expected == "" {
+				if isError(evaluated) {
+					t.Errorf("unexpected error for %q: %s", tt.input, evaluated.Inspect())
+				}
+
+				continue
+			}
+
+			testErrorObject(t, evaluated, tt.expected)
+
+			// The depth of the calls that stopped with the error is reset, so the evaluator can run more programs
+			if len(e.callStack) != 0 {
+				t.Errorf("wrong call depth after error for %q. got %d, want 0", tt.input, len(e.callStack))
+			}
+		}
+	})
+}
+
+func TestCallStack(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected []string // The name and position of each call, as name@line:column
+		}{
+			{"1 + true;", []string{}},
+			{"func f() { return 1 + true; }\nf();", []string{"f@2:3"}},
+			{"func f() { return g() + 1; }\nfunc g() { return 1 + true; }\nf();", []string{"f@3:3", "g@1:21"}},
+			// The tail call of g replaces the call of f
+			{"func f() { return g(); }\nfunc g() { return 1 + true; }\nf();", []string{"g@1:21"}},
+			{"let add = func(x) { return x + true; };\n[1].map(add);", []string{"Array.map@2:9", "add@2:2"}},
+			{"[1].map(func(x) { return x + true; });", []string{"Array.map@1:9", "<anonymous>@1:2"}},
+			{"func f(s) { return int(s); }\nf(\"a\");", []string{"f@2:3", "int@1:24"}},
+			{"{\"a\": 1}.keys().map(func(k) { return k + 1; });", []string{"Array.map@1:21", "<anonymous>@1:2"}},
+			{"struct P { x; func get() { return self.x + true; } }\nP(1).get();", []string{"P.get@2:10"}},
+			{"func f() { try { 1 + true; } catch (e) { } return 1 + true; }\nf();", []string{"f@2:3"}},
+			{"func f(a) { return a; }\nf();", []string{}},
+			// Rethrowing a caught error keeps the calls that led to it
+			{"func f() { return 1 + true; }\ntry { f(); } catch (e) { throw e; }", []string{"f@2:9"}},
+			{"func f(n) { if (n == 0) { return 1 + true; } return 1 + f(n - 1); }\nfunc g() { try { f(2); } catch (e) { throw e; } }\ng();", []string{"g@3:3", "f@2:20", "f@1:59", "f@1:59"}},
+		}
+
+		for _, tt := range tests {
+			evaluated := testEval(engine, tt.input)
+			err, ok := evaluated.(*object.Error)
+
+			if !ok {
+				t.Errorf("object is not Error for %q. got %T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+
+			stack := []string{}
+
+			for _, frame := range err.Stack {
+				stack = append(stack, fmt.Sprintf("%s@%d:%d", frame.Name, frame.Node.Line(), frame.Node.Column()))
+			}
+
+			if strings.Join(stack, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("wrong call stack for %q. got %v, want %v", tt.input, stack, tt.expected)
+			}
+		}
+	})
 }
 
 func TestErrorHandling(t *testing.T) {
-	tests := []struct {
-		input           string
-		expectedMessage string
-	}{
-		{
-			"5 + true;",
-			"[1:4] type mismatch: INTEGER + BOOLEAN",
-		},
-		{
-			"5 + true; 5;",
-			"[1:4] type mismatch: INTEGER + BOOLEAN",
-		},
-		{
-			"-true",
-			"[1:1] unknown operator: -BOOLEAN",
-		},
-		{
-			"true + false;",
-			"[1:7] unknown operator: BOOLEAN + BOOLEAN",
-		},
-		{
-			"5; true + false; 5",
-			"[1:10] unknown operator: BOOLEAN + BOOLEAN",
-		},
-		{
-			"if (10 > 1) { true + false; }",
-			"[1:21] unknown operator: BOOLEAN + BOOLEAN",
-		},
-		{
-			`
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input           string
+			expectedMessage string
+		}{
+			{
+				"5 + true;",
+				"[1:4] type mismatch: INTEGER + BOOLEAN",
+			},
+			{
+				"5 + true; 5;",
+				"[1:4] type mismatch: INTEGER + BOOLEAN",
+			},
+			{
+				"-true",
+				"[1:1] unknown operator: -BOOLEAN",
+			},
+			{
+				"true + false;",
+				"[1:7] unknown operator: BOOLEAN + BOOLEAN",
+			},
+			{
+				"5; true + false; 5",
+				"[1:10] unknown operator: BOOLEAN + BOOLEAN",
+			},
+			{
+				"if (10 > 1) { true + false; }",
+				"[1:21] unknown operator: BOOLEAN + BOOLEAN",
+			},
+			{
+				`
 if (10 > 1) {
 	if (10 > 1) {
 		return true + false;
 	}
 	return 1;
 }`,
-			"[4:16] unknown operator: BOOLEAN + BOOLEAN",
-		},
-		{
-			"foobar",
-			"[1:2] identifier not found: foobar",
-		},
-		{
-			`"Hello" - "World"`,
-			"[1:10] unknown operator: STRING - STRING",
-		},
-		{
-			`{"name": "Vorn"}[func(x) { x }];`,
-			"[1:19] unusable as object key: FUNCTION",
+				"[4:16] unknown operator: BOOLEAN + BOOLEAN",
+			},
+			{
+				"foobar",
+				"[1:2] identifier not found: foobar",
+			},
+			{
+				"foobar; let foobar = 1;",
+				"[1:2] identifier used before its declaration: foobar",
+			},
+			{
+				"func f() { return foobar; } f(); let foobar = 1;",
+				"[1:20] identifier not found: foobar",
+			},
+			{
+				"func f() { foobar = 2; } f(); let foobar = 1;",
+				"[1:20] variable foobar has not been initialized.",
+			},
+			{
+				"if (true) { func f() { return foobar; } f(); let foobar = 1; }",
+				"[1:32] identifier not found: foobar",
+			},
+			{
+				`"Hello" - "World"`,
+				"[1:10] unknown operator: STRING - STRING",
+			},
+			{
+				`{"name": "Vorn"}[func(x) { x }];`,
+				"[1:19] unusable as object key: FUNCTION",
+			},
+		}
+
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			errObj, ok := evaluated.(*object.Error)
+
+			if !ok {
+				t.Errorf("no error object returned. got %T(%+v)", evaluated, evaluated)
+				continue
+			}
+
+			if errObj.Message != test.expectedMessage {
+				t.Errorf("wrong error message. expected %q, got %q", test.expectedMessage, errObj.Message)
+			}
+		}
+	})
+}
+
+func TestArithmeticErrors(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input           string
+			expectedMessage string
+		}{
+			{"1 / 0", "[1:4] division by zero"},
+			{"1.5 / 0", "[1:6] division by zero"},
+			{"1 / 0.0", "[1:4] division by zero"},
+			{"10 % 0", "[1:5] modulo by zero"},
+			{"let x = 0;\nlet y = 5 % x;", "[2:12] modulo by zero"},
+			{"let x = 5; x %= 0;", "[1:16] modulo by zero"},
+			{"1 << -1", "[1:5] negative shift amount: -1"},
+			{"1 >> -2", "[1:5] negative shift amount: -2"},
+			{"9223372036854775807 + 1", "[1:22] integer overflow: 9223372036854775807 + 1"},
+			{"-9223372036854775807 - 2", "[1:23] integer overflow: -9223372036854775807 - 2"},
+			{"4611686018427387904 * 2", "[1:22] integer overflow: 4611686018427387904 * 2"},
+			{"let x = 9223372036854775807; x += 1;", "[1:34] integer overflow: 9223372036854775807 + 1"},
+			{"try { 1 % 0; } catch (e) { throw e; }", "[1:10] modulo by zero"},
+			{"let x = 9223372036854775807; x++;", "[1:33] integer overflow: 9223372036854775807 + 1"},
+			{"let x = -9223372036854775807 - 1; --x;", "[1:37] integer overflow: -9223372036854775808 - 1"},
+			{"let h = {\"a\": 9223372036854775807}; h.a++;", "[1:42] integer overflow: 9223372036854775807 + 1"},
+			{"1 << 70", "[1:5] integer overflow: 1 << 70"},
+			{"1 << 63", "[1:5] integer overflow: 1 << 63"},
+			{"3 << 62", "[1:5] integer overflow: 3 << 62"},
+			{"-(-9223372036854775807 - 1)", "[1:2] integer overflow: -(-9223372036854775808)"},
+			{"let x = -9223372036854775807 - 1; -x;", "[1:36] integer overflow: -(-9223372036854775808)"},
+		}
+
+		for _, test := range tests {
+			testErrorObject(t, testEval(engine, test.input), test.expectedMessage)
+		}
+
+		testIntegerObject(t, testEval(engine, "-9223372036854775807 - 1"), -9223372036854775808)
+		testIntegerObject(t, testEval(engine, "-4611686018427387904 * 2"), -9223372036854775808)
+		testIntegerObject(t, testEval(engine, "-9223372036854775807 - 1 % -1"), -9223372036854775807)
+		testIntegerObject(t, testEval(engine, "1 << 62"), 4611686018427387904)
+		testIntegerObject(t, testEval(engine, "-1 << 63"), -9223372036854775808)
+		testIntegerObject(t, testEval(engine, "-(-9223372036854775807)"), 9223372036854775807)
+		testIntegerObject(t, testEval(engine, "0 << 70"), 0)
+		testIntegerObject(t, testEval(engine, "1 >> 70"), 0)
+	})
+}
+
+func TestInternalErrorRecovery(t *testing.T) {
+	// A prefix expression without a right-hand side can't be produced by the parser,
+	// but makes the evaluator panic which should be turned into an error
+	program := &ast.Program{
+		Statements: []ast.Statement{
+			&ast.ExpressionStatement{
+				Token: token.Token{Type: token.MINUS, Literal: "-", Line: 3, Column: 7},
+				Expression: &ast.PrefixExpression{
+					Token:    token.Token{Type: token.MINUS, Literal: "-", Line: 3, Column: 7},
+					Operator: "-",
+				},
+			},
 		},
 	}
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		errObj, ok := evaluated.(*object.Error)
+	evaluated := New().Eval(program, object.NewEnvironment())
+	err, ok := evaluated.(*object.Error)
 
-		if !ok {
-			t.Errorf("no error object returned. got %T(%+v)", evaluated, evaluated)
-			continue
+	if !ok {
+		t.Fatalf("object is not Error. got %T (%+v)", evaluated, evaluated)
+	}
+
+	if !strings.HasPrefix(err.Message, "[3:7] internal error: ") {
+		t.Errorf("wrong error message. got %q", err.Message)
+	}
+}
+
+func TestInternalErrorPosition(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		// Slicing with a start after the end makes the slice method panic, which points to its call in every engine
+		tests := []struct {
+			input    string
+			expected string
+		}{
+			{"[1, 2, 3].slice(2, 1);", "[1:17] internal error: "},
+			{"let x = 1;\nfunc f() {\n  return [1, 2, 3].slice(2, 1);\n}\nf();", "[3:26] internal error: "},
+			{"[1].map(func(x) { return [1, 2, 3].slice(2, 1); });", "[1:42] internal error: "},
+			{"try { [1, 2, 3].slice(2, 1); } catch (e) { e.message; }", ""},
 		}
 
-		if errObj.Message != test.expectedMessage {
-			t.Errorf("wrong error message. expected %q, got %q", test.expectedMessage, errObj.Message)
+		for _, tt := range tests {
+			evaluated := testEval(engine, tt.input)
+
+			if tt.expected == "" {
+				if isError(evaluated) {
+					t.Errorf("unexpected error for %q: %s", tt.input, evaluated.Inspect())
+				}
+
+				continue
+			}
+
+			err, ok := evaluated.(*object.Error)
+
+			if !ok {
+				t.Errorf("object is not Error for %q. got %T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+
+			if !strings.HasPrefix(err.Message, tt.expected) {
+				t.Errorf("wrong error message for %q. got %q, want prefix %q", tt.input, err.Message, tt.expected)
+			}
 		}
-	}
+	})
 }
 
 func TestLetStatements(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected int64
-	}{
-		{"let a = 5; a;", 5},
-		{"let a = 5 * 5; a;", 25},
-		{"let a = 5; let b = a; b;", 5},
-		{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
-	}
-	for _, tt := range tests {
-		testIntegerObject(t, testEval(tt.input), tt.expected)
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected int64
+		}{
+			{"let a = 5; a;", 5},
+			{"let a = 5 * 5; a;", 25},
+			{"let a = 5; let b = a; b;", 5},
+			{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
+		}
+		for _, tt := range tests {
+			testIntegerObject(t, testEval(engine, tt.input), tt.expected)
+		}
+	})
 }
 
 func TestFunctionObject(t *testing.T) {
-	input := "func(x) { x + 2; };"
-	evaluated := testEval(input)
-	function, ok := evaluated.(*object.Function)
+	testEngines(t, func(t *testing.T, engine string) {
+		input := "func(x) { x + 2; };"
+		evaluated := testEval(engine, input)
+		function, ok := evaluated.(*object.Function)
 
-	if !ok {
-		t.Fatalf("object is not Function. got %T (%+v)", evaluated, evaluated)
-	}
+		if !ok {
+			t.Fatalf("object is not Function. got %T (%+v)", evaluated, evaluated)
+		}
 
-	if len(function.Arguments) != 1 {
-		t.Fatalf("function has wrong arguments. Arguments=%+v", function.Arguments)
-	}
+		if len(function.Arguments) != 1 {
+			t.Fatalf("function has wrong arguments. Arguments=%+v", function.Arguments)
+		}
 
-	if function.Arguments[0].String() != "x" {
-		t.Fatalf("argument is not 'x'. got %q", function.Arguments[0])
-	}
+		if function.Arguments[0].String() != "x" {
+			t.Fatalf("argument is not 'x'. got %q", function.Arguments[0])
+		}
 
-	expectedBody := "{\n  (x + 2)\n}"
+		expectedBody := "{\n  (x + 2)\n}"
 
-	if function.Body.String() != expectedBody {
-		t.Fatalf("body is not %q. got %q", expectedBody, function.Body.String())
-	}
+		if function.Body.String() != expectedBody {
+			t.Fatalf("body is not %q. got %q", expectedBody, function.Body.String())
+		}
+	})
 }
 
 func TestFunctionApplication(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected int64
-	}{
-		{"let identity = func(x) { return x; }; identity(5);", 5},
-		{"let double = func(x) { return x * 2; }; double(5);", 10},
-		{"let add = func(x, y) { return x + y; }; add(5, 5);", 10},
-		{"let add = func(x, y) { return x + y; }; add(5 + 5, add(5, 5));", 20},
-		{"func(x) { return x; }(5)", 5},
-	}
-	for _, tt := range tests {
-		testIntegerObject(t, testEval(tt.input), tt.expected)
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected int64
+		}{
+			{"let identity = func(x) { return x; }; identity(5);", 5},
+			{"let double = func(x) { return x * 2; }; double(5);", 10},
+			{"let add = func(x, y) { return x + y; }; add(5, 5);", 10},
+			{"let add = func(x, y) { return x + y; }; add(5 + 5, add(5, 5));", 20},
+			{"func(x) { return x; }(5)", 5},
+		}
+		for _, tt := range tests {
+			testIntegerObject(t, testEval(engine, tt.input), tt.expected)
+		}
+	})
+}
+
+func TestFunctionArguments(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"func add(a, b = 10) { return a + b; } add(1);", 11},
+			{"func add(a, b = 10) { return a + b; } add(1, 2);", 3},
+			{"func f(a, b = a * 2) { return b; } f(4);", 8},
+			{"func f(a, b = c) { return b; } f(4);", "[1:16] identifier not found: c"},
+			{"func count(...rest) { return rest.length(); } count();", 0},
+			{"func count(a, ...rest) { return rest.length(); } count(1, 2, 3);", 2},
+			{"let sum = func(...numbers) { return numbers.reduce(func(acc, n) { return acc + n; }, 0); }; sum(1, 2, 3);", 6},
+			{"func add(a, b) { return a + b; } add(1);", "[1:38] wrong number of arguments for add. got 1, want 2"},
+			{"func add(a, b) { return a + b; } add(1, 2, 3);", "[1:38] wrong number of arguments for add. got 3, want 2"},
+			{"func add(a, b = 1) { return a + b; } add();", "[1:42] wrong number of arguments for add. got 0, want 1 to 2"},
+			{"func f(a, ...rest) { return a; } f();", "[1:36] wrong number of arguments for f. got 0, want at least 1"},
+			{"let add = func(a, b) { a + b }; add(1);", "[1:37] wrong number of arguments for add. got 1, want 2"},
+			{"func(a) { a }();", "[1:15] wrong number of arguments for anonymous function. got 0, want 1"},
+			{"[1, 2, 3].map(func(x, i = 10) { return x + i; })[0];", 1},
+			{"[1, 2, 3].map(func(...args) { return args.length(); })[0];", 3},
+			{"[1, 2, 3].filter(func(...args) { return args[0] > 1; }).length();", 2},
+		}
+
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+
+			switch expected := test.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case string:
+				testErrorObject(t, evaluated, expected)
+			}
+		}
+	})
+}
+
+func TestDestructuring(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"let [a, b] = [1, 2]; a + b;", 3},
+			{"let [a, b, ...rest] = [1, 2, 3, 4]; rest.length();", 2},
+			{"let [a, ...rest] = [1]; rest.length();", 0},
+			{"let [a, b = 10] = [1]; b;", 10},
+			{"let [a, b = a * 2] = [4, null]; b;", 8},
+			{"let [a, b] = [1]; b;", nil},
+			{`const {host, port = 80} = {"host": "localhost"}; port;`, 80},
+			{`const {host, port = 80} = {"port": 8080}; port;`, 8080},
+			{`let {size: s} = {"size": 3}; s;`, 3},
+			{`let {server: {ports: [first, second]}} = {"server": {"ports": [1, 2]}}; second;`, 2},
+			{`let {a, ...others} = {"a": 1, "b": 2, "c": 3}; others["c"];`, 3},
+			{`let {a, ...others} = {"a": 1, "b": 2, "c": 3}; others.keys().length();`, 2},
+			{`let [[a, b], {c}] = [[1, 2], {"c": 3}]; a + b + c;`, 6},
+			{`func f([a, b], {c = 3} = {}) { return a + b + c; } f([1, 2]);`, 6},
+			{`func f({a}, b = a) { return b; } f({"a": 5});`, 5},
+			{`[[1, 2], [3, 4]].map(func([a, b]) { return a * b; })[1];`, 12},
+			{"let [a, b] = 5;", "[1:6] can not destructure INTEGER as an array, expected ARRAY"},
+			{"let {a} = [1];", "[1:6] can not destructure ARRAY as an object, expected HASH"},
+			{"let [a, b = c] = [1];", "[1:14] identifier not found: c"},
+			{"let [a] = x;", "[1:12] identifier not found: x"},
+			{"func f({a}) { return a; } f(1);", "[1:9] can not destructure INTEGER as an object, expected HASH"},
+			{"func f([a] = b) { return a; } f();", "[1:15] identifier not found: b"},
+			{"let a = 1; func f() { let [a, b] = [2, 3]; return a; } f() + a;", 3},
+		}
+
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+
+			switch expected := test.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case string:
+				testErrorObject(t, evaluated, expected)
+			default:
+				testNullObject(t, evaluated)
+			}
+		}
+	})
 }
 
 func TestClosures(t *testing.T) {
-	input := `
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `
 let newAdder = func(x) {
 	return func(y) { return x + y; };
 };
@@ -631,116 +1404,207 @@ let newAdder = func(x) {
 let addTwo = newAdder(2);
 addTwo(2);`
 
-	testIntegerObject(t, testEval(input), 4)
+		testIntegerObject(t, testEval(engine, input), 4)
+	})
+}
+
+func TestScopes(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected int64
+		}{
+			{"let x = 1; if (true) { let x = 2; } x;", 1},
+			{"let x = 1; let y = 0; if (true) { let x = 2; if (true) { x += 10; } y = x; } y;", 12},
+			{"let x = 1; func f() { x = 5; } f(); x;", 5},
+			{"func f(a) { let b = 2; return func(c) { let d = 4; return a + b + c + d; }; } f(1)(3);", 10},
+			{"let fs = []; for (i in range(3)) { let j = i * 2; fs = fs.append(func() { return i + j; }); } fs[2]();", 6},
+			{"func f(n) { if (n == 0) { return 0; } let m = n; return m + f(n - 1); } f(4);", 10},
+			{"func f() { return g(); } func g() { return 3; } f();", 3},
+			{"let n = 0; for (let i = 0; i < 3; i++) { let i2 = i * i; n += i2; } n;", 5},
+			{"struct P { x; func add(y) { let z = self.x + y; return z; } } P(1).add(2);", 3},
+			{"let x = 1; func f() { let x = x + 1; return x; } f() * 10 + x;", 21},
+			{"func f(x) { if (true) { let x = x * 2; x += 1; return x; } } f(5);", 11},
+			{"let x = 1; let y = 0; if (true) { y = x; let x = 2; y += x; } y;", 3},
+		}
+
+		for _, tt := range tests {
+			testIntegerObject(t, testEval(engine, tt.input), tt.expected)
+		}
+	})
 }
 
 func TestStringLiteral(t *testing.T) {
-	input := `"Hello World!"`
-	evaluated := testEval(input)
-	str, ok := evaluated.(*object.String)
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `"Hello World!"`
+		evaluated := testEval(engine, input)
+		str, ok := evaluated.(*object.String)
 
-	if !ok {
-		t.Fatalf("object is not String. got %T (%+v)", evaluated, evaluated)
-	}
+		if !ok {
+			t.Fatalf("object is not String. got %T (%+v)", evaluated, evaluated)
+		}
 
-	if str.Value != "Hello World!" {
-		t.Errorf("String has wrong value. got %q", str.Value)
-	}
+		if str.Value != "Hello World!" {
+			t.Errorf("String has wrong value. got %q", str.Value)
+		}
+	})
+}
+
+func TestStringEscapes(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected string
+		}{
+			{`"Hello\nWorld!"`, "Hello\nWorld!"},
+			{`"\"quoted\"" + "\t" + "\\"`, "\"quoted\"\t\\"},
+			{`"\u{1F600}"`, "😀"},
+			{"`C:\\raw\\n`", `C:\raw\n`},
+			{"`multi\nline`", "multi\nline"},
+		}
+
+		for _, test := range tests {
+			testStringObject(t, testEval(engine, test.input), test.expected)
+		}
+	})
+}
+
+func TestStringInterpolation(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected string
+		}{
+			{`let name = "World"; "Hello ${name}!"`, "Hello World!"},
+			{`let user = {"name": "Ann"}; let items = [1, 2]; "Hello ${user.name}, you have ${len(items)} items"`, "Hello Ann, you have 2 items"},
+			{`"${1 + 2}${3.5}${true}${null}"`, "33.5truenull"},
+			{`"list: ${[1, "a"]}"`, "list: [1, a]"},
+			{`"outer ${"inner ${1 + 1}"}"`, "outer inner 2"},
+			{`"cost: $5 \${literal}"`, "cost: $5 ${literal}"},
+			{`let x = 1; "${x > 0 ? "positive" : "negative"}"`, "positive"},
+		}
+
+		for _, test := range tests {
+			testStringObject(t, testEval(engine, test.input), test.expected)
+		}
+
+		testErrorObject(t, testEval(engine, `"a ${1 + true} b"`), "[1:9] type mismatch: INTEGER + BOOLEAN")
+		testErrorObject(t, testEval(engine, "let a = 1;\n\"x ${a} ${a + \"\" - 1}\""), "[2:14] type mismatch: INTEGER + STRING")
+	})
 }
 
 func TestStringConcatenation(t *testing.T) {
-	input := `"Hello" + " " + "World!"`
-	evaluated := testEval(input)
-	str, ok := evaluated.(*object.String)
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `"Hello" + " " + "World!"`
+		evaluated := testEval(engine, input)
+		str, ok := evaluated.(*object.String)
 
-	if !ok {
-		t.Fatalf("object is not String. got %T (%+v)", evaluated, evaluated)
-	}
-	if str.Value != "Hello World!" {
-		t.Errorf("String has wrong value. got %q", str.Value)
-	}
+		if !ok {
+			t.Fatalf("object is not String. got %T (%+v)", evaluated, evaluated)
+		}
+		if str.Value != "Hello World!" {
+			t.Errorf("String has wrong value. got %q", str.Value)
+		}
+	})
 }
 
 func TestArrayLiterals(t *testing.T) {
-	input := "[1, 2 * 2, 3 + 3]"
-	evaluated := testEval(input)
-	result, ok := evaluated.(*object.Array)
+	testEngines(t, func(t *testing.T, engine string) {
+		input := "[1, 2 * 2, 3 + 3]"
+		evaluated := testEval(engine, input)
+		result, ok := evaluated.(*object.Array)
 
-	if !ok {
-		t.Fatalf("object is not Array. got %T (%+v)", evaluated, evaluated)
-	}
+		if !ok {
+			t.Fatalf("object is not Array. got %T (%+v)", evaluated, evaluated)
+		}
 
-	if len(result.Elements) != 3 {
-		t.Fatalf("array has wrong num of elements. got %d",
-			len(result.Elements))
-	}
+		if len(result.Elements) != 3 {
+			t.Fatalf("array has wrong num of elements. got %d",
+				len(result.Elements))
+		}
 
-	testIntegerObject(t, result.Elements[0], 1)
-	testIntegerObject(t, result.Elements[1], 4)
-	testIntegerObject(t, result.Elements[2], 6)
+		testIntegerObject(t, result.Elements[0], 1)
+		testIntegerObject(t, result.Elements[1], 4)
+		testIntegerObject(t, result.Elements[2], 6)
+	})
 }
 
 func TestArrayIndexExpressions(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected interface{}
-	}{
-		{
-			"[1, 2, 3][0]",
-			1,
-		},
-		{
-			"[1, 2, 3][1]",
-			2,
-		},
-		{
-			"[1, 2, 3][2]",
-			3,
-		},
-		{
-			"let i = 0; [1][i];",
-			1,
-		},
-		{
-			"[1, 2, 3][1 + 1];",
-			3,
-		},
-		{
-			"let arr = [1, 2, 3]; arr[2];",
-			3,
-		},
-		{
-			"let arr = [1, 2, 3]; arr[0] + arr[1] + arr[2];",
-			6,
-		},
-		{
-			"let arr = [1, 2, 3]; let i = arr[0]; arr[i]",
-			2,
-		},
-		{
-			"[1, 2, 3][3]",
-			nil,
-		},
-		{
-			"[1, 2, 3, 4, 5, 6][-1]",
-			6,
-		},
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{
+				"[1, 2, 3][0]",
+				1,
+			},
+			{
+				"[1, 2, 3][1]",
+				2,
+			},
+			{
+				"[1, 2, 3][2]",
+				3,
+			},
+			{
+				"let i = 0; [1][i];",
+				1,
+			},
+			{
+				"[1, 2, 3][1 + 1];",
+				3,
+			},
+			{
+				"let arr = [1, 2, 3]; arr[2];",
+				3,
+			},
+			{
+				"let arr = [1, 2, 3]; arr[0] + arr[1] + arr[2];",
+				6,
+			},
+			{
+				"let arr = [1, 2, 3]; let i = arr[0]; arr[i]",
+				2,
+			},
+			{
+				"[1, 2, 3][3]",
+				nil,
+			},
+			{
+				"[1, 2, 3, 4, 5, 6][-1]",
+				6,
+			},
+			{
+				"[1, 2, 3][-3]",
+				1,
+			},
+			{
+				"[1, 2][-5]",
+				nil,
+			},
+			{
+				"[][-1]",
+				nil,
+			},
+		}
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		integer, ok := test.expected.(int)
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			integer, ok := test.expected.(int)
 
-		if ok {
-			testIntegerObject(t, evaluated, int64(integer))
-		} else {
-			testNullObject(t, evaluated)
+			if ok {
+				testIntegerObject(t, evaluated, int64(integer))
+			} else {
+				testNullObject(t, evaluated)
+			}
 		}
-	}
+	})
 }
 
 func TestHashLiterals(t *testing.T) {
-	input := `let two = "two";
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `let two = "two";
 {
 "one": 10 - 9,
 two: 1 + 1,
@@ -750,201 +1614,542 @@ true: 5,
 false: 6
 }`
 
-	evaluated := testEval(input)
-	result, ok := evaluated.(*object.Hash)
+		evaluated := testEval(engine, input)
+		result, ok := evaluated.(*object.Hash)
 
-	if !ok {
-		t.Fatalf("Eval didn't return Hash. got %T (%+v)", evaluated, evaluated)
-	}
+		if !ok {
+			t.Fatalf("Eval didn't return Hash. got %T (%+v)", evaluated, evaluated)
+		}
 
-	expected := map[object.HashKey]int64{
-		(object.NewString(nil, "one")).HashKey():   1,
-		(object.NewString(nil, "two")).HashKey():   2,
-		(object.NewString(nil, "three")).HashKey(): 3,
-		(object.NewInteger(nil, 4)).HashKey():      4,
-		object.TRUE.HashKey():                      5,
-		object.FALSE.HashKey():                     6,
-	}
+		expected := map[object.HashKey]int64{
+			(object.NewString(nil, "one")).HashKey():   1,
+			(object.NewString(nil, "two")).HashKey():   2,
+			(object.NewString(nil, "three")).HashKey(): 3,
+			(object.NewInteger(nil, 4)).HashKey():      4,
+			object.TRUE.HashKey():                      5,
+			object.FALSE.HashKey():                     6,
+		}
 
-	if len(result.Pairs) != len(expected) {
-		t.Fatalf("Hash has wrong num of pairs. got %d", len(result.Pairs))
-	}
+		if len(result.Pairs) != len(expected) {
+			t.Fatalf("Hash has wrong num of pairs. got %d", len(result.Pairs))
+		}
 
-	for expectedKey, expectedValue := range expected {
-		pair, ok := result.Pairs[expectedKey]
+		for expectedKey, expectedValue := range expected {
+			pair, ok := result.Pairs[expectedKey]
 
-		if !ok {
-			t.Errorf("no pair for given key in Pairs")
+			if !ok {
+				t.Errorf("no pair for given key in Pairs")
+			}
+
+			testIntegerObject(t, pair.Value, expectedValue)
+		}
+	})
+}
+
+func TestSpreadExpressions(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"let a = [2, 3]; [1, ...a, 4].length();", 4},
+			{"let a = [2, 3]; [1, ...a, 4][2];", 3},
+			{"[...[], ...[]].length();", 0},
+			{`[..."héllo"][1] == "é";`, true},
+			{"func add(a, b, c) { return a + b + c; } let args = [1, 2, 3]; add(...args);", 6},
+			{"func add(a, b, c) { return a + b + c; } add(1, ...[2, 3]);", 6},
+			{"let sum = func(...numbers) { return numbers.length(); }; sum(...[1, 2], ...[3]);", 3},
+			{"let a = [1, 2]; let b = [...a]; b[0] = 5; a[0];", 1},
+			{`let a = {"x": 1, "y": 2}; let b = {...a, "y": 3}; b["x"] + b["y"];`, 4},
+			{`let a = {"x": 1, "y": 2}; let b = {"y": 3, ...a}; b["y"];`, 2},
+			{`let b = {...{"x": 1}, ...{"x": 2}}; b["x"];`, 2},
+			{"[...5];", "[1:5] can not spread INTEGER, expected ARRAY or STRING"},
+			{"func f(a) { return a; } f(...null);", "[1:30] can not spread NULL, expected ARRAY or STRING"},
+			{`{...[1]};`, "[1:5] can not spread ARRAY in object, expected HASH"},
+			{`[...x];`, "[1:6] identifier not found: x"},
+			{`{...x};`, "[1:6] identifier not found: x"},
 		}
 
-		testIntegerObject(t, pair.Value, expectedValue)
-	}
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+
+			switch expected := test.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case bool:
+				testBooleanObject(t, evaluated, expected)
+			case string:
+				testErrorObject(t, evaluated, expected)
+			}
+		}
+	})
 }
 
 func TestHashIndexExpressions(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected interface{}
-	}{
-		{
-			`{"foo": 5}["foo"]`,
-			5,
-		},
-		{
-			`{"foo": 5}["bar"]`,
-			nil,
-		},
-		{
-			`let key = "foo"; {"foo": 5}[key]`,
-			5,
-		},
-		{
-			`{}["foo"]`,
-			nil,
-		},
-		{
-			`{5: 5}[5]`,
-			5,
-		},
-		{
-			`{true: 5}[true]`,
-			5,
-		},
-		{
-			`{false: 5}[false]`,
-			5,
-		},
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{
+				`{"foo": 5}["foo"]`,
+				5,
+			},
+			{
+				`{"foo": 5}["bar"]`,
+				nil,
+			},
+			{
+				`let key = "foo"; {"foo": 5}[key]`,
+				5,
+			},
+			{
+				`{}["foo"]`,
+				nil,
+			},
+			{
+				`{5: 5}[5]`,
+				5,
+			},
+			{
+				`{true: 5}[true]`,
+				5,
+			},
+			{
+				`{false: 5}[false]`,
+				5,
+			},
+		}
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		integer, ok := test.expected.(int)
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			integer, ok := test.expected.(int)
 
-		if ok {
-			testIntegerObject(t, evaluated, int64(integer))
-		} else {
-			testNullObject(t, evaluated)
+			if ok {
+				testIntegerObject(t, evaluated, int64(integer))
+			} else {
+				testNullObject(t, evaluated)
+			}
 		}
-	}
+	})
+}
+
+func TestHashOrder(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected string
+		}{
+			{`string({"c": 1, "a": 2, "b": 3, 1: 4, true: 5})`, "{c: 1, a: 2, b: 3, 1: 4, true: 5}"},
+			{`string({"c": 1, "a": 2, "b": 3}.keys())`, "[c, a, b]"},
+			{`string({"c": 1, "a": 2, "b": 3}.values())`, "[1, 2, 3]"},
+			{`string({"c": 1, "a": 2, "b": 3}.items())`, "[[c, 1], [a, 2], [b, 3]]"},
+			{`let h = {"c": 1, "a": 2}; h.b = 3; h.c = 4; string(h);`, "{c: 4, a: 2, b: 3}"},
+			{`string({"c": 1, "a": 1, "c": 2})`, "{c: 2, a: 1}"},
+			{`let h = {"b": 1, "z": 2}; string({"c": 0, ...h, "a": 3, "b": 4});`, "{c: 0, b: 4, z: 2, a: 3}"},
+			{`let { b, ...rest } = {"c": 1, "b": 2, "a": 3, "d": 4}; string(rest);`, "{c: 1, a: 3, d: 4}"},
+			{`let s = ""; for (k, v in {"c": 1, "a": 2, "b": 3}) { s += k; } s;`, "cab"},
+			{`let order = ""; func key(k) { order += k; return k; } let h = {key("c"): 1, key("a"): 2, key("b"): 3}; order;`, "cab"},
+			{`let keys = null; try { throw "oops"; } catch (e) { keys = string(e.keys()); } keys;`, "[message, line, column, value]"},
+		}
+
+		for _, test := range tests {
+			testStringObject(t, testEval(engine, test.input), test.expected)
+		}
+	})
 }
 
 func TestVariableReassignment(t *testing.T) {
-	input := `let x = 1;
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `let x = 1;
 x = 4;
 x;`
 
-	testIntegerObject(t, testEval(input), 4)
+		testIntegerObject(t, testEval(engine, input), 4)
+	})
 }
 
 func TestIsError(t *testing.T) {
-	input := `5 + "a"`
-	evaluated := testEval(input)
-	if evaluated.Type() != object.ERROR_OBJ {
-		t.Errorf("no error object returned. got %T (%+v)", evaluated, evaluated)
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		input := `5 + "a"`
+		evaluated := testEval(engine, input)
+		if evaluated.Type() != object.ERROR_OBJ {
+			t.Errorf("no error object returned. got %T (%+v)", evaluated, evaluated)
+		}
 
-	if isError(nil) {
-		t.Errorf("nil is error")
-	}
+		if isError(nil) {
+			t.Errorf("nil is error")
+		}
+	})
 }
 
 func TestIsTruthy(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected bool
-	}{
-		{"true", true},
-		{"false", false},
-		{"1 == 1", true},
-		{"1 != 1", false},
-		{"first([])", false},
-		{"null", false},
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected bool
+		}{
+			{"true", true},
+			{"false", false},
+			{"1 == 1", true},
+			{"1 != 1", false},
+			{"first([])", false},
+			{"null", false},
+		}
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		if isTruthy(evaluated) != test.expected {
-			t.Errorf("wrong truth value. got %t for input %s", isTruthy(evaluated), test.input)
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			if isTruthy(evaluated) != test.expected {
+				t.Errorf("wrong truth value. got %t for input %s", isTruthy(evaluated), test.input)
+			}
 		}
-	}
+	})
 }
 
 func TestReassignmentExpressions(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected interface{}
-	}{
-		{"let x = 1; x = 2; x;", 2},
-		{"let x = 1; x += 4; x;", 5},
-		{"let x = 1; x -= 4; x;", -3},
-		{"let x = 1; x *= 4; x;", 4},
-		{"let x = 1; x /= 4; x;", 0.25},
-		{"let x = 1; x %= 4; x;", 1},
-		{"let x = 1; x &= 4; x;", 0},
-		{"let x = 1; x |= 4; x;", 5},
-		{"let x = 1; x ^= 4; x;", 5},
-		{"let x = 1; x <<= 4; x;", 16},
-		{"let x = 1; x >>= 4; x;", 0},
-	}
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"let x = 1; x = 2; x;", 2},
+			{"let x = 1; x += 4; x;", 5},
+			{"let x = 1; x -= 4; x;", -3},
+			{"let x = 1; x *= 4; x;", 4},
+			{"let x = 1; x /= 4; x;", 0.25},
+			{"let x = 1; x %= 4; x;", 1},
+			{"let x = 1; x &= 4; x;", 0},
+			{"let x = 1; x |= 4; x;", 5},
+			{"let x = 1; x ^= 4; x;", 5},
+			{"let x = 1; x <<= 4; x;", 16},
+			{"let x = 1; x >>= 4; x;", 0},
+		}
+
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			integer, ok := test.expected.(int)
+
+			if ok {
+				testIntegerObject(t, evaluated, int64(integer))
+				continue
+			}
+
+			float, ok := test.expected.(float64)
+
+			if ok {
+				testFloatObject(t, evaluated, float)
+				continue
+			}
+
+			err, ok := test.expected.(string)
+
+			if ok {
+				testErrorObject(t, evaluated, err)
+				continue
+			}
+
+			testNullObject(t, evaluated)
+		}
+	})
+}
+
+func TestIncrementDecrementExpressions(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"let x = 1; x++; x;", 2},
+			{"let x = 1; x--; x;", 0},
+			{"let x = 1; ++x; x;", 2},
+			{"let x = 1; --x; x;", 0},
+			{"let x = 1.5; x++; x;", 2.5},
+			{"let x = 1.5; x--; x;", 0.5},
+			{"let x = 1.5; ++x; x;", 2.5},
+			{"let x = 1.5; --x; x;", 0.5},
+		}
+
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			integer, ok := test.expected.(int)
+
+			if ok {
+				testIntegerObject(t, evaluated, int64(integer))
+				continue
+			}
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		integer, ok := test.expected.(int)
+			float, ok := test.expected.(float64)
+
+			if ok {
+				testFloatObject(t, evaluated, float)
+				continue
+			}
+
+			testNullObject(t, evaluated)
+		}
+	})
+}
 
-		if ok {
-			testIntegerObject(t, evaluated, int64(integer))
-			continue
+func TestNumbersAreImmutable(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"let a = 1; let b = a; b++; a;", 1},
+			{"let a = 1; let b = a; b++; b;", 2},
+			{"let a = 1; let b = a; --b; a;", 1},
+			{"let a = 1.5; let b = a; b++; a;", 1.5},
+			{"let a = 1; let arr = [a, a]; a++; arr[0];", 1},
+			{"let a = 1; let arr = [a, a]; arr[0]++; arr[1];", 1},
+			{"let a = 1; let arr = [a, a]; arr[0]++; a;", 1},
+			{"let arr = [1]; let x = arr[0]; x++; arr[0];", 1},
+			{"let arr = [1, 2]; let x = first(arr); x++; arr[0];", 1},
+			{"let arr = [1, 2]; let x = last(arr); x--; arr[1];", 2},
+			{`let h = {"n": 1}; let n = h.n; n++; h.n;`, 1},
+			{"let a = 1; let get = func() { return a; }; let b = a; b++; get();", 1},
+			{"let a = 1; let inc = func() { a++; }; let b = a; inc(); b;", 1},
+			{"let a = 1; let inc = func() { a++; }; inc(); inc(); a;", 3},
+			{"let counter = func() { let n = 0; return func() { n++; return n; }; }; let c = counter(); let first = c(); c(); first;", 1},
+			{"let n = 0; let seen = []; for (let i = 0; i < 3; i++) { seen = [...seen, n]; n++; } seen[0];", 0},
+			{"let f = func(x) { x++; return x; }; let a = 1; f(a); a;", 1},
+			{"let a = 1; let x = a++; x;", 1},
+			{"let a = 1; let x = ++a; x;", 2},
 		}
 
-		float, ok := test.expected.(float64)
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
 
-		if ok {
-			testFloatObject(t, evaluated, float)
-			continue
+			switch expected := test.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case float64:
+				testFloatObject(t, evaluated, expected)
+			}
 		}
+	})
+}
+
+func TestMemberAssignment(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"let a = [1, 2, 3]; a[0] = 5; a[0];", 5},
+			{"let a = [1, 2, 3]; a[-1] = 5; a[2];", 5},
+			{"let a = [1, 2, 3]; a[1] += 5; a[1];", 7},
+			{"let a = [1, 2, 3]; a[1] *= 1.5; a[1];", 3.0},
+			{"let a = [[1, 2], [3, 4]]; a[1][0] = 9; a[1][0];", 9},
+			{"let a = [1, 2, 3]; let b = a; b[0] = 5; a[0];", 5},
+			{`let h = {"a": 1}; h["a"] = 2; h["a"];`, 2},
+			{`let h = {"a": 1}; h["b"] = 2; h["b"];`, 2},
+			{`let h = {"a": 1}; h.a = 2; h["a"];`, 2},
+			{`let h = {"a": 1}; h.b = 3; h["b"];`, 3},
+			{`let h = {"a": 1}; h.a += 4; h["a"];`, 5},
+			{`let h = {"a": {"b": 1}}; h["a"].b <<= 3; h["a"]["b"];`, 8},
+			{`let h = {1: 1}; h[1] -= 2; h[1];`, -1},
+			{`let h = {}; h.a = [1, 2]; h["a"][1] = 3; h["a"][1];`, 3},
+			{"let a = [1, 2, 3]; a[3] = 4;", "[1:26] index 3 out of range for array of length 3"},
+			{"let a = [1, 2, 3]; a[-4] = 4;", "[1:27] index -4 out of range for array of length 3"},
+			{`let a = [1, 2, 3]; a["0"] = 4;`, "[1:28] array index must be INTEGER, got STRING"},
+			{`let h = {}; h[len] = 4;`, "[1:21] unusable as object key: BUILTIN"},
+			{`let h = {}; h.a += 4;`, "[1:19] type mismatch: NULL + INTEGER"},
+			{`let s = "abc"; s[0] = "d";`, "[1:22] index assignment not supported: STRING"},
+		}
+
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			integer, ok := test.expected.(int)
+
+			if ok {
+				testIntegerObject(t, evaluated, int64(integer))
+				continue
+			}
 
-		err, ok := test.expected.(string)
+			float, ok := test.expected.(float64)
 
-		if ok {
-			testErrorObject(t, evaluated, err)
-			continue
+			if ok {
+				testFloatObject(t, evaluated, float)
+				continue
+			}
+
+			err, ok := test.expected.(string)
+
+			if ok {
+				testErrorObject(t, evaluated, err)
+				continue
+			}
+
+			testNullObject(t, evaluated)
 		}
+	})
+}
 
-		testNullObject(t, evaluated)
-	}
+func TestCyclicContainers(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected string
+		}{
+			{`let h = {}; h["self"] = h; string(h);`, "{self: {...}}"},
+			{`let h = {"a": 1}; h.self = h; "${h}";`, "{a: 1, self: {...}}"},
+			{"let x = [1, 0]; x[1] = x; string(x);", "[1, [...]]"},
+			{"let x = [1]; let h = {}; h.x = x; x[0] = h; string([x, h]);", "[[{x: [...]}], {x: [{...}]}]"},
+		}
+
+		for _, test := range tests {
+			testStringObject(t, testEval(engine, test.input), test.expected)
+		}
+	})
 }
 
-func TestIncrementDecrementExpressions(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected interface{}
-	}{
-		{"let x = 1; x++; x;", 2},
-		{"let x = 1; x--; x;", 0},
-		{"let x = 1; ++x; x;", 2},
-		{"let x = 1; --x; x;", 0},
-		{"let x = 1.5; x++; x;", 2.5},
-		{"let x = 1.5; x--; x;", 0.5},
-		{"let x = 1.5; ++x; x;", 2.5},
-		{"let x = 1.5; --x; x;", 0.5},
-	}
+func TestMemberIncrementDecrementExpressions(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{"let a = [1, 2]; a[0]++; a[0];", 2},
+			{"let a = [1, 2]; a[0]--; a[0];", 0},
+			{"let a = [1, 2]; ++a[1]; a[1];", 3},
+			{"let a = [1, 2]; --a[1]; a[1];", 1},
+			{"let a = [1, 2]; a[0]++;", 1},
+			{"let a = [1, 2]; ++a[0];", 2},
+			{"let a = [1.5]; a[0]++; a[0];", 2.5},
+			{`let h = {"n": 1}; h.n++; h["n"];`, 2},
+			{`let h = {"n": 1}; --h.n; h["n"];`, 0},
+			{`let h = {"n": 1}; h["n"]++; h["n"];`, 2},
+			{"let x = 1; let a = [x]; a[0]++; x;", 1},
+			{"let a = [0, 0, 0]; for (let i = 0; i < 3; i++) { a[i] = i * 2; }; a[2];", 4},
+			{"let a = [1]; a[1]++;", "[1:20] index 1 out of range for array of length 1"},
+			{`let a = ["x"]; a[0]++;`, "[1:22] unknown operator: ++"},
+		}
+
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+			integer, ok := test.expected.(int)
+
+			if ok {
+				testIntegerObject(t, evaluated, int64(integer))
+				continue
+			}
+
+			float, ok := test.expected.(float64)
 
-	for _, test := range tests {
-		evaluated := testEval(test.input)
-		integer, ok := test.expected.(int)
+			if ok {
+				testFloatObject(t, evaluated, float)
+				continue
+			}
 
-		if ok {
-			testIntegerObject(t, evaluated, int64(integer))
-			continue
+			err, ok := test.expected.(string)
+
+			if ok {
+				testErrorObject(t, evaluated, err)
+				continue
+			}
+
+			testNullObject(t, evaluated)
+		}
+	})
+}
+
+func TestTryCatchFinally(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{`let x = 0; try { x = 1; } catch (e) { x = 2; }; x;`, 1},
+			{`let x = 0; try { 1 + ""; x = 1; } catch (e) { x = 2; }; x;`, 2},
+			{`let x = 0; try { throw "oops"; } catch { x = 2; }; x;`, 2},
+			{`let x = 0; try { throw "oops"; } catch (e) { x = 2; } finally { x = x * 10; }; x;`, 20},
+			{`let x = 0; try { x = 1; } finally { x = x * 10; }; x;`, 10},
+			{`let m = ""; try { throw "oops"; } catch (e) { m = e["message"]; }; m;`, "oops"},
+			{`let m = ""; try { 1 + ""; } catch (e) { m = e["message"]; }; m;`, "type mismatch: INTEGER + STRING"},
+			{`let m = 0; try { 1 + ""; } catch (e) { m = e["line"]; }; m;`, 1},
+			{`let m = 0; try { throw 5; } catch (e) { m = e["value"]; }; m;`, 5},
+			{`let m = ""; try { try { throw "inner"; } catch (e) { throw e; } } catch (e) { m = e["message"]; }; m;`, "inner"},
+			{`func f() { throw "from f"; }; let m = ""; try { f(); } catch (e) { m = e["message"]; }; m;`, "from f"},
+			{`func f() { try { return 1; } finally { 2; } }; f();`, 1},
+			{`func f() { try { return 1; } finally { return 2; } }; f();`, 2},
+			{`func f() { try { throw "x"; } catch (e) { return 3; } }; f();`, 3},
+			{`let x = 0; for (let i = 0; i < 5; i += 1) { try { if (i == 3) { break; } x += 1; } finally { x += 10; } }; x;`, 43},
+			{`let x = 0; for (let i = 0; i < 3; i += 1) { try { continue; } finally { x += 1; } }; x;`, 3},
+			{`try { throw "uncaught"; } finally { 1; }`, "[1:8] uncaught"},
+			{`try { 1; } finally { throw "finally"; }`, "[1:23] finally"},
+			{`throw "oops";`, "[1:2] oops"},
+			{`throw [1, 2];`, "[1:2] [1, 2]"},
+			{`throw 1 + "";`, "[1:10] type mismatch: INTEGER + STRING"},
+			{`try { 1 + ""; } catch (e) { throw e; }`, "[1:10] type mismatch: INTEGER + STRING"},
+			{`try { 1 + ""; } catch (e) { e["message"] = "changed"; throw e; }`, "[1:10] changed"},
+			{`let l = 0; try { try { throw "x"; } catch (e) { throw e; } } catch (e) { l = e["column"]; }; l;`, 25},
+			// Outside of its catch block a caught error is thrown like any other object
+			{`let c = null; try { 1 + ""; } catch (e) { c = e; } throw c;`, "[1:53] type mismatch: INTEGER + STRING"},
 		}
 
-		float, ok := test.expected.(float64)
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+
+			switch expected := test.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case string:
+				if evaluated != nil && evaluated.Type() == object.ERROR_OBJ {
+					testErrorObject(t, evaluated, expected)
+				} else {
+					testStringObject(t, evaluated, expected)
+				}
+			}
+		}
+	})
+}
 
-		if ok {
-			testFloatObject(t, evaluated, float)
-			continue
+func TestLogicalOperators(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{`1 || 0`, 1},
+			{`0 || 1`, 0},
+			{`null || 5`, 5},
+			{`false || "default"`, "default"},
+			{`"value" || "default"`, "value"},
+			{`1 && 2`, 2},
+			{`0 && 2`, 2},
+			{`null && 2`, nil},
+			{`false && 2`, false},
+			{`true && "yes"`, "yes"},
+			{`null || null`, nil},
+			{`let x = null; x || 10;`, 10},
+			{`let x = null; x != null && x.length() > 0;`, false},
+			{`let x = "abc"; x != null && x.length() > 0;`, true},
+			{`true || 1 + ""`, true},
+			{`false && 1 + ""`, false},
+			{`false || 1 + ""`, "[1:13] type mismatch: INTEGER + STRING"},
+			{`1 + "" || true`, "[1:4] type mismatch: INTEGER + STRING"},
+			{`let calls = 0; func f() { calls += 1; return true; }; true || f(); false && f(); calls;`, 0},
+			{`let calls = 0; func f() { calls += 1; return true; }; false || f(); true && f(); calls;`, 2},
 		}
 
-		testNullObject(t, evaluated)
-	}
+		for _, test := range tests {
+			evaluated := testEval(engine, test.input)
+
+			switch expected := test.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case bool:
+				testBooleanObject(t, evaluated, expected)
+			case string:
+				if evaluated != nil && evaluated.Type() == object.ERROR_OBJ {
+					testErrorObject(t, evaluated, expected)
+				} else {
+					testStringObject(t, evaluated, expected)
+				}
+			default:
+				testNullObject(t, evaluated)
+			}
+		}
+	})
 }
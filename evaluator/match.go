@@ -0,0 +1,166 @@
+package evaluator
+
+import (
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/object"
+)
+
+/*
+Evaluate a match expression, returning the value of the body of the first arm that matches the subject.
+
+Every arm is tried in a new environment so names bound by a pattern are only visible to its guard and body.
+Returns null if no arm matches.
+*/
+func (e *Evaluator) evalMatchExpression(node *ast.MatchExpression, env *object.Environment) object.Object {
+	subject := e.Eval(node.Subject, env)
+
+	if isError(subject) {
+		return subject
+	}
+
+	for _, arm := range node.Arms {
+		for _, pattern := range arm.Patterns {
+			armEnv := object.NewScope(env, arm.Slots)
+			matched, err := e.matchPattern(pattern, subject, armEnv)
+
+			if err != nil {
+				return err
+			}
+
+			if !matched {
+				continue
+			}
+
+			if arm.Guard != nil {
+				guard := e.Eval(arm.Guard, armEnv)
+
+				if isError(guard) {
+					return guard
+				}
+
+				if !isTruthy(guard) {
+					continue
+				}
+			}
+
+			return e.Eval(arm.Body, armEnv)
+		}
+	}
+
+	return object.NULL
+}
+
+/*
+Check if a value matches a pattern of a match expression, binding the names in the pattern in the given environment
+*/
+func (e *Evaluator) matchPattern(pattern ast.Expression, value object.Object, env *object.Environment) (bool, *object.Error) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != "_" {
+			env.Define(pattern, value)
+		}
+
+		return true, nil
+	case *ast.ArrayPattern:
+		array, ok := value.(*object.Array)
+
+		if !ok || len(array.Elements) < len(pattern.Elements) {
+			return false, nil
+		}
+
+		if pattern.Rest == nil && len(array.Elements) != len(pattern.Elements) {
+			return false, nil
+		}
+
+		for i, element := range pattern.Elements {
+			if matched, err := e.matchPattern(element.Target, array.Elements[i], env); !matched || err != nil {
+				return false, err
+			}
+		}
+
+		if pattern.Rest != nil {
+			rest := append([]object.Object{}, array.Elements[len(pattern.Elements):]...)
+			env.Define(pattern.Rest, object.NewArray(pattern.Rest, rest))
+		}
+
+		return true, nil
+	case *ast.HashPattern:
+		hash, ok := value.(*object.Hash)
+
+		if !ok {
+			return false, nil
+		}
+
+		used := map[object.HashKey]bool{}
+
+		for _, element := range pattern.Elements {
+			property, ok := hashProperty(element.Key, hash, element.Key.Value)
+
+			if !ok {
+				return false, nil
+			}
+
+			used[object.NewString(element.Key, element.Key.Value).HashKey()] = true
+
+			if matched, err := e.matchPattern(element.Target, property, env); !matched || err != nil {
+				return false, err
+			}
+		}
+
+		if pattern.Rest != nil {
+			rest := object.NewHash(pattern.Rest)
+
+			for _, hashed := range hash.Keys {
+				if !used[hashed] {
+					rest.Set(hashed, hash.Pairs[hashed])
+				}
+			}
+
+			env.Define(pattern.Rest, rest)
+		}
+
+		return true, nil
+	}
+
+	expected := e.Eval(pattern, env)
+
+	if isError(expected) { // coverage-ignore
+		return false, expected.(*object.Error)
+	}
+
+	return literalMatches(expected, value), nil
+}
+
+/*
+Check if a value is equal to the value of a literal pattern, integers and floats match if they have the same value
+*/
+func literalMatches(expected object.Object, value object.Object) bool {
+	switch expected := expected.(type) {
+	case *object.Integer:
+		switch value := value.(type) {
+		case *object.Integer:
+			return expected.Value == value.Value
+		case *object.Float:
+			return float64(expected.Value) == value.Value
+		}
+	case *object.Float:
+		switch value := value.(type) {
+		case *object.Integer:
+			return expected.Value == float64(value.Value)
+		case *object.Float:
+			return expected.Value == value.Value
+		}
+	case *object.String:
+		if value, ok := value.(*object.String); ok {
+			return expected.Value == value.Value
+		}
+	case *object.Boolean:
+		if value, ok := value.(*object.Boolean); ok {
+			return expected.Value == value.Value
+		}
+	case *object.Null:
+		return value.Type() == object.NULL_OBJ
+	}
+
+	return false
+}
@@ -0,0 +1,242 @@
+package evaluator
+
+import (
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/object"
+	"github.com/iskandervdh/vorn/token"
+)
+
+/*
+Evaluate the container and key of an assignment target like arr[0] or obj.key.
+
+For dotted targets the key is the name of the property as a string.
+*/
+func (e *Evaluator) evalMemberTarget(target ast.Expression, env *object.Environment) (object.Object, object.Object, *object.Error) {
+	switch target := target.(type) {
+	case *ast.IndexExpression:
+		container := e.Eval(target.Left, env)
+
+		if isError(container) {
+			return nil, nil, container.(*object.Error)
+		}
+
+		key := e.Eval(target.Index, env)
+
+		if isError(key) {
+			return nil, nil, key.(*object.Error)
+		}
+
+		return container, key, nil
+	case *ast.ChainingExpression:
+		container := e.Eval(target.Left, env)
+
+		if isError(container) {
+			return nil, nil, container.(*object.Error)
+		}
+
+		property := target.Right.(*ast.Identifier)
+
+		return container, object.NewString(property, property.Value), nil
+	}
+
+	return nil, nil, object.NewError(target, "invalid assignment target %s", target.String())
+}
+
+/*
+Get the index into the elements of an array, supporting negative indices from the end of the array.
+
+Returns an error if the index is out of range.
+*/
+func arrayElementIndex(node ast.Node, array *object.Array, key object.Object) (int64, *object.Error) {
+	index, ok := key.(*object.Integer)
+
+	if !ok {
+		return 0, object.NewError(node, "array index must be INTEGER, got %s", object.TypeName(key))
+	}
+
+	length := int64(len(array.Elements))
+	idx := index.Value
+
+	if idx < 0 {
+		idx += length
+	}
+
+	if idx < 0 || idx >= length {
+		return 0, object.NewError(node, "index %d out of range for array of length %d", index.Value, length)
+	}
+
+	return idx, nil
+}
+
+/*
+Read the current value of an array element or object property that is being assigned to
+*/
+func (e *Evaluator) getMember(node ast.Node, container, key object.Object) object.Object {
+	switch container := container.(type) {
+	case *object.Array:
+		idx, err := arrayElementIndex(node, container, key)
+
+		if err != nil {
+			return err
+		}
+
+		return container.Elements[idx]
+	case *object.Hash:
+		hashKey, ok := object.HashKeyOf(key)
+
+		if !ok {
+			return object.NewError(node, "unusable as object key: %s", object.TypeName(key))
+		}
+
+		pair, ok := container.Pairs[hashKey]
+
+		if !ok {
+			return object.NULL
+		}
+
+		return pair.Value
+	case *object.Instance:
+		name, err := instanceFieldName(node, container, key)
+
+		if err != nil {
+			return err
+		}
+
+		return container.Fields[name]
+	}
+
+	return object.NewError(node, "index assignment not supported: %s", object.TypeName(container))
+}
+
+/*
+Set the value of an array element or object property.
+
+Assigning to a new key of an object adds it, assigning outside the bounds of an array is an error.
+*/
+func (e *Evaluator) setMember(node ast.Node, container, key, value object.Object) *object.Error {
+	switch container := container.(type) {
+	case *object.Array:
+		idx, err := arrayElementIndex(node, container, key)
+
+		if err != nil {
+			return err
+		}
+
+		container.Elements[idx] = value
+
+		return nil
+	case *object.Hash:
+		hashKey, ok := object.HashKeyOf(key)
+
+		if !ok {
+			return object.NewError(node, "unusable as object key: %s", object.TypeName(key))
+		}
+
+		container.Set(hashKey, object.HashPair{Key: key, Value: value})
+
+		return nil
+	case *object.Instance:
+		name, err := instanceFieldName(node, container, key)
+
+		if err != nil {
+			return err
+		}
+
+		container.Fields[name] = value
+
+		return nil
+	}
+
+	return object.NewError(node, "index assignment not supported: %s", object.TypeName(container))
+}
+
+func (e *Evaluator) evalMemberReassignmentExpression(node *ast.MemberReassignmentExpression, env *object.Environment) object.Object {
+	container, key, err := e.evalMemberTarget(node.Target, env)
+
+	if err != nil {
+		return err
+	}
+
+	value := e.Eval(node.Value, env)
+
+	if isError(value) {
+		return value
+	}
+
+	if err := e.assignMember(node, container, key, value); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/*
+Assign a value to the member key of container, applying the operator of a compound assignment like += to the current value first
+*/
+func (e *Evaluator) assignMember(node *ast.MemberReassignmentExpression, container, key, value object.Object) *object.Error {
+	if node.Token.Type != token.ASSIGN {
+		operator, ok := token.AssignmentInfixOperator(node.Token.Type)
+
+		if !ok { // coverage-ignore
+			return object.NewError(node, "unknown operator: %s", node.Token.Literal)
+		}
+
+		current := e.getMember(node, container, key)
+
+		if isError(current) {
+			return current.(*object.Error)
+		}
+
+		value = e.evalInfixExpression(&ast.InfixExpression{
+			Token:    node.Token,
+			Left:     node.Target,
+			Operator: string(operator),
+			Right:    node.Value,
+		}, current, value)
+
+		if isError(value) {
+			return value.(*object.Error)
+		}
+	}
+
+	return e.setMember(node, container, key, value)
+}
+
+func (e *Evaluator) evalMemberIncrementDecrementExpression(node *ast.MemberIncrementDecrementExpression, env *object.Environment) object.Object {
+	container, key, err := e.evalMemberTarget(node.Target, env)
+
+	if err != nil {
+		return err
+	}
+
+	return e.incrementDecrementMember(node, container, key)
+}
+
+/*
+Apply the ++ or -- operator to the member key of container.
+
+Returns the value before the update when the operator comes before the target, the updated value otherwise.
+*/
+func (e *Evaluator) incrementDecrementMember(node *ast.MemberIncrementDecrementExpression, container, key object.Object) object.Object {
+	current := e.getMember(node, container, key)
+
+	if isError(current) {
+		return current
+	}
+
+	updated := incrementDecrement(node, node.Token, current)
+
+	if isError(updated) {
+		return updated
+	}
+
+	if err := e.setMember(node, container, key, updated); err != nil { // coverage-ignore
+		return err
+	}
+
+	if node.Before {
+		return current
+	}
+
+	return updated
+}
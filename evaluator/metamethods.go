@@ -0,0 +1,203 @@
+package evaluator
+
+import (
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/object"
+	"github.com/iskandervdh/vorn/token"
+)
+
+// Names of the methods a struct can define to overload an arithmetic operator
+var arithmeticMetamethods = map[string]string{
+	token.PLUS:     "__add",
+	token.MINUS:    "__sub",
+	token.ASTERISK: "__mul",
+	token.SLASH:    "__div",
+	token.PERCENT:  "__mod",
+}
+
+/*
+Get a method of an instance that overloads built-in behavior, bound to the instance.
+
+Returns false if the object is not an instance or its struct does not define the method.
+*/
+func metamethod(obj object.Object, name string) (*object.Function, bool) {
+	instance, ok := obj.(*object.Instance)
+
+	if !ok {
+		return nil, false
+	}
+
+	method, ok := instance.Struct.Methods[name]
+
+	if !ok {
+		return nil, false
+	}
+
+	return bindMethod(instance, method), true
+}
+
+/*
+Get the `__eq` method of the left operand, or of the right operand when the left operand does not define it,
+and the other operand it is called with.
+
+Returns false if neither operand defines `__eq`.
+*/
+func equalityMetamethod(left, right object.Object) (*object.Function, object.Object, bool) {
+	if method, ok := metamethod(left, "__eq"); ok {
+		return method, right, true
+	}
+
+	if method, ok := metamethod(right, "__eq"); ok {
+		return method, left, true
+	}
+
+	return nil, nil, false
+}
+
+/*
+Check if two values are equal like == does, comparing instances inside of arrays and objects with their `__eq` method.
+
+Returns an error if an `__eq` method fails.
+*/
+func (e *Evaluator) equal(node ast.Node, left, right object.Object) (bool, object.Object) {
+	var err object.Object
+
+	result := object.EqualWith(left, right, func(a, b object.Object) (bool, bool) {
+		// After an error the result does not matter anymore
+		if err != nil {
+			return false, true
+		}
+
+		method, other, ok := equalityMetamethod(a, b)
+
+		if !ok {
+			return false, false
+		}
+
+		result := e.applyFunction(node, method, []object.Object{other})
+
+		if isError(result) {
+			err = result
+
+			return false, true
+		}
+
+		return isTruthy(result), true
+	})
+
+	return result, err
+}
+
+/*
+Evaluate an infix expression using the metamethods of the operands.
+
+Arithmetic operators call the metamethod of the left operand, e.g. `a + b` calls `a.__add(b)`.
+`==` and `!=` use `__eq` of either operand. `a > b` is evaluated as `b < a`, and `<=` and `>=` use `__le`
+or fall back to the negation of `__lt`.
+
+Returns false if none of the operands overload the operator.
+*/
+func (e *Evaluator) evalInfixMetamethod(node *ast.InfixExpression, left, right object.Object) (object.Object, bool) {
+	switch node.Operator {
+	case token.EQ, token.NOT_EQ:
+		method, other, ok := equalityMetamethod(left, right)
+
+		if !ok {
+			return nil, false
+		}
+
+		return e.compareWithMetamethod(node, method, other, node.Operator == token.NOT_EQ), true
+	case token.LT:
+		if method, ok := metamethod(left, "__lt"); ok {
+			return e.compareWithMetamethod(node, method, right, false), true
+		}
+	case token.GT:
+		if method, ok := metamethod(right, "__lt"); ok {
+			return e.compareWithMetamethod(node, method, left, false), true
+		}
+	case token.LTE:
+		if method, ok := metamethod(left, "__le"); ok {
+			return e.compareWithMetamethod(node, method, right, false), true
+		}
+
+		if method, ok := metamethod(right, "__lt"); ok {
+			return e.compareWithMetamethod(node, method, left, true), true
+		}
+	case token.GTE:
+		if method, ok := metamethod(right, "__le"); ok {
+			return e.compareWithMetamethod(node, method, left, false), true
+		}
+
+		if method, ok := metamethod(left, "__lt"); ok {
+			return e.compareWithMetamethod(node, method, right, true), true
+		}
+	default:
+		name, ok := arithmeticMetamethods[node.Operator]
+
+		if !ok {
+			return nil, false
+		}
+
+		if method, ok := metamethod(left, name); ok {
+			return e.applyFunction(node, method, []object.Object{right}), true
+		}
+	}
+
+	return nil, false
+}
+
+/*
+Call a comparison metamethod and convert its result to a boolean, negating it if needed
+*/
+func (e *Evaluator) compareWithMetamethod(node ast.Node, method *object.Function, other object.Object, negate bool) object.Object {
+	result := e.applyFunction(node, method, []object.Object{other})
+
+	if isError(result) {
+		return result
+	}
+
+	return e.nativeBoolToBooleanObject(isTruthy(result) != negate)
+}
+
+/*
+Get the string representation of an object, using the `__str` method of instances that define one,
+including instances inside of arrays, objects and the fields of other instances
+*/
+func (e *Evaluator) toString(node ast.Node, obj object.Object) object.Object {
+	var err object.Object
+
+	str := object.InspectWith(obj, func(value object.Object) (string, bool) {
+		// After an error the representation does not matter anymore
+		if err != nil {
+			return "", true
+		}
+
+		method, ok := metamethod(value, "__str")
+
+		if !ok {
+			return "", false
+		}
+
+		result := e.applyFunction(node, method, []object.Object{})
+
+		if isError(result) {
+			err = result
+
+			return "", true
+		}
+
+		if result.Type() != object.STRING_OBJ {
+			err = object.NewError(node, "__str of %s must return STRING, got %s", object.TypeName(value), object.TypeName(result))
+
+			return "", true
+		}
+
+		return result.(*object.String).Value, true
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return object.NewString(node, str)
+}
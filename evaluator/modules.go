@@ -0,0 +1,170 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/constants"
+	"github.com/iskandervdh/vorn/lexer"
+	"github.com/iskandervdh/vorn/object"
+	"github.com/iskandervdh/vorn/parser"
+	"github.com/iskandervdh/vorn/resolver"
+)
+
+/*
+Set the file that is being evaluated.
+
+Relative imports are resolved from the directory of this file.
+When no file is set, imports are resolved from the current working directory.
+*/
+func (e *Evaluator) SetFile(path string) {
+	absolutePath, err := filepath.Abs(path)
+
+	if err != nil { // coverage-ignore
+		absolutePath = path
+	}
+
+	e.importStack = []string{absolutePath}
+	e.file = absolutePath
+}
+
+/*
+Get the directory relative imports should be resolved from
+*/
+func (e *Evaluator) importDirectory() string {
+	if len(e.importStack) == 0 {
+		return "."
+	}
+
+	return filepath.Dir(e.importStack[len(e.importStack)-1])
+}
+
+/*
+Resolve the absolute path of an imported module
+*/
+func (e *Evaluator) resolveModulePath(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(e.importDirectory(), path)
+	}
+
+	return filepath.Abs(path)
+}
+
+func (e *Evaluator) evalImportStatement(is *ast.ImportStatement, env *object.Environment) object.Object {
+	if env.IsDefined(is.Name) {
+		return object.NewError(is.Name, "identifier already defined: %s", is.Name.Value)
+	}
+
+	path, err := e.resolveModulePath(is.Path.Value)
+
+	if err != nil { // coverage-ignore
+		return object.NewError(is.Path, "could not resolve module %q: %s", is.Path.Value, err)
+	}
+
+	module, ok := e.modules[path]
+
+	if !ok {
+		loaded := e.loadModule(is, path)
+
+		if isError(loaded) {
+			return loaded
+		}
+
+		module = loaded.(*object.Module)
+	}
+
+	env.Define(is.Name, module)
+
+	return nil
+}
+
+/*
+Parse and evaluate the module at the given path in its own environment.
+
+Modules are always run by the evaluator, also when the program that imports them is optimized or run by the vm.
+The module is cached by its path, so it is only evaluated once no matter how many times it is imported.
+*/
+func (e *Evaluator) loadModule(is *ast.ImportStatement, path string) object.Object {
+	for i, importing := range e.importStack {
+		if importing == path {
+			cycle := []string{}
+
+			for _, file := range append(e.importStack[i:], path) {
+				cycle = append(cycle, filepath.Base(file))
+			}
+
+			return object.NewError(is.Path, "circular import: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	source, err := os.ReadFile(path)
+
+	if err != nil {
+		return object.NewError(is.Path, "could not read module %q: %s", is.Path.Value, err)
+	}
+
+	p := parser.New(lexer.New(string(source)), constants.TRACE)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		return object.NewError(is.Path, "could not parse module %q: %s", is.Path.Value, strings.Join(p.Errors(), ", "))
+	}
+
+	if errors := resolver.New(e).Resolve(program); len(errors) != 0 {
+		messages := []string{}
+
+		for _, err := range errors {
+			messages = append(messages, err.Message)
+		}
+
+		return object.NewError(is.Path, "could not resolve module %q: %s", is.Path.Value, strings.Join(messages, ", "))
+	}
+
+	env := object.NewEnvironment()
+
+	// The import is on the call stack while the module runs, so errors of the module show where it was imported
+	if err := e.pushCall(is, "<module>", path); err != nil {
+		return err
+	}
+
+	e.importStack = append(e.importStack, path)
+	evaluated := e.recordCallStack(e.Eval(program, env))
+	e.importStack = e.importStack[:len(e.importStack)-1]
+	e.popCall()
+
+	if isError(evaluated) {
+		return evaluated
+	}
+
+	exports := []string{}
+
+	for _, statement := range program.Statements {
+		if exportStatement, ok := statement.(*ast.ExportStatement); ok {
+			for _, name := range exportStatement.Names() {
+				exports = append(exports, name.Value)
+			}
+		}
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	module := object.NewModule(is, name, path, env, exports)
+
+	e.modules[path] = module
+
+	return module
+}
+
+/*
+Get an exported member of a module
+*/
+func (e *Evaluator) moduleMember(module *object.Module, name *ast.Identifier) object.Object {
+	member, ok := module.Get(name.Value)
+
+	if !ok {
+		return object.NewError(name, "module %s has no exported member %s", module.Name, name.Value)
+	}
+
+	return member
+}
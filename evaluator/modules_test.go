@@ -0,0 +1,177 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iskandervdh/vorn/lexer"
+	"github.com/iskandervdh/vorn/object"
+	"github.com/iskandervdh/vorn/parser"
+)
+
+func writeModuleFiles(t *testing.T, files map[string]string) string {
+	directory := t.TempDir()
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(directory, name), []byte(content), 0644); err != nil {
+			t.Fatalf("could not write module file %s: %s", name, err)
+		}
+	}
+
+	return directory
+}
+
+func testEvalFile(t *testing.T, engine string, directory string, input string) object.Object {
+	l := lexer.New(input)
+	p := parser.New(l, false)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+
+	e := New()
+	e.SetFile(filepath.Join(directory, "main.vorn"))
+
+	return run(engine, e, program, object.NewEnvironment())
+}
+
+func TestImport(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		directory := writeModuleFiles(t, map[string]string{
+			"utils.vorn": `
+export const PI = 3;
+export func double(x) { return x * 2; }
+func hidden() { return 1; }
+export let counter = 0;
+export func increment() { counter += 1; return counter; }
+`,
+			"broken.vorn":  `let x = ;`,
+			"failing.vorn": `1 + "";`,
+			"unknown.vorn": `export let x = y;`,
+			"a.vorn":       `import "./b.vorn" as b;`,
+			"b.vorn":       `import "./a.vorn" as a;`,
+		})
+
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{`import "./utils.vorn" as utils; utils.PI;`, 3},
+			{`import "./utils.vorn" as utils; utils.double(21);`, 42},
+			{`import "utils.vorn" as utils; utils.double(utils.PI);`, 6},
+			{`import "./utils.vorn" as a; import "./utils.vorn" as b; a.increment(); b.increment(); a.counter;`, 2},
+			{`import "./utils.vorn" as utils; utils.hidden();`, "[1:40] module utils has no exported member hidden"},
+			{`import "./utils.vorn" as utils; utils.missing;`, "[1:40] module utils has no exported member missing"},
+			{`import "./utils.vorn" as utils; import "./utils.vorn" as utils;`, "[1:59] identifier already defined: utils"},
+			{`import "./utils.vorn" as utils; utils = 1;`, "[1:40] can not reassign import utils."},
+			{`import "./utils.vorn" as utils; let utils = 1;`, "identifier already defined: utils"},
+			{`import "./missing.vorn" as missing;`, "could not read module"},
+			{`import "./broken.vorn" as broken;`, "could not parse module"},
+			{`import "./failing.vorn" as failing;`, "[1:4] type mismatch: INTEGER + STRING"},
+			{`import "./unknown.vorn" as unknown;`, `could not resolve module "./unknown.vorn": [1:17] identifier not found: y`},
+			{`import "./a.vorn" as a;`, "[1:9] circular import: a.vorn -> b.vorn -> a.vorn"},
+		}
+
+		for _, test := range tests {
+			evaluated := testEvalFile(t, engine, directory, test.input)
+
+			switch expected := test.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case string:
+				errorObject, ok := evaluated.(*object.Error)
+
+				if !ok {
+					t.Errorf("object is not Error. got %T (%+v)", evaluated, evaluated)
+					continue
+				}
+
+				if !strings.Contains(errorObject.Message, expected) {
+					t.Errorf("wrong error message. expected %q, got %q", expected, errorObject.Message)
+				}
+			}
+		}
+	})
+}
+
+func TestModuleIsEvaluatedOnce(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		directory := writeModuleFiles(t, map[string]string{
+			"counter.vorn": `export let count = 0; count += 1;`,
+		})
+
+		evaluated := testEvalFile(t, engine, directory, `
+import "./counter.vorn" as a;
+import "./counter.vorn" as b;
+a.count + b.count;`)
+
+		testIntegerObject(t, evaluated, 2)
+	})
+}
+
+func TestModuleObject(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		directory := writeModuleFiles(t, map[string]string{
+			"utils.vorn": `export const X = 1;`,
+		})
+
+		evaluated := testEvalFile(t, engine, directory, `import "./utils.vorn" as utils; utils;`)
+		module, ok := evaluated.(*object.Module)
+
+		if !ok {
+			t.Fatalf("object is not Module. got %T (%+v)", evaluated, evaluated)
+		}
+
+		if module.Name != "utils" {
+			t.Errorf("module has wrong name. got %q", module.Name)
+		}
+
+		if module.Path != filepath.Join(directory, "utils.vorn") {
+			t.Errorf("module has wrong path. got %q", module.Path)
+		}
+	})
+}
+
+func TestModuleTraceback(t *testing.T) {
+	testEngines(t, func(t *testing.T, engine string) {
+		directory := writeModuleFiles(t, map[string]string{
+			"util.vorn": "export func helper(x) {\n  return x / 0;\n}",
+			"bad.vorn":  "export let x = 1;\nlet y = x + true;",
+		})
+
+		tests := []struct {
+			input    string
+			expected string
+		}{
+			{
+				"import \"./util.vorn\" as util;\nutil.helper(1);",
+				"Traceback (most recent call last):\n" +
+					"  File \"main.vorn\", line 2, column 13, in <program>\n" +
+					"  File \"util.vorn\", line 2, column 13, in helper\n",
+			},
+			{
+				"import \"./bad.vorn\" as bad;",
+				"Traceback (most recent call last):\n" +
+					"  File \"main.vorn\", line 1, column 2, in <program>\n" +
+					"  File \"bad.vorn\", line 2, column 12, in <module>\n",
+			},
+		}
+
+		for _, tt := range tests {
+			evaluated := testEvalFile(t, engine, directory, tt.input)
+			err, ok := evaluated.(*object.Error)
+
+			if !ok {
+				t.Errorf("object is not Error for %q. got %T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+
+			if err.Traceback() != tt.expected {
+				t.Errorf("wrong traceback for %q. got\n%s\nwant\n%s", tt.input, err.Traceback(), tt.expected)
+			}
+		}
+	})
+}
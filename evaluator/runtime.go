@@ -0,0 +1,174 @@
+package evaluator
+
+import (
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/object"
+)
+
+// The methods in this file let the virtual machine in the vm package share the semantics of the evaluator.
+// They work on values that are already evaluated, so the vm only has to evaluate the operands itself.
+
+/*
+Get the builtin function with the given name.
+
+Returns the builtin and a boolean indicating if it exists.
+*/
+func (e *Evaluator) Builtin(name string) (*object.Builtin, bool) {
+	builtin, ok := e.builtins[name]
+
+	return builtin, ok
+}
+
+/*
+Apply the operator of a prefix expression to its evaluated operand
+*/
+func (e *Evaluator) Prefix(node *ast.PrefixExpression, right object.Object) object.Object {
+	return e.evalPrefixExpression(node, right)
+}
+
+/*
+Apply the operator of an infix expression to its evaluated operands, except for the logical && and ||
+*/
+func (e *Evaluator) Infix(node *ast.InfixExpression, left, right object.Object) object.Object {
+	return e.evalInfixExpression(node, left, right)
+}
+
+/*
+Enter a call of a function made at node, adding it to the call stack.
+
+Returns an error at node when the call would exceed the maximum call depth.
+Every call that was entered has to be left with LeaveCall when it returns.
+*/
+func (e *Evaluator) EnterCall(node ast.Node, function object.Object) *object.Error {
+	return e.pushCall(node, callName(function), e.callFile(function))
+}
+
+/*
+Leave the call that was entered last
+*/
+func (e *Evaluator) LeaveCall() {
+	e.popCall()
+}
+
+/*
+Record the calls that are running in an error, unless the error already has a call stack
+*/
+func (e *Evaluator) RecordCallStack(err *object.Error) {
+	e.recordCallStack(err)
+}
+
+/*
+Get the element at index of an array, object or struct instance
+*/
+func (e *Evaluator) Index(node *ast.IndexExpression, left, index object.Object) object.Object {
+	return e.evalIndexExpression(node, left, index)
+}
+
+/*
+Call a function, builtin or struct with the given arguments
+*/
+func (e *Evaluator) Call(node ast.Node, function object.Object, args []object.Object) object.Object {
+	return e.applyFunction(node, function, args)
+}
+
+/*
+Create the environment a function is called in, binding the given arguments to the argument names of the function
+*/
+func (e *Evaluator) ExtendFunctionEnv(node ast.Node, function *object.Function, args []object.Object) (*object.Environment, *object.Error) {
+	return e.extendFunctionEnv(node, function, args)
+}
+
+/*
+Call the method of a chaining call like left.method(args)
+*/
+func (e *Evaluator) ChainingCall(node *ast.CallExpression, left object.Object, args []object.Object) object.Object {
+	return e.callChainingFunction(node, left, args)
+}
+
+/*
+Get the function a chaining call like left.method(args) calls, if it calls a function instead of a built-in method
+*/
+func (e *Evaluator) ChainingMethod(node *ast.CallExpression, left object.Object) (*object.Function, bool) {
+	return e.chainingMethod(node, left)
+}
+
+/*
+Get the property of a chaining expression like left.name
+*/
+func (e *Evaluator) ChainingProperty(node *ast.Identifier, left object.Object) object.Object {
+	return e.chainingProperty(node, left)
+}
+
+/*
+Get the value of a variable after applying the ++ or -- operator of node to its current value
+*/
+func (e *Evaluator) IncrementDecrement(node *ast.IncrementDecrementExpression, current object.Object) object.Object {
+	return incrementDecrement(node, node.Token, current)
+}
+
+/*
+Assign a value to an array element, object property or field of an instance, like container[key] = value
+*/
+func (e *Evaluator) AssignMember(node *ast.MemberReassignmentExpression, container, key, value object.Object) *object.Error {
+	return e.assignMember(node, container, key, value)
+}
+
+/*
+Apply the ++ or -- operator to an array element, object property or field of an instance, like container[key]++
+*/
+func (e *Evaluator) IncrementDecrementMember(node *ast.MemberIncrementDecrementExpression, container, key object.Object) object.Object {
+	return e.incrementDecrementMember(node, container, key)
+}
+
+/*
+Start iterating over the evaluated iterable of a for-in loop.
+
+Returns a function that returns the next key and value each time it is called, until there are no elements left.
+*/
+func (e *Evaluator) Iterator(node *ast.ForInStatement, iterable object.Object) (func() (object.Object, object.Object, bool), *object.Error) {
+	return e.iterator(node, iterable)
+}
+
+/*
+Start iterating over the numbers of a call to the range builtin with the given arguments, without creating the array of numbers
+*/
+func (e *Evaluator) RangeIterator(node *ast.CallExpression, args []object.Object) (func() (object.Object, object.Object, bool), *object.Error) {
+	return rangeIterator(node, args)
+}
+
+/*
+Create the error a throw statement throws with the evaluated value
+*/
+func (e *Evaluator) Throw(node *ast.ThrowStatement, value object.Object, env *object.Environment) *object.Error {
+	return throw(node, value, env)
+}
+
+/*
+Convert a caught error into the value that is bound to the parameter of a catch block with the environment env
+*/
+func (e *Evaluator) CaughtError(node *ast.Identifier, err *object.Error, env *object.Environment) *object.Hash {
+	return e.caughtErrorObject(node, err, env)
+}
+
+/*
+Let the vm run the functions it compiled when they are called by the evaluator, like the callbacks of builtins and chaining functions.
+
+With a nil function the evaluator evaluates the bodies of compiled functions itself.
+*/
+func (e *Evaluator) SetCompiledCall(call func(node ast.Node, function *object.Function, args []object.Object) object.Object) {
+	e.callCompiled = call
+}
+
+/*
+Convert a value to the string it has in a template literal or when it is printed, using the __str method of instances
+*/
+func (e *Evaluator) ToString(node ast.Node, value object.Object) object.Object {
+	return e.toString(node, value)
+}
+
+/*
+Get the file of the code that is running, empty when no file is set
+*/
+func (e *Evaluator) File() string {
+	return e.file
+}
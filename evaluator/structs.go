@@ -0,0 +1,166 @@
+package evaluator
+
+import (
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/object"
+)
+
+func (e *Evaluator) evalStructStatement(node *ast.StructStatement, env *object.Environment) object.Object {
+	if env.IsDefined(node.Name) {
+		return object.NewError(node, "identifier already defined: %s", node.Name.Value)
+	}
+
+	fields := []string{}
+
+	for _, field := range node.Fields {
+		fields = append(fields, field.Value)
+	}
+
+	methods := map[string]*object.Function{}
+
+	for _, method := range node.Methods {
+		methods[method.Name.Value] = e.functionFromStatement(method, node.Name.Value+"."+method.Name.Value, env)
+	}
+
+	env.Define(node.Name, object.NewStruct(node, node.Name.Value, fields, node.Defaults, methods, env))
+
+	return nil
+}
+
+/*
+Create a new instance of a struct by calling it.
+
+Without an init method the arguments are assigned to the fields in the order they are defined in,
+fields that are left out get their default value. With an init method all fields start out with
+their default value, or null, and the arguments are passed to init instead.
+*/
+func (e *Evaluator) instantiate(node ast.Node, s *object.Struct, args []object.Object) object.Object {
+	init, hasInit := s.Methods["init"]
+
+	if !hasInit {
+		if err := checkArgumentsRange(node, s.Name, len(args), len(s.Fields)-len(s.Defaults), len(s.Fields)); err != nil {
+			return err
+		}
+	}
+
+	instance := object.NewInstance(node, s, map[string]object.Object{})
+
+	for i, field := range s.Fields {
+		if !hasInit && i < len(args) {
+			instance.Fields[field] = args[i]
+			continue
+		}
+
+		defaultValue, ok := s.Defaults[field]
+
+		if !ok {
+			instance.Fields[field] = object.NULL
+			continue
+		}
+
+		value := e.Eval(defaultValue, s.Env)
+
+		if isError(value) {
+			return value
+		}
+
+		instance.Fields[field] = value
+	}
+
+	if hasInit {
+		result := e.applyFunction(node, bindMethod(instance, init), args)
+
+		if isError(result) {
+			return result
+		}
+	}
+
+	return instance
+}
+
+// The self variable of a method, which is the only variable in the frame that encloses the method
+var selfIdentifier = &ast.Identifier{Value: "self", Local: true}
+
+/*
+Bind a method to an instance, making the instance available as `self` inside the method
+*/
+func bindMethod(instance *object.Instance, method *object.Function) *object.Function {
+	env := object.NewFrame(method.Env, 1)
+	env.Define(selfIdentifier, instance)
+
+	bound := object.Clone(method.Node(), method).(*object.Function)
+	bound.Env = env
+
+	return bound
+}
+
+/*
+Get a field or bound method of an instance, used for dot property access like point.x
+*/
+func (e *Evaluator) instanceMember(instance *object.Instance, name *ast.Identifier) object.Object {
+	if value, ok := instance.Fields[name.Value]; ok {
+		return value
+	}
+
+	if method, ok := instance.Struct.Methods[name.Value]; ok {
+		return bindMethod(instance, method)
+	}
+
+	return object.NewError(name, "%s has no field %s", instance.Struct.Name, name.Value)
+}
+
+/*
+Call a method of an instance. Functions stored in a field can be called like methods as well.
+*/
+func (e *Evaluator) callInstanceMethod(instance *object.Instance, call *ast.CallExpression, args []object.Object) object.Object {
+	name := call.Function.TokenLiteral()
+
+	if method, ok := instance.Struct.Methods[name]; ok {
+		return e.applyFunction(call, bindMethod(instance, method), args)
+	}
+
+	if value, ok := instance.Fields[name]; ok {
+		if value.Type() != object.FUNCTION_OBJ && value.Type() != object.BUILTIN_OBJ {
+			return object.NewError(call.Function, "not a function: %s", object.TypeName(value))
+		}
+
+		return e.applyFunction(call, value, args)
+	}
+
+	return object.NewError(call.Function, "%s has no method %s", instance.Struct.Name, name)
+}
+
+/*
+Evaluate an index expression on an instance, which calls its `__index` method if the struct defines one
+and gets the field named by the index otherwise
+*/
+func (e *Evaluator) evalInstanceIndexExpression(node *ast.IndexExpression, instance *object.Instance, index object.Object) object.Object {
+	if method, ok := metamethod(instance, "__index"); ok {
+		return e.applyFunction(node, method, []object.Object{index})
+	}
+
+	name, err := instanceFieldName(node, instance, index)
+
+	if err != nil {
+		return err
+	}
+
+	return instance.Fields[name]
+}
+
+/*
+Get the name of the field of an instance a key refers to, which has to be a string naming an existing field
+*/
+func instanceFieldName(node ast.Node, instance *object.Instance, key object.Object) (string, *object.Error) {
+	name, ok := key.(*object.String)
+
+	if !ok {
+		return "", object.NewError(node, "field name must be STRING, got %s", object.TypeName(key))
+	}
+
+	if _, ok := instance.Fields[name.Value]; !ok {
+		return "", object.NewError(node, "%s has no field %s", instance.Struct.Name, name.Value)
+	}
+
+	return name.Value, nil
+}
@@ -1,6 +1,11 @@
 package lexer
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/iskandervdh/vorn/token"
 )
 
@@ -14,6 +19,10 @@ type Lexer struct {
 	column int // current column number
 
 	forLoopParentheses int // count of parentheses in a for loop definition
+
+	// Count of open braces for each string interpolation that is being lexed,
+	// used to find the } that ends the interpolation
+	interpolationBraces []int
 }
 
 func New(input string) *Lexer {
@@ -48,6 +57,17 @@ func (l *Lexer) NextToken() token.Token {
 				Line:    l.line,
 				Column:  l.column,
 			}
+		} else if l.peekChar() == '>' {
+			ch := l.char
+			l.readChar()
+			literal := string(ch) + string(l.char)
+
+			t = token.Token{
+				Type:    token.ARROW,
+				Literal: literal,
+				Line:    l.line,
+				Column:  l.column,
+			}
 		} else {
 			t = token.New(token.ASSIGN, l.char, l.line, l.column)
 		}
@@ -247,13 +267,45 @@ func (l *Lexer) NextToken() token.Token {
 		t = token.New(token.SEMICOLON, l.char, l.line, l.column)
 	case ':':
 		t = token.New(token.COLON, l.char, l.line, l.column)
+	case '?':
+		t = token.New(token.QUESTION, l.char, l.line, l.column)
 	case '.':
-		t = token.New(token.DOT, l.char, l.line, l.column)
+		if l.peekChar() == '.' && l.readPosition+1 < len(l.input) && l.input[l.readPosition+1] == '.' {
+			l.readChar()
+			l.readChar()
+
+			t = token.Token{
+				Type:    token.ELLIPSIS,
+				Literal: "...",
+				Line:    l.line,
+				Column:  l.column,
+			}
+		} else {
+			t = token.New(token.DOT, l.char, l.line, l.column)
+		}
 	case ',':
 		t = token.New(token.COMMA, l.char, l.line, l.column)
 	case '{':
+		if len(l.interpolationBraces) > 0 {
+			l.interpolationBraces[len(l.interpolationBraces)-1] += 1
+		}
+
 		t = token.New(token.LBRACE, l.char, l.line, l.column)
 	case '}':
+		if len(l.interpolationBraces) > 0 {
+			last := len(l.interpolationBraces) - 1
+
+			// The } that ends an interpolation continues the string it is part of
+			if l.interpolationBraces[last] == 0 {
+				l.interpolationBraces = l.interpolationBraces[:last]
+				t = l.readString(false)
+
+				break
+			}
+
+			l.interpolationBraces[last] -= 1
+		}
+
 		t = token.New(token.RBRACE, l.char, l.line, l.column)
 	case '(':
 		// If we're in a for loop definition, increment the for loop parentheses
@@ -277,10 +329,9 @@ func (l *Lexer) NextToken() token.Token {
 
 		t = token.New(token.RPAREN, l.char, l.line, l.column)
 	case '"':
-		t.Line = l.line
-		t.Column = l.column
-		t.Type = token.STRING
-		t.Literal = l.readString()
+		t = l.readString(true)
+	case '`':
+		t = l.readRawString()
 	case '[':
 		t = token.New(token.LBRACKET, l.char, l.line, l.column)
 	case ']':
@@ -373,11 +424,7 @@ func (l *Lexer) NextToken() token.Token {
 
 			return t
 		} else if isDigit(l.char) {
-			t.Line = l.line
-			t.Column = l.column
-			t.Literal, t.Type = l.readNumber()
-
-			return t
+			return l.readNumber()
 		} else {
 			t = token.New(token.ILLEGAL, l.char, l.line, l.column)
 		}
@@ -463,33 +510,320 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readNumber() (string, token.TokenType) {
+/*
+Read an integer or float literal.
+
+Supports hexadecimal (0xFF), binary (0b1010) and octal (0o755) integers,
+floats with a fraction and/or exponent (1.5, 1e9, 2.5E-3) and underscores
+between digits (1_000_000).
+
+Returns an ILLEGAL token with a description of the problem as its literal if the number is malformed.
+*/
+func (l *Lexer) readNumber() token.Token {
+	t := token.Token{Type: token.INT, Line: l.line, Column: l.column}
 	position := l.position
-	tokenType := token.TokenType(token.INT)
+	isNumberDigit := isDigit
+
+	if base, ok := numberBases[l.peekChar()]; l.char == '0' && ok {
+		l.readChar()
+		l.readChar()
+
+		isNumberDigit = base.isDigit
+		digits := l.position
+		l.readDigits(base.isDigit)
+
+		if isLetter(l.char) || isDigit(l.char) {
+			invalid := l.char
+			l.readMalformedNumber()
+
+			return l.illegalNumber(t, fmt.Sprintf("invalid digit %q in %s literal %s", invalid, base.name, l.input[position:l.position]))
+		}
+
+		if l.position == digits {
+			return l.illegalNumber(t, fmt.Sprintf("%s literal %s has no digits", base.name, l.input[position:l.position]))
+		}
+	} else {
+		l.readDigits(isDigit)
 
-	for isDigit(l.char) || l.char == '.' {
 		if l.char == '.' {
-			tokenType = token.FLOAT
+			t.Type = token.FLOAT
+
+			l.readChar()
+			l.readDigits(isDigit)
+		}
+
+		if l.char == 'e' || l.char == 'E' {
+			t.Type = token.FLOAT
+
+			l.readChar()
+
+			if l.char == '+' || l.char == '-' {
+				l.readChar()
+			}
+
+			exponent := l.position
+			l.readDigits(isDigit)
+
+			if l.position == exponent {
+				l.readMalformedNumber()
+
+				return l.illegalNumber(t, fmt.Sprintf("exponent has no digits in %s", l.input[position:l.position]))
+			}
+		}
+	}
+
+	if isLetter(l.char) || isDigit(l.char) || l.char == '.' {
+		l.readMalformedNumber()
+
+		return l.illegalNumber(t, fmt.Sprintf("malformed number %s", l.input[position:l.position]))
+	}
+
+	t.Literal = l.input[position:l.position]
+
+	if !hasValidUnderscores(t.Literal, isNumberDigit) {
+		return l.illegalNumber(t, fmt.Sprintf("'_' must separate successive digits in %s", t.Literal))
+	}
+
+	return t
+}
+
+type numberBase struct {
+	name    string
+	isDigit func(byte) bool
+}
+
+/*
+The number bases that can be used with a 0x, 0b or 0o prefix
+*/
+var numberBases = map[byte]numberBase{
+	'x': {"hexadecimal", isHexDigit},
+	'X': {"hexadecimal", isHexDigit},
+	'b': {"binary", isBinaryDigit},
+	'B': {"binary", isBinaryDigit},
+	'o': {"octal", isOctalDigit},
+	'O': {"octal", isOctalDigit},
+}
+
+/*
+Read digits that are valid according to isDigit and the underscores between them
+*/
+func (l *Lexer) readDigits(isDigit func(byte) bool) {
+	for isDigit(l.char) || l.char == '_' {
+		l.readChar()
+	}
+}
+
+/*
+Skip the rest of a malformed number so lexing can continue after it
+*/
+func (l *Lexer) readMalformedNumber() {
+	for isLetter(l.char) || isDigit(l.char) || l.char == '.' {
+		l.readChar()
+	}
+}
+
+func (l *Lexer) illegalNumber(t token.Token, message string) token.Token {
+	t.Type = token.ILLEGAL
+	t.Literal = message
+
+	return t
+}
+
+/*
+Check if every underscore in a number literal is between two digits
+*/
+func hasValidUnderscores(literal string, isDigit func(byte) bool) bool {
+	for i := 0; i < len(literal); i++ {
+		if literal[i] != '_' {
+			continue
+		}
+
+		if i == 0 || i == len(literal)-1 || !isDigit(literal[i-1]) || !isDigit(literal[i+1]) {
+			return false
 		}
+	}
+
+	return true
+}
+
+/*
+Read a double quoted string and decode its escape sequences.
+
+If the string contains an interpolation like ${name} the part of the string before it
+is returned as a TEMPLATE_START token, after which the lexer returns the tokens of the
+interpolated expression. The closing } of the interpolation continues reading the string
+(start is false) returning either a TEMPLATE_MIDDLE or TEMPLATE_END token.
 
+Returns an ILLEGAL token with a description of the problem as its literal
+if the string contains an invalid escape sequence or is never terminated.
+*/
+func (l *Lexer) readString(start bool) token.Token {
+	t := token.Token{Type: token.STRING, Line: l.line, Column: l.column}
+
+	if !start {
+		t.Type = token.TEMPLATE_END
+	}
+
+	var out strings.Builder
+	var illegal *token.Token
+
+	for {
 		l.readChar()
+
+		switch l.char {
+		case 0:
+			return token.Token{Type: token.ILLEGAL, Literal: "unterminated string", Line: t.Line, Column: t.Column}
+		case '"':
+			if illegal != nil {
+				return *illegal
+			}
+
+			t.Literal = out.String()
+
+			return t
+		case '$':
+			if l.peekChar() != '{' {
+				out.WriteByte(l.char)
+
+				continue
+			}
+
+			if illegal != nil {
+				return *illegal
+			}
+
+			// Move on to the {, which is skipped after returning the token
+			l.readChar()
+			l.interpolationBraces = append(l.interpolationBraces, 0)
+
+			if start {
+				t.Type = token.TEMPLATE_START
+			} else {
+				t.Type = token.TEMPLATE_MIDDLE
+			}
+
+			t.Literal = out.String()
+
+			return t
+		case '\\':
+			line, column := l.line, l.column
+			decoded, err := l.readEscapeSequence()
+
+			// Only report the first invalid escape sequence, but keep reading until the end of the string
+			if err != "" && illegal == nil {
+				illegal = &token.Token{Type: token.ILLEGAL, Literal: err, Line: line, Column: column}
+			}
+
+			out.WriteString(decoded)
+		case '\n':
+			l.line += 1
+			l.column = 1
+
+			out.WriteByte(l.char)
+		default:
+			out.WriteByte(l.char)
+		}
 	}
+}
 
-	return l.input[position:l.position], tokenType
+/*
+Decode the escape sequence starting at the current backslash.
+
+Returns the decoded string, or a description of the error if the escape sequence is invalid.
+*/
+func (l *Lexer) readEscapeSequence() (string, string) {
+	l.readChar()
+
+	switch l.char {
+	case 'n':
+		return "\n", ""
+	case 't':
+		return "\t", ""
+	case 'r':
+		return "\r", ""
+	case '0':
+		return "\x00", ""
+	case '"':
+		return "\"", ""
+	case '$':
+		return "$", ""
+	case '\\':
+		return "\\", ""
+	case 'u':
+		return l.readUnicodeEscapeSequence()
+	case 0:
+		// Let readString report the unterminated string
+		return "", ""
+	case '\n':
+		l.line += 1
+		l.column = 1
+	}
+
+	return "", fmt.Sprintf("invalid escape sequence \\%c", l.char)
+}
+
+/*
+Decode a unicode escape sequence of the form \u{1F600} containing 1 to 6 hexadecimal digits.
+*/
+func (l *Lexer) readUnicodeEscapeSequence() (string, string) {
+	if l.peekChar() != '{' {
+		return "", "invalid unicode escape sequence, expected \\u{...}"
+	}
+
+	l.readChar()
+
+	position := l.position + 1
+
+	for isHexDigit(l.peekChar()) {
+		l.readChar()
+	}
+
+	digits := l.input[position : l.position+1]
+
+	if l.peekChar() != '}' || len(digits) == 0 || len(digits) > 6 {
+		return "", fmt.Sprintf("invalid unicode escape sequence \\u{%s", digits)
+	}
+
+	l.readChar()
+
+	codePoint, _ := strconv.ParseInt(digits, 16, 32)
+
+	if !utf8.ValidRune(rune(codePoint)) {
+		return "", fmt.Sprintf("invalid unicode code point \\u{%s}", digits)
+	}
+
+	return string(rune(codePoint)), ""
 }
 
-func (l *Lexer) readString() string {
+/*
+Read a backtick delimited raw string.
+
+Raw strings can span multiple lines and do not support escape sequences.
+*/
+func (l *Lexer) readRawString() token.Token {
+	t := token.Token{Type: token.STRING, Line: l.line, Column: l.column}
 	position := l.position + 1
 
 	for {
 		l.readChar()
 
-		if l.char == '"' || l.char == 0 {
+		if l.char == '`' {
 			break
 		}
+
+		if l.char == 0 {
+			return token.Token{Type: token.ILLEGAL, Literal: "unterminated raw string", Line: t.Line, Column: t.Column}
+		}
+
+		if l.char == '\n' {
+			l.line += 1
+			l.column = 1
+		}
 	}
 
-	return l.input[position:l.position]
+	t.Literal = l.input[position:l.position]
+
+	return t
 }
 
 func isLetter(ch byte) bool {
@@ -499,3 +833,15 @@ func isLetter(ch byte) bool {
 func isDigit(ch byte) bool {
 	return '0' <= ch && ch <= '9'
 }
+
+func isHexDigit(ch byte) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+func isBinaryDigit(ch byte) bool {
+	return ch == '0' || ch == '1'
+}
+
+func isOctalDigit(ch byte) bool {
+	return '0' <= ch && ch <= '7'
+}
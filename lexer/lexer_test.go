@@ -332,6 +332,52 @@ func TestForLoop(t *testing.T) {
 	}
 }
 
+func TestForInLoop(t *testing.T) {
+	input := `for (i, x in range(len(arr))) { print(x); }`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FOR, "for"},
+		{token.LPAREN, "("},
+		{token.IDENT, "i"},
+		{token.COMMA, ","},
+		{token.IDENT, "x"},
+		{token.IN, "in"},
+		{token.IDENT, "range"},
+		{token.LPAREN, "("},
+		{token.IDENT, "len"},
+		{token.LPAREN, "("},
+		{token.IDENT, "arr"},
+		{token.RPAREN, ")"},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, ";"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "print"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, ";"},
+		{token.RBRACE, "}"},
+	}
+
+	l := New(input)
+
+	for i, test := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - token type wrong. expected %q, got %q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected %q, got %q", i, test.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
 func TestIncrementDecrement(t *testing.T) {
 	input := `i++;
 i--;
@@ -455,3 +501,361 @@ i >>= 2;`
 		t.Fatalf("expected EOF, got %q", tok.Type)
 	}
 }
+
+func TestTernary(t *testing.T) {
+	input := `a ? b : c;`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.QUESTION, "?"},
+		{token.IDENT, "b"},
+		{token.COLON, ":"},
+		{token.IDENT, "c"},
+		{token.SEMICOLON, ";"},
+	}
+
+	l := New(input)
+	for i, test := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - token type wrong. expected %q, got %q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected %q, got %q", i, test.expectedLiteral, tok.Literal)
+		}
+	}
+
+	if tok := l.NextToken(); tok.Type != token.EOF {
+		t.Fatalf("expected EOF, got %q", tok.Type)
+	}
+}
+
+func TestEllipsis(t *testing.T) {
+	input := `func(a, ...rest) {}; a.b`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FUNCTION, "func"},
+		{token.LPAREN, "("},
+		{token.IDENT, "a"},
+		{token.COMMA, ","},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.RBRACE, "}"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.DOT, "."},
+		{token.IDENT, "b"},
+	}
+
+	l := New(input)
+	for i, test := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - token type wrong. expected %q, got %q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected %q, got %q", i, test.expectedLiteral, tok.Literal)
+		}
+	}
+
+	if tok := l.NextToken(); tok.Type != token.EOF {
+		t.Fatalf("expected EOF, got %q", tok.Type)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	input := `match x { 1 => a, _ => b == c }`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.MATCH, "match"},
+		{token.IDENT, "x"},
+		{token.LBRACE, "{"},
+		{token.INT, "1"},
+		{token.ARROW, "=>"},
+		{token.IDENT, "a"},
+		{token.COMMA, ","},
+		{token.IDENT, "_"},
+		{token.ARROW, "=>"},
+		{token.IDENT, "b"},
+		{token.EQ, "=="},
+		{token.IDENT, "c"},
+		{token.RBRACE, "}"},
+	}
+
+	l := New(input)
+	for i, test := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - token type wrong. expected %q, got %q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected %q, got %q", i, test.expectedLiteral, tok.Literal)
+		}
+	}
+
+	if tok := l.NextToken(); tok.Type != token.EOF {
+		t.Fatalf("expected EOF, got %q", tok.Type)
+	}
+}
+
+func TestStruct(t *testing.T) {
+	input := `struct Point { x, y = 0; }`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.STRUCT, "struct"},
+		{token.IDENT, "Point"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "x"},
+		{token.COMMA, ","},
+		{token.IDENT, "y"},
+		{token.ASSIGN, "="},
+		{token.INT, "0"},
+		{token.SEMICOLON, ";"},
+		{token.RBRACE, "}"},
+	}
+
+	l := New(input)
+	for i, test := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - token type wrong. expected %q, got %q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected %q, got %q", i, test.expectedLiteral, tok.Literal)
+		}
+	}
+
+	if tok := l.NextToken(); tok.Type != token.EOF {
+		t.Fatalf("expected EOF, got %q", tok.Type)
+	}
+}
+
+func TestStringEscapeSequences(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{`"hello"`, token.STRING, "hello"},
+		{`"a\nb"`, token.STRING, "a\nb"},
+		{`"a\tb\rc"`, token.STRING, "a\tb\rc"},
+		{`"say \"hi\""`, token.STRING, `say "hi"`},
+		{`"back\\slash"`, token.STRING, `back\slash`},
+		{`"nul\0"`, token.STRING, "nul\x00"},
+		{`"\u{1F600}"`, token.STRING, "😀"},
+		{`"\u{e9}t\u{E9}"`, token.STRING, "été"},
+		{`"\q"`, token.ILLEGAL, `invalid escape sequence \q`},
+		{`"ok \x then \q"`, token.ILLEGAL, `invalid escape sequence \x`},
+		{`"\u1F600"`, token.ILLEGAL, `invalid unicode escape sequence, expected \u{...}`},
+		{`"\u{}"`, token.ILLEGAL, `invalid unicode escape sequence \u{`},
+		{`"\u{1F600"`, token.ILLEGAL, `invalid unicode escape sequence \u{1F600`},
+		{`"\u{1234567}"`, token.ILLEGAL, `invalid unicode escape sequence \u{1234567`},
+		{`"\u{D800}"`, token.ILLEGAL, `invalid unicode code point \u{D800}`},
+		{`"unterminated`, token.ILLEGAL, "unterminated string"},
+		{`"unterminated\"`, token.ILLEGAL, "unterminated string"},
+		{`"unterminated\`, token.ILLEGAL, "unterminated string"},
+	}
+
+	for i, test := range tests {
+		l := New(test.input)
+		tok := l.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - token type wrong. expected %q, got %q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected %q, got %q", i, test.expectedLiteral, tok.Literal)
+		}
+
+		if tok := l.NextToken(); tok.Type != token.EOF {
+			t.Fatalf("tests[%d] - expected EOF, got %q", i, tok.Type)
+		}
+	}
+}
+
+func TestRawString(t *testing.T) {
+	input := "let s = `first \\n\n\"second\"`;\nlet x = 1;"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLine    int
+	}{
+		{token.LET, "let", 1},
+		{token.IDENT, "s", 1},
+		{token.ASSIGN, "=", 1},
+		{token.STRING, "first \\n\n\"second\"", 1},
+		{token.SEMICOLON, ";", 2},
+		{token.LET, "let", 3},
+		{token.IDENT, "x", 3},
+		{token.ASSIGN, "=", 3},
+		{token.INT, "1", 3},
+		{token.SEMICOLON, ";", 3},
+	}
+
+	l := New(input)
+	for i, test := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - token type wrong. expected %q, got %q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected %q, got %q", i, test.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Line != test.expectedLine {
+			t.Fatalf("tests[%d] - line wrong. expected %d, got %d", i, test.expectedLine, tok.Line)
+		}
+	}
+
+	if tok := l.NextToken(); tok.Type != token.EOF {
+		t.Fatalf("expected EOF, got %q", tok.Type)
+	}
+
+	l = New("`never closed\n")
+	tok := l.NextToken()
+
+	if tok.Type != token.ILLEGAL || tok.Literal != "unterminated raw string" {
+		t.Fatalf("expected ILLEGAL unterminated raw string, got %q %q", tok.Type, tok.Literal)
+	}
+}
+
+func TestMultiLineString(t *testing.T) {
+	l := New("\"a\nb\";\nx")
+
+	tok := l.NextToken()
+
+	if tok.Type != token.STRING || tok.Literal != "a\nb" {
+		t.Fatalf("expected STRING \"a\\nb\", got %q %q", tok.Type, tok.Literal)
+	}
+
+	l.NextToken()
+	tok = l.NextToken()
+
+	if tok.Line != 3 {
+		t.Fatalf("expected x to be on line 3, got %d", tok.Line)
+	}
+}
+
+func TestStringInterpolation(t *testing.T) {
+	input := `"Hello ${user.name}, ${ {"a": "${x}"}["a"] }!" "\${x}"`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.TEMPLATE_START, "Hello "},
+		{token.IDENT, "user"},
+		{token.DOT, "."},
+		{token.IDENT, "name"},
+		{token.TEMPLATE_MIDDLE, ", "},
+		{token.LBRACE, "{"},
+		{token.STRING, "a"},
+		{token.COLON, ":"},
+		{token.TEMPLATE_START, ""},
+		{token.IDENT, "x"},
+		{token.TEMPLATE_END, ""},
+		{token.RBRACE, "}"},
+		{token.LBRACKET, "["},
+		{token.STRING, "a"},
+		{token.RBRACKET, "]"},
+		{token.TEMPLATE_END, "!"},
+		{token.STRING, "${x}"},
+	}
+
+	l := New(input)
+	for i, test := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - token type wrong. expected %q, got %q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected %q, got %q", i, test.expectedLiteral, tok.Literal)
+		}
+	}
+
+	if tok := l.NextToken(); tok.Type != token.EOF {
+		t.Fatalf("expected EOF, got %q", tok.Type)
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{"123", token.INT, "123"},
+		{"1_000_000", token.INT, "1_000_000"},
+		{"0xFF", token.INT, "0xFF"},
+		{"0Xdead_beef", token.INT, "0Xdead_beef"},
+		{"0b1010", token.INT, "0b1010"},
+		{"0B1111_0000", token.INT, "0B1111_0000"},
+		{"0o755", token.INT, "0o755"},
+		{"1.5", token.FLOAT, "1.5"},
+		{"1.", token.FLOAT, "1."},
+		{"1_000.000_1", token.FLOAT, "1_000.000_1"},
+		{"1e9", token.FLOAT, "1e9"},
+		{"2.5E-3", token.FLOAT, "2.5E-3"},
+		{"6.02e+23", token.FLOAT, "6.02e+23"},
+		{"1.2.3", token.ILLEGAL, "malformed number 1.2.3"},
+		{"123abc", token.ILLEGAL, "malformed number 123abc"},
+		{"1e", token.ILLEGAL, "exponent has no digits in 1e"},
+		{"1e+", token.ILLEGAL, "exponent has no digits in 1e+"},
+		{"1.5ex", token.ILLEGAL, "exponent has no digits in 1.5ex"},
+		{"0x", token.ILLEGAL, "hexadecimal literal 0x has no digits"},
+		{"0b", token.ILLEGAL, "binary literal 0b has no digits"},
+		{"0b102", token.ILLEGAL, "invalid digit '2' in binary literal 0b102"},
+		{"0o78", token.ILLEGAL, "invalid digit '8' in octal literal 0o78"},
+		{"0xFG", token.ILLEGAL, "invalid digit 'G' in hexadecimal literal 0xFG"},
+		{"0x1.5", token.ILLEGAL, "malformed number 0x1.5"},
+		{"1__000", token.ILLEGAL, "'_' must separate successive digits in 1__000"},
+		{"1_000_", token.ILLEGAL, "'_' must separate successive digits in 1_000_"},
+		{"1_.5", token.ILLEGAL, "'_' must separate successive digits in 1_.5"},
+		{"0x_FF", token.ILLEGAL, "'_' must separate successive digits in 0x_FF"},
+	}
+
+	for i, test := range tests {
+		l := New(test.input)
+		tok := l.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - token type wrong. expected %q, got %q (%q)", i, test.expectedType, tok.Type, tok.Literal)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected %q, got %q", i, test.expectedLiteral, tok.Literal)
+		}
+
+		if tok := l.NextToken(); tok.Type != token.EOF {
+			t.Fatalf("tests[%d] - expected EOF, got %q", i, tok.Type)
+		}
+	}
+}
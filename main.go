@@ -12,13 +12,29 @@ The flags are:
 		Print the tokens of the input.
 
 	-a, --ast
-		Print the AST of the input.
+		Print the AST of the input, after optimizing it when combined with -O.
+
+	-W, --warnings
+		Print warnings about the input, like match expressions that are not exhaustive, before running it.
+
+	--vm
+		Compile the input to bytecode and run it with the virtual machine instead of the evaluator.
+		Imported modules are still run by the evaluator.
+
+	-O, --optimize
+		Optimize the input before running it, folding constant expressions and removing code that is never run.
+		Imported modules are not optimized.
+
+	--max-depth depth
+		Set the maximum amount of nested function calls, 10000 by default and at most 100000.
 
 	-h, --help
 		Print this help message.
 
 	-v, --version
 		Print the version of Vorn.
+
+Flags can be combined and can also come after the file, e.g. `vorn --vm -O -W --max-depth 500 file.vorn`.
 */
 package main
 
@@ -28,19 +44,30 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strings"
 
+	"github.com/iskandervdh/vorn/compiler"
 	"github.com/iskandervdh/vorn/constants"
 	"github.com/iskandervdh/vorn/evaluator"
 	"github.com/iskandervdh/vorn/lexer"
 	"github.com/iskandervdh/vorn/object"
+	"github.com/iskandervdh/vorn/optimizer"
 	"github.com/iskandervdh/vorn/parser"
 	"github.com/iskandervdh/vorn/repl"
+	"github.com/iskandervdh/vorn/resolver"
 	"github.com/iskandervdh/vorn/token"
 	"github.com/iskandervdh/vorn/version"
+	"github.com/iskandervdh/vorn/vm"
 )
 
-func runProgram(in io.Reader, out io.Writer) {
+type runOptions struct {
+	warnings bool // Print warnings about the program before running it
+	vm       bool // Run the program with the virtual machine instead of the evaluator
+	optimize bool // Optimize the program before running it
+
+	maxCallDepth int // The maximum amount of nested function calls, the default of the evaluator when 0
+}
+
+func runProgram(in io.Reader, out io.Writer, path string, options runOptions) {
 	// Create a new environment for the program
 	env := object.NewEnvironment()
 
@@ -61,9 +88,38 @@ func runProgram(in io.Reader, out io.Writer) {
 		return
 	}
 
-	// Create a new evaluator and evaluate the program
+	if options.warnings && len(p.Warnings()) != 0 {
+		parser.PrintWarnings(out, p.Warnings())
+	}
+
+	// Create a new evaluator and resolve the variables of the program
 	e := evaluator.New()
-	evaluated := e.Eval(program, env)
+	e.SetFile(path)
+
+	if options.maxCallDepth > 0 {
+		e.SetMaxCallDepth(options.maxCallDepth)
+	}
+
+	// If any identifiers could not be resolved, print them and exit
+	if errors := resolver.New(e).Resolve(program); len(errors) != 0 {
+		fmt.Println("Error resolving program")
+		resolver.PrintErrors(out, errors)
+		return
+	}
+
+	// Optimize the program after it is resolved, so the errors are reported for the original program
+	if options.optimize {
+		optimizer.New(e).Optimize(program)
+	}
+
+	// Evaluate the program, or compile it and run it with the vm
+	var evaluated object.Object
+
+	if options.vm {
+		evaluated = vm.New(e).Run(compiler.Compile(program), env)
+	} else {
+		evaluated = e.Eval(program, env)
+	}
 
 	// If the evaluated object is nil, something went wrong
 	if evaluated == nil {
@@ -71,8 +127,9 @@ func runProgram(in io.Reader, out io.Writer) {
 		os.Exit(2)
 	}
 
-	// If the evaluated object is an error, print the error and exit
+	// If the evaluated object is an error, print the calls that led to it and the error and exit
 	if evaluated.Type() == object.ERROR_OBJ {
+		io.WriteString(out, evaluated.(*object.Error).Traceback())
 		io.WriteString(out, evaluated.Inspect())
 		io.WriteString(out, "\n")
 
@@ -106,7 +163,7 @@ func handleTokens(in io.Reader) {
 	fmt.Println()
 }
 
-func handleAST(in io.Reader, out io.Writer) {
+func handleAST(in io.Reader, out io.Writer, optimize bool) {
 	// Read the file into a buffer
 	buf := new(bytes.Buffer)
 	buf.ReadFrom(in)
@@ -124,6 +181,10 @@ func handleAST(in io.Reader, out io.Writer) {
 		return
 	}
 
+	if optimize {
+		optimizer.New(evaluator.New()).Optimize(program)
+	}
+
 	// Print the AST
 	fmt.Println(program.String())
 }
@@ -142,103 +203,143 @@ The flags are:
 	    Print the tokens of the input.
 
 	-a, --ast
-	    Print the AST of the input.
+	    Print the AST of the input, after optimizing it when combined with -O.
+
+	-W, --warnings
+	    Print warnings about the input, like match expressions that are not exhaustive, before running it.
+
+	--vm
+	    Compile the input to bytecode and run it with the virtual machine instead of the evaluator.
+	    Imported modules are still run by the evaluator.
+
+	-O, --optimize
+	    Optimize the input before running it, folding constant expressions and removing code that is never run.
+	    Imported modules are not optimized.
+
+	--max-depth depth
+	    Set the maximum amount of nested function calls, 10000 by default and at most 100000.
 
 	-v, --version
 	    Print the version of Vorn.
 
 	-h, --help
-	    Print this help message.`)
+	    Print this help message.
+
+Flags can be combined and can also come after the file, e.g. ` + "`vorn --vm -O -W --max-depth 500 file.vorn`" + `.`)
 }
 
-func handleFlag(f string) {
-	switch f {
-	case "-h", "--help":
-		printHelp()
-		os.Exit(0)
-	case "--tokens":
-		if len(os.Args) < 3 {
-			fmt.Println("Usage: vorn --tokens [file]")
-			os.Exit(1)
-		}
+var (
+	tokensFlag   bool
+	astFlag      bool
+	warningsFlag bool
+	vmFlag       bool
+	optimizeFlag bool
+	maxDepthFlag int
+	versionFlag  bool
+	helpFlag     bool
+)
 
-		file, err := os.OpenFile(os.Args[2], os.O_RDONLY, 0644)
+func init() {
+	flag.Usage = printHelp
+	flag.BoolVar(&tokensFlag, "tokens", false, "Print the tokens of the input.")
 
-		if err != nil {
-			fmt.Printf("Error opening file %s: %s\n", os.Args[2], err)
-			os.Exit(1)
-		}
+	const astUsage = "Print the AST of the input."
+	flag.BoolVar(&astFlag, "a", false, astUsage+" (shorthand)")
+	flag.BoolVar(&astFlag, "ast", false, astUsage)
 
-		handleTokens(file)
-		os.Exit(0)
-	case "-a", "--ast":
-		if len(os.Args) < 3 {
-			fmt.Println("Usage: vorn -a [file]")
-			os.Exit(1)
-		}
+	const warningsUsage = "Print warnings about the input before running it."
+	flag.BoolVar(&warningsFlag, "W", false, warningsUsage+" (shorthand)")
+	flag.BoolVar(&warningsFlag, "warnings", false, warningsUsage)
 
-		file, err := os.OpenFile(os.Args[2], os.O_RDONLY, 0644)
+	flag.BoolVar(&vmFlag, "vm", false, "Run the input with the virtual machine instead of the evaluator.")
 
-		if err != nil {
-			fmt.Printf("Error opening file %s: %s\n", os.Args[2], err)
-			os.Exit(1)
-		}
+	const optimizeUsage = "Optimize the input before running it."
+	flag.BoolVar(&optimizeFlag, "O", false, optimizeUsage+" (shorthand)")
+	flag.BoolVar(&optimizeFlag, "optimize", false, optimizeUsage)
 
-		handleAST(file, os.Stdout)
-		os.Exit(0)
-	case "-v", "--version":
-		fmt.Printf("vorn %s\n", version.Version)
-		os.Exit(0)
-	default:
-		fmt.Printf("Unknown flag %s\n", f)
-		os.Exit(1)
-	}
+	flag.IntVar(&maxDepthFlag, "max-depth", constants.MAX_CALL_DEPTH, "Set the maximum amount of nested function calls.")
+
+	const versionUsage = "Print the version of Vorn."
+	flag.BoolVar(&versionFlag, "v", false, versionUsage+" (shorthand)")
+	flag.BoolVar(&versionFlag, "version", false, versionUsage)
+
+	const helpUsage = "Print this help message."
+	flag.BoolVar(&helpFlag, "h", false, helpUsage+" (shorthand)")
+	flag.BoolVar(&helpFlag, "help", false, helpUsage)
 }
 
-var traceFlag string
+/*
+Parse the flags of the command line, which can come before and after the path of the file.
 
-func init() {
-	flag.Usage = printHelp
-	flag.String("tokens", "", "Print the tokens of the input.")
+Returns the arguments that are not flags.
+*/
+func parseArguments(args []string) []string {
+	positional := []string{}
 
-	const (
-		astDefault = ""
-		astUsage   = "Print the AST of the input."
-	)
-	flag.StringVar(&traceFlag, "a", astDefault, astUsage+" (shorthand)")
-	flag.StringVar(&traceFlag, "ast", astDefault, astUsage)
+	for {
+		flag.CommandLine.Parse(args)
+		args = flag.Args()
 
-	const versionUsage = "Print the version of Vorn."
-	flag.Bool("v", false, versionUsage+" (shorthand)")
-	flag.Bool("version", false, versionUsage)
+		if len(args) == 0 {
+			return positional
+		}
 
-	const helpUsage = "Print this help message."
-	flag.Bool("h", false, helpUsage+" (shorthand)")
-	flag.Bool("help", false, helpUsage)
+		// The flag package stops at the first argument that is not a flag, so continue parsing after it
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
 }
 
 func main() {
-	flag.Parse()
+	args := parseArguments(os.Args[1:])
+
+	if helpFlag {
+		printHelp()
+		os.Exit(0)
+	}
 
-	if len(os.Args) == 1 {
+	if versionFlag {
 		fmt.Printf("vorn %s\n", version.Version)
-		repl.Start(os.Stdin, os.Stdout)
+		os.Exit(0)
+	}
 
-		return
+	if maxDepthFlag <= 0 || maxDepthFlag > constants.MAX_CALL_DEPTH_LIMIT {
+		fmt.Printf("Invalid maximum call depth %d, it must be between 1 and %d\n", maxDepthFlag, constants.MAX_CALL_DEPTH_LIMIT)
+		os.Exit(1)
 	}
 
-	if strings.HasPrefix(os.Args[1], "-") {
-		handleFlag(os.Args[1])
+	if len(args) > 1 || (len(args) == 0 && flag.NFlag() != 0) {
+		fmt.Println("Usage: vorn [flags] [path/to/file]")
+		os.Exit(1)
+	}
+
+	if len(args) == 0 {
+
+		fmt.Printf("vorn %s\n", version.Version)
+		repl.Start(os.Stdin, os.Stdout)
 
 		return
 	}
 
-	file, err := os.OpenFile(os.Args[1], os.O_RDONLY, 0644)
+	path := args[0]
+	file, err := os.OpenFile(path, os.O_RDONLY, 0644)
 
 	if err != nil {
-		fmt.Printf("Error opening file %s: %s\n", os.Args[1], err)
-		return
+		fmt.Printf("Error opening file %s: %s\n", path, err)
+		os.Exit(1)
 	}
 
-	runProgram(file, os.Stdout)
+	switch {
+	case tokensFlag:
+		handleTokens(file)
+	case astFlag:
+		handleAST(file, os.Stdout, optimizeFlag)
+	default:
+		runProgram(file, os.Stdout, path, runOptions{
+			warnings:     warningsFlag,
+			vm:           vmFlag,
+			optimize:     optimizeFlag,
+			maxCallDepth: maxDepthFlag,
+		})
+	}
 }
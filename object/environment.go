@@ -1,8 +1,33 @@
 package object
 
+import "github.com/iskandervdh/vorn/ast"
+
+/*
+An environment is either the global environment of a program or module, which stores its variables by name,
+or a frame that stores the local variables of a scope in the slots assigned to them by the resolver.
+*/
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store  map[string]*Variable // Variables by name, nil for frames
+	slots  []Object             // Local variables by slot, unset slots are nil
+	outer  *Environment
+	caught *caughtError // The error caught by the catch block of this environment, nil outside catch blocks
+}
+
+/*
+An error caught by a catch block and the value bound to the parameter of the block in its place
+*/
+type caughtError struct {
+	value Object
+	err   *Error
+}
+
+/*
+A variable of an environment that stores its variables by name.
+
+The variable keeps its place when it is assigned, so the vm can hold on to it instead of looking it up by name every time.
+*/
+type Variable struct {
+	Value Object // nil while the variable is not defined
 }
 
 /*
@@ -11,7 +36,7 @@ Create a new environment.
 Returns the new environment.
 */
 func NewEnvironment() *Environment {
-	s := make(map[string]Object)
+	s := make(map[string]*Variable)
 
 	return &Environment{store: s}
 }
@@ -28,15 +53,37 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 	return env
 }
 
+/*
+Create a new frame with room for size local variables that is enclosed by the given environment.
+
+Returns the new frame.
+*/
+func NewFrame(outer *Environment, size int) *Environment {
+	return &Environment{slots: make([]Object, size), outer: outer}
+}
+
+/*
+Create the environment of a scope with the given amount of slots.
+
+A scope without slots does not get a frame of its own, so the given environment is returned instead.
+*/
+func NewScope(outer *Environment, slots int) *Environment {
+	if slots == 0 {
+		return outer
+	}
+
+	return NewFrame(outer, slots)
+}
+
 /*
 Get an object from the environment by name. If the object is not found in the current environment,
-check the outer environment if it exists.
+check the outer environment if it exists. Frames are skipped, because they do not store variables by name.
 
 Returns the object, the environment we were searching in and a boolean indicating if the object was found.
 */
 func (e *Environment) Get(name string) (Object, *Environment, bool) {
 	env := e
-	obj, ok := e.store[name]
+	obj, ok := e.GetFromCurrent(name)
 
 	// If the object is not found in the current environment,
 	// check the outer environment if it exists
@@ -55,9 +102,13 @@ Get an object from the current environment by name.
 If the object is found in the current environment return it and true. Otherwise return the nil and false.
 */
 func (e *Environment) GetFromCurrent(name string) (Object, bool) {
-	obj, ok := e.store[name]
+	variable, ok := e.store[name]
 
-	return obj, ok
+	if !ok || variable.Value == nil {
+		return nil, false
+	}
+
+	return variable.Value, true
 }
 
 /*
@@ -66,7 +117,164 @@ Set the value of an identifier in the environment.
 Returns the value that was set.
 */
 func (e *Environment) Set(name string, val Object) Object {
-	e.store[name] = val
+	if variable, ok := e.store[name]; ok {
+		variable.Value = val
+	} else {
+		e.store[name] = &Variable{Value: val}
+	}
 
 	return val
 }
+
+/*
+Get the global variable with the given name, which is the variable an identifier that is not local refers to.
+
+When the variable is not defined yet, an undefined variable is added to the environment that stores the global
+variables, which becomes the variable when it is defined.
+*/
+func (e *Environment) Variable(name string) *Variable {
+	global := e.global()
+
+	if _, env, ok := global.Get(name); ok {
+		return env.store[name]
+	}
+
+	variable, ok := global.store[name]
+
+	if !ok {
+		variable = &Variable{}
+		global.store[name] = variable
+	}
+
+	return variable
+}
+
+/*
+Remember the error a catch block caught and the value bound to its parameter,
+so throwing the value again inside the block throws the original error.
+*/
+func (e *Environment) Catch(value Object, err *Error) {
+	e.caught = &caughtError{value: value, err: err}
+}
+
+/*
+Get the original error of a value that was bound to the parameter of a catch block this environment is in.
+
+Returns the error and a boolean indicating if the value was caught as an error.
+*/
+func (e *Environment) CaughtError(value Object) (*Error, bool) {
+	for env := e; env != nil; env = env.outer {
+		if env.caught != nil && env.caught.value == value {
+			return env.caught.err, true
+		}
+	}
+
+	return nil, false
+}
+
+/*
+Get the environment that encloses this environment.
+
+Returns nil if the environment is not enclosed by another environment.
+*/
+func (e *Environment) Outer() *Environment {
+	return e.outer
+}
+
+/*
+Get the frame depth environments up from this environment
+*/
+func (e *Environment) frame(depth int) *Environment {
+	env := e
+
+	for i := 0; i < depth; i++ {
+		env = env.outer
+	}
+
+	return env
+}
+
+/*
+Get the nearest environment that stores its variables by name, which is where the global variables are
+*/
+func (e *Environment) global() *Environment {
+	env := e
+
+	for env.store == nil && env.outer != nil {
+		env = env.outer
+	}
+
+	return env
+}
+
+/*
+Get the value of the variable an identifier refers to, using the slot the resolver assigned to it for local variables.
+
+Returns the value and a boolean indicating if the variable is defined.
+*/
+func (e *Environment) Lookup(ident *ast.Identifier) (Object, bool) {
+	if ident.Local {
+		value := e.frame(ident.Depth).slots[ident.Slot]
+
+		return value, value != nil
+	}
+
+	value, _, ok := e.global().Get(ident.Value)
+
+	return value, ok
+}
+
+/*
+Check if the variable an identifier declares is already defined in the scope it is declared in
+*/
+func (e *Environment) IsDefined(ident *ast.Identifier) bool {
+	if ident.Local {
+		return e.frame(ident.Depth).slots[ident.Slot] != nil
+	}
+
+	_, ok := e.global().GetFromCurrent(ident.Value)
+
+	return ok
+}
+
+/*
+Define the variable an identifier declares in the scope it is declared in
+*/
+func (e *Environment) Define(ident *ast.Identifier, value Object) {
+	if ident.Local {
+		e.frame(ident.Depth).slots[ident.Slot] = value
+
+		return
+	}
+
+	e.global().Set(ident.Value, value)
+}
+
+/*
+Assign a new value to the variable an identifier refers to.
+
+Returns false if the variable is not defined.
+*/
+func (e *Environment) Assign(ident *ast.Identifier, value Object) bool {
+	if ident.Local {
+		frame := e.frame(ident.Depth)
+
+		if frame.slots[ident.Slot] == nil {
+			return false
+		}
+
+		frame.slots[ident.Slot] = value
+
+		return true
+	}
+
+	_, env, ok := e.global().Get(ident.Value)
+
+	if !ok {
+		return false
+	}
+
+	env.Set(ident.Value, value)
+
+	return true
+}
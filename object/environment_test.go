@@ -1,6 +1,10 @@
 package object
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/iskandervdh/vorn/ast"
+)
 
 func TestEnvironment(t *testing.T) {
 	env := NewEnvironment()
@@ -131,3 +135,159 @@ func TestEnclosedEnvironment(t *testing.T) {
 		t.Errorf("environment is not the same as the one the object was set in")
 	}
 }
+
+func TestEnvironmentOuter(t *testing.T) {
+	env := NewEnvironment()
+	enclosed := NewEnclosedEnvironment(env)
+
+	if enclosed.Outer() != env {
+		t.Errorf("enclosed environment has wrong outer environment")
+	}
+
+	if env.Outer() != nil {
+		t.Errorf("environment without outer environment has outer environment %v", env.Outer())
+	}
+}
+
+func TestNewScope(t *testing.T) {
+	env := NewEnvironment()
+
+	if NewScope(env, 0) != env {
+		t.Errorf("scope without slots is not the enclosing environment")
+	}
+
+	scope := NewScope(env, 2)
+
+	if scope == env || scope.Outer() != env || len(scope.slots) != 2 {
+		t.Errorf("scope with slots is not a new frame with 2 slots enclosed by the environment")
+	}
+}
+
+func TestFrames(t *testing.T) {
+	global := NewEnvironment()
+	frame := NewFrame(global, 2)
+	inner := NewFrame(frame, 1)
+
+	a := &ast.Identifier{Value: "a", Local: true, Depth: 1, Slot: 1}
+	b := &ast.Identifier{Value: "b", Local: true, Depth: 0, Slot: 0}
+	g := &ast.Identifier{Value: "g"}
+
+	if _, ok := inner.Lookup(a); ok {
+		t.Errorf("inner.Lookup returned an object for a variable that is not defined")
+	}
+
+	if inner.IsDefined(a) || inner.IsDefined(g) {
+		t.Errorf("inner.IsDefined returned true for a variable that is not defined")
+	}
+
+	if inner.Assign(a, NewInteger(nil, 1)) || inner.Assign(g, NewInteger(nil, 1)) {
+		t.Errorf("inner.Assign assigned a variable that is not defined")
+	}
+
+	inner.Define(a, NewInteger(nil, 1))
+	inner.Define(b, NewInteger(nil, 2))
+	inner.Define(g, NewInteger(nil, 3))
+
+	if frame.slots[1] == nil || inner.slots[0] == nil {
+		t.Fatalf("local variables are not defined in the slots of their frames")
+	}
+
+	if _, ok := global.GetFromCurrent("g"); !ok {
+		t.Fatalf("global variable is not defined in the global environment")
+	}
+
+	if !inner.IsDefined(a) || !inner.IsDefined(g) {
+		t.Errorf("inner.IsDefined returned false for a defined variable")
+	}
+
+	if !inner.Assign(a, NewInteger(nil, 10)) || !inner.Assign(g, NewInteger(nil, 30)) {
+		t.Errorf("inner.Assign did not assign a defined variable")
+	}
+
+	for _, tt := range []struct {
+		ident    *ast.Identifier
+		expected int64
+	}{
+		{a, 10},
+		{b, 2},
+		{g, 30},
+	} {
+		object, ok := inner.Lookup(tt.ident)
+
+		if !ok {
+			t.Fatalf("inner.Lookup did not return an object for %s", tt.ident.Value)
+		}
+
+		if object.(*Integer).Value != tt.expected {
+			t.Errorf("wrong value for %s. got=%d, want=%d", tt.ident.Value, object.(*Integer).Value, tt.expected)
+		}
+	}
+
+	if _, _, ok := inner.Get("g"); !ok {
+		t.Errorf("inner.Get did not skip the frames to find a global variable")
+	}
+}
+
+func TestVariable(t *testing.T) {
+	global := NewEnvironment()
+	frame := NewFrame(global, 1)
+
+	variable := frame.Variable("x")
+
+	if variable.Value != nil {
+		t.Fatalf("variable that is not defined has a value. got=%v", variable.Value)
+	}
+
+	if _, ok := global.GetFromCurrent("x"); ok {
+		t.Fatalf("variable that is not defined is found by GetFromCurrent")
+	}
+
+	if global.IsDefined(&ast.Identifier{Value: "x"}) {
+		t.Fatalf("variable that is not defined is defined")
+	}
+
+	global.Set("x", NewInteger(nil, 1))
+
+	if variable.Value == nil || variable.Value.(*Integer).Value != 1 {
+		t.Fatalf("defining the variable did not set the value of the variable. got=%v", variable.Value)
+	}
+
+	variable.Value = NewInteger(nil, 2)
+
+	if value, _, ok := global.Get("x"); !ok || value.(*Integer).Value != 2 {
+		t.Errorf("assigning the variable did not change the value in the environment. got=%v", value)
+	}
+
+	if frame.Variable("x") != variable {
+		t.Errorf("frame.Variable returned a different variable for the same name")
+	}
+
+	outer := NewEnvironment()
+	outer.Set("y", NewInteger(nil, 3))
+
+	if NewEnclosedEnvironment(outer).Variable("y") != outer.store["y"] {
+		t.Errorf("Variable did not return the variable of the outer environment")
+	}
+}
+
+func TestCaughtError(t *testing.T) {
+	global := NewEnvironment()
+	catch := NewFrame(global, 1)
+	inner := NewFrame(catch, 1)
+
+	err := NewError(&ast.Identifier{Value: "e"}, "boom")
+	value := NewHash(nil)
+	catch.Catch(value, err)
+
+	if caught, ok := inner.CaughtError(value); !ok || caught != err {
+		t.Errorf("caught error is not found from an enclosed environment. got=%v", caught)
+	}
+
+	if _, ok := inner.CaughtError(NewHash(nil)); ok {
+		t.Errorf("a value that was not caught is found as a caught error")
+	}
+
+	if _, ok := global.CaughtError(value); ok {
+		t.Errorf("caught error is found outside of its catch block")
+	}
+}
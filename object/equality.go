@@ -0,0 +1,218 @@
+package object
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"strings"
+)
+
+/*
+Check if two objects are structurally equal.
+
+Arrays are equal when their elements are equal in the same order, hashes when they have equal values for the same keys.
+Integers and floats are compared by their numeric value. Other objects like functions and instances are only equal to themselves.
+*/
+func Equal(a, b Object) bool {
+	return equal(a, b, map[[2]Object]bool{}, nil)
+}
+
+/*
+Check if two objects are structurally equal like Equal does, letting instances decide if they are equal to another object,
+also when they are inside of arrays and hashes.
+
+instanceEqual is called when either object is an instance and returns if the objects are equal and true,
+or false to compare the instance by identity.
+*/
+func EqualWith(a, b Object, instanceEqual func(a, b Object) (bool, bool)) bool {
+	return equal(a, b, map[[2]Object]bool{}, instanceEqual)
+}
+
+// The pairs of arrays and hashes being compared are tracked to stop at cycles, which are considered equal
+func equal(a, b Object, comparing map[[2]Object]bool, instanceEqual func(a, b Object) (bool, bool)) bool {
+	if a == b {
+		return true
+	}
+
+	if instanceEqual != nil {
+		_, aInstance := a.(*Instance)
+		_, bInstance := b.(*Instance)
+
+		if aInstance || bInstance {
+			if result, ok := instanceEqual(a, b); ok {
+				return result
+			}
+		}
+	}
+
+	switch a := a.(type) {
+	case *Integer:
+		switch b := b.(type) {
+		case *Integer:
+			return a.Value == b.Value
+		case *Float:
+			return float64(a.Value) == b.Value
+		}
+	case *Float:
+		switch b := b.(type) {
+		case *Integer:
+			return a.Value == float64(b.Value)
+		case *Float:
+			return a.Value == b.Value
+		}
+	case *Boolean:
+		if b, ok := b.(*Boolean); ok {
+			return a.Value == b.Value
+		}
+	case *Null:
+		return b.Type() == NULL_OBJ
+	case *String:
+		if b, ok := b.(*String); ok {
+			return a.Value == b.Value
+		}
+	case *Array:
+		b, ok := b.(*Array)
+
+		if !ok || len(a.Elements) != len(b.Elements) {
+			return false
+		}
+
+		pair := [2]Object{a, b}
+
+		if comparing[pair] {
+			return true
+		}
+
+		comparing[pair] = true
+
+		for i := range a.Elements {
+			if !equal(a.Elements[i], b.Elements[i], comparing, instanceEqual) {
+				return false
+			}
+		}
+
+		return true
+	case *Hash:
+		b, ok := b.(*Hash)
+
+		if !ok || len(a.Pairs) != len(b.Pairs) {
+			return false
+		}
+
+		pair := [2]Object{a, b}
+
+		if comparing[pair] {
+			return true
+		}
+
+		comparing[pair] = true
+
+		for key, aPair := range a.Pairs {
+			bPair, ok := b.Pairs[key]
+
+			if !ok || !equal(aPair.Value, bPair.Value, comparing, instanceEqual) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+/*
+Get the key of an object used to store it in a hash.
+
+Arrays and hashes are keyed by their contents and floats by their numeric value, so values that are equal with ==
+have the same key. Returns false if the object can not be used as a key, like functions, NaN or arrays that contain themselves.
+*/
+func HashKeyOf(obj Object) (HashKey, bool) {
+	return hashKeyOf(obj, map[Object]bool{})
+}
+
+func hashKeyOf(obj Object, visiting map[Object]bool) (HashKey, bool) {
+	switch obj := obj.(type) {
+	case Hashable:
+		return obj.HashKey(), true
+	case *Float:
+		return floatHashKey(obj.Value)
+	case *Array:
+		if visiting[obj] {
+			return HashKey{}, false
+		}
+
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		var content strings.Builder
+
+		for _, element := range obj.Elements {
+			key, ok := hashKeyOf(element, visiting)
+
+			if !ok {
+				return HashKey{}, false
+			}
+
+			writeHashKey(&content, key)
+		}
+
+		return HashKey{Type: obj.Type(), Value: uint64(len(obj.Elements)), Content: content.String()}, true
+	case *Hash:
+		if visiting[obj] {
+			return HashKey{}, false
+		}
+
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		pairs := make([]string, 0, len(obj.Keys))
+
+		for _, key := range obj.Keys {
+			value, ok := hashKeyOf(obj.Pairs[key].Value, visiting)
+
+			if !ok {
+				return HashKey{}, false
+			}
+
+			var pair strings.Builder
+			writeHashKey(&pair, key)
+			writeHashKey(&pair, value)
+
+			pairs = append(pairs, pair.String())
+		}
+
+		// The pairs are sorted so the order they were added in does not matter
+		sort.Strings(pairs)
+
+		return HashKey{Type: obj.Type(), Value: uint64(len(pairs)), Content: strings.Join(pairs, "")}, true
+	}
+
+	return HashKey{}, false
+}
+
+/*
+Get the key of a float, which is the key of the equal integer when the float is a whole number
+*/
+func floatHashKey(value float64) (HashKey, bool) {
+	if math.IsNaN(value) {
+		return HashKey{}, false
+	}
+
+	if value == math.Trunc(value) && value >= math.MinInt64 && value < math.MaxInt64 {
+		return HashKey{Type: INTEGER_OBJ, Value: uint64(int64(value))}, true
+	}
+
+	return HashKey{Type: FLOAT_OBJ, Value: math.Float64bits(value)}, true
+}
+
+/*
+Write a key in a form that can be told apart from every other key, even when keys are written one after the other
+*/
+func writeHashKey(w *strings.Builder, key HashKey) {
+	w.WriteString(string(key.Type))
+	w.WriteByte(0)
+	w.Write(binary.LittleEndian.AppendUint64(nil, key.Value))
+	w.Write(binary.AppendUvarint(nil, uint64(len(key.Content))))
+	w.WriteString(key.Content)
+}
@@ -0,0 +1,172 @@
+package object
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	array := &Array{Elements: []Object{&Integer{Value: 1}}}
+	cyclic1 := &Array{Elements: []Object{&Integer{Value: 1}}}
+	cyclic1.Elements = append(cyclic1.Elements, cyclic1)
+	cyclic2 := &Array{Elements: []Object{&Integer{Value: 1}}}
+	cyclic2.Elements = append(cyclic2.Elements, cyclic2)
+	function := &Function{}
+
+	hash := func(key string, value Object) *Hash {
+		return newTestHash(nil, HashPair{Key: &String{Value: key}, Value: value})
+	}
+
+	tests := []struct {
+		a, b     Object
+		expected bool
+	}{
+		{&Integer{Value: 1}, &Integer{Value: 1}, true},
+		{&Integer{Value: 1}, &Integer{Value: 2}, false},
+		{&Integer{Value: 1}, &Float{Value: 1}, true},
+		{&Float{Value: 1.5}, &Integer{Value: 1}, false},
+		{&Float{Value: 1.5}, &Float{Value: 1.5}, true},
+		{&Boolean{Value: true}, &Boolean{Value: true}, true},
+		{&Boolean{Value: true}, &Integer{Value: 1}, false},
+		{&String{Value: "a"}, &String{Value: "a"}, true},
+		{&String{Value: "a"}, &String{Value: "b"}, false},
+		{&Null{}, NULL, true},
+		{&Null{}, &Integer{Value: 0}, false},
+		{array, &Array{Elements: []Object{&Integer{Value: 1}}}, true},
+		{array, &Array{Elements: []Object{&Integer{Value: 2}}}, false},
+		{array, &Array{}, false},
+		{cyclic1, cyclic2, true},
+		{hash("a", array), hash("a", &Array{Elements: []Object{&Integer{Value: 1}}}), true},
+		{hash("a", array), hash("b", array), false},
+		{hash("a", array), hash("a", &Integer{Value: 1}), false},
+		{hash("a", array), array, false},
+		{function, function, true},
+		{function, &Function{}, false},
+	}
+
+	for i, test := range tests {
+		if Equal(test.a, test.b) != test.expected {
+			t.Errorf("tests[%d] - Equal(%s, %s) wrong. expected %t", i, test.a.Inspect(), test.b.Inspect(), test.expected)
+		}
+	}
+}
+
+func TestEqualWith(t *testing.T) {
+	point := &Struct{Name: "Point", Fields: []string{"x"}}
+	instance := func(x int64) *Instance {
+		return &Instance{Struct: point, Fields: map[string]Object{"x": &Integer{Value: x}}}
+	}
+
+	// Instances are equal when their x fields are equal
+	instanceEqual := func(a, b Object) (bool, bool) {
+		a1, ok1 := a.(*Instance)
+		b1, ok2 := b.(*Instance)
+
+		if !ok1 || !ok2 {
+			return false, true
+		}
+
+		return Equal(a1.Fields["x"], b1.Fields["x"]), true
+	}
+
+	tests := []struct {
+		a, b     Object
+		expected bool
+	}{
+		{instance(1), instance(1), true},
+		{instance(1), instance(2), false},
+		{&Array{Elements: []Object{instance(1)}}, &Array{Elements: []Object{instance(1)}}, true},
+		{&Array{Elements: []Object{instance(1)}}, &Array{Elements: []Object{instance(2)}}, false},
+		{&Array{Elements: []Object{instance(1)}}, &Array{Elements: []Object{&Integer{Value: 1}}}, false},
+	}
+
+	for i, test := range tests {
+		if EqualWith(test.a, test.b, instanceEqual) != test.expected {
+			t.Errorf("tests[%d] - EqualWith(%s, %s) wrong. expected %t", i, test.a.Inspect(), test.b.Inspect(), test.expected)
+		}
+	}
+
+	if Equal(&Array{Elements: []Object{instance(1)}}, &Array{Elements: []Object{instance(1)}}) {
+		t.Errorf("expected Equal to compare instances by identity")
+	}
+}
+
+func TestHashKeyOf(t *testing.T) {
+	array1 := &Array{Elements: []Object{&Integer{Value: 1}, &String{Value: "a"}}}
+	array2 := &Array{Elements: []Object{&Integer{Value: 1}, &String{Value: "a"}}}
+	reversed := &Array{Elements: []Object{&String{Value: "a"}, &Integer{Value: 1}}}
+
+	key1, ok1 := HashKeyOf(array1)
+	key2, ok2 := HashKeyOf(array2)
+	key3, _ := HashKeyOf(reversed)
+
+	if !ok1 || !ok2 || key1 != key2 {
+		t.Errorf("arrays with same content have different keys")
+	}
+
+	if key1 == key3 {
+		t.Errorf("arrays with different content have same keys")
+	}
+
+	a, b := &String{Value: "a"}, &String{Value: "b"}
+	hash1 := newTestHash(nil, HashPair{Key: a, Value: array1}, HashPair{Key: b, Value: b})
+	hash2 := newTestHash(nil, HashPair{Key: b, Value: b}, HashPair{Key: a, Value: array2})
+
+	hashKey1, ok1 := HashKeyOf(hash1)
+	hashKey2, ok2 := HashKeyOf(hash2)
+
+	if !ok1 || !ok2 || hashKey1 != hashKey2 {
+		t.Errorf("hashes with same content have different keys")
+	}
+
+	nested := &Array{Elements: []Object{&Array{Elements: []Object{&Integer{Value: 1}}}, &Integer{Value: 2}}}
+	flat := &Array{Elements: []Object{&Integer{Value: 1}, &Array{Elements: []Object{&Integer{Value: 2}}}}}
+	nestedKey, _ := HashKeyOf(nested)
+	flatKey, _ := HashKeyOf(flat)
+
+	if nestedKey == flatKey {
+		t.Errorf("arrays with different nesting have same keys")
+	}
+
+	// Keys that would add up to the same value when their pairs are summed
+	swapped1 := newTestHash(nil, HashPair{Key: a, Value: &Integer{Value: 1}}, HashPair{Key: b, Value: &Integer{Value: 2}})
+	swapped2 := newTestHash(nil, HashPair{Key: a, Value: &Integer{Value: 2}}, HashPair{Key: b, Value: &Integer{Value: 1}})
+	swappedKey1, _ := HashKeyOf(swapped1)
+	swappedKey2, _ := HashKeyOf(swapped2)
+
+	if swappedKey1 == swappedKey2 {
+		t.Errorf("hashes with different content have same keys")
+	}
+
+	integers := &Array{Elements: []Object{&Integer{Value: 1}, &Integer{Value: -2}}}
+	floats := &Array{Elements: []Object{&Float{Value: 1.0}, &Float{Value: -2.0}}}
+	integersKey, ok1 := HashKeyOf(integers)
+	floatsKey, ok2 := HashKeyOf(floats)
+
+	if !ok1 || !ok2 || integersKey != floatsKey {
+		t.Errorf("equal arrays of integers and floats have different keys")
+	}
+
+	halfKey, ok := HashKeyOf(&Float{Value: 1.5})
+	oneKey, _ := HashKeyOf(&Integer{Value: 1})
+
+	if !ok || halfKey == oneKey {
+		t.Errorf("expected 1.5 to have its own key")
+	}
+
+	cyclic := &Array{}
+	cyclic.Elements = []Object{cyclic}
+
+	unhashable := []Object{
+		cyclic,
+		&Float{Value: math.NaN()},
+		&Array{Elements: []Object{&Function{}}},
+		newTestHash(nil, HashPair{Key: a, Value: &Function{}}),
+	}
+
+	for _, obj := range unhashable {
+		if _, ok := HashKeyOf(obj); ok {
+			t.Errorf("expected %T to be unusable as a hash key", obj)
+		}
+	}
+}
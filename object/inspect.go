@@ -0,0 +1,94 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+/*
+Get the string representation of an object like Inspect does, letting format represent the object
+and the values inside of arrays, hashes and instances.
+
+format returns the representation of a value and true, or false to use the default representation.
+*/
+func InspectWith(obj Object, format func(Object) (string, bool)) string {
+	return inspect(obj, map[Object]bool{}, format)
+}
+
+// The arrays, hashes and instances being inspected are tracked to stop at cycles,
+// which are shown as [...], {...} or the name of the struct followed by {...}
+func inspect(obj Object, visiting map[Object]bool, format func(Object) (string, bool)) string {
+	if format != nil {
+		if str, ok := format(obj); ok {
+			return str
+		}
+	}
+
+	switch obj := obj.(type) {
+	case *Array:
+		if visiting[obj] {
+			return "[...]"
+		}
+
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		var out bytes.Buffer
+		elements := []string{}
+
+		for _, e := range obj.Elements {
+			elements = append(elements, inspect(e, visiting, format))
+		}
+
+		out.WriteString("[")
+		out.WriteString(strings.Join(elements, ", "))
+		out.WriteString("]")
+
+		return out.String()
+	case *Hash:
+		if visiting[obj] {
+			return "{...}"
+		}
+
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		var out bytes.Buffer
+		pairs := []string{}
+
+		for _, key := range obj.Keys {
+			pair := obj.Pairs[key]
+			pairs = append(pairs, fmt.Sprintf("%s: %s", inspect(pair.Key, visiting, format), inspect(pair.Value, visiting, format)))
+		}
+
+		out.WriteString("{")
+		out.WriteString(strings.Join(pairs, ", "))
+		out.WriteString("}")
+
+		return out.String()
+	case *Instance:
+		if visiting[obj] {
+			return obj.Struct.Name + "{...}"
+		}
+
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		var out bytes.Buffer
+		fields := []string{}
+
+		for _, name := range obj.Struct.Fields {
+			fields = append(fields, fmt.Sprintf("%s: %s", name, inspect(obj.Fields[name], visiting, format)))
+		}
+
+		out.WriteString(obj.Struct.Name)
+		out.WriteString("{")
+		out.WriteString(strings.Join(fields, ", "))
+		out.WriteString("}")
+
+		return out.String()
+	}
+
+	return obj.Inspect()
+}
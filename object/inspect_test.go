@@ -0,0 +1,53 @@
+package object
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestInspectCycles(t *testing.T) {
+	array := &Array{Elements: []Object{&Integer{Value: 1}}}
+	array.Elements = append(array.Elements, array)
+
+	hash := newTestHash(nil, HashPair{Key: &String{Value: "a"}, Value: &Integer{Value: 1}})
+	hash.Set((&String{Value: "self"}).HashKey(), HashPair{Key: &String{Value: "self"}, Value: hash})
+
+	nested := &Array{Elements: []Object{hash, hash}}
+
+	point := &Struct{Name: "Point", Fields: []string{"x", "next"}}
+	instance := &Instance{Struct: point, Fields: map[string]Object{"x": &Integer{Value: 1}}}
+	instance.Fields["next"] = instance
+
+	tests := []struct {
+		object   Object
+		expected string
+	}{
+		{array, "[1, [...]]"},
+		{hash, "{a: 1, self: {...}}"},
+		{nested, "[{a: 1, self: {...}}, {a: 1, self: {...}}]"},
+		{instance, "Point{x: 1, next: Point{...}}"},
+	}
+
+	for _, test := range tests {
+		if got := test.object.Inspect(); got != test.expected {
+			t.Errorf("wrong inspect output. expected %q, got %q", test.expected, got)
+		}
+	}
+}
+
+func TestInspectWith(t *testing.T) {
+	array := &Array{Elements: []Object{&Integer{Value: 1}, &String{Value: "a"}}}
+	array.Elements = append(array.Elements, array)
+
+	format := func(obj Object) (string, bool) {
+		if integer, ok := obj.(*Integer); ok {
+			return fmt.Sprintf("<%d>", integer.Value), true
+		}
+
+		return "", false
+	}
+
+	if got := InspectWith(array, format); got != "[<1>, a, [...]]" {
+		t.Errorf("wrong inspect output. expected %q, got %q", "[<1>, a, [...]]", got)
+	}
+}
@@ -3,8 +3,8 @@ package object
 import (
 	"bytes"
 	"fmt"
-	"hash/fnv"
-	"strings"
+	"path/filepath"
+	"slices"
 
 	"github.com/iskandervdh/vorn/ast"
 	"github.com/iskandervdh/vorn/token"
@@ -18,6 +18,7 @@ const (
 	BOOLEAN_OBJ      = "BOOLEAN"
 	FLOAT_OBJ        = "FLOAT"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	TAIL_CALL_OBJ    = "TAIL_CALL"
 	BREAK_OBJ        = "BREAK"
 	CONTINUE_OBJ     = "CONTINUE"
 	ERROR_OBJ        = "ERROR"
@@ -26,6 +27,9 @@ const (
 	BUILTIN_OBJ      = "BUILTIN"
 	ARRAY_OBJ        = "ARRAY"
 	HASH_OBJ         = "HASH"
+	MODULE_OBJ       = "MODULE"
+	STRUCT_OBJ       = "STRUCT"
+	INSTANCE_OBJ     = "INSTANCE"
 )
 
 type Object interface {
@@ -38,6 +42,17 @@ func IsNumber(obj Object) bool {
 	return obj.Type() == INTEGER_OBJ || obj.Type() == FLOAT_OBJ
 }
 
+/*
+Get the name of the type of an object as it is shown to users, which is the name of the struct for instances
+*/
+func TypeName(obj Object) string {
+	if instance, ok := obj.(*Instance); ok {
+		return instance.Struct.Name
+	}
+
+	return string(obj.Type())
+}
+
 type Null struct {
 	node ast.Node
 }
@@ -102,6 +117,24 @@ func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 func (rv *ReturnValue) Node() ast.Node   { return rv.node }
 
+/*
+A call in tail position that is returned as the value of a function, so the call of the function
+can be replaced by it instead of nesting it
+*/
+type TailCall struct {
+	node      ast.Node
+	Function  *Function
+	Arguments []Object
+}
+
+func NewTailCall(node ast.Node, function *Function, args []Object) *TailCall {
+	return &TailCall{node: node, Function: function, Arguments: args}
+}
+
+func (tc *TailCall) Type() ObjectType { return TAIL_CALL_OBJ }
+func (tc *TailCall) Inspect() string  { return "tail call" }
+func (tc *TailCall) Node() ast.Node   { return tc.node }
+
 type Break struct {
 	node ast.Node
 }
@@ -129,24 +162,113 @@ func (c *Continue) Node() ast.Node   { return c.node }
 type Error struct {
 	node    ast.Node
 	Message string
+
+	Description string  // the message without the location prefix
+	Value       Object  // the value passed to throw, nil for runtime errors
+	Stack       []Frame // the calls that were running when the error happened, outermost first
+	File        string  // the file the error happened in, empty when no file is set
+}
+
+/*
+A call of a function, builtin or method, or the import of a module
+*/
+type Frame struct {
+	Name string   // The name of the function that was called
+	Node ast.Node // Where the function was called
+	File string   // The file the function was called in, empty when no file is set
 }
 
 func NewError(node ast.Node, format string, a ...interface{}) *Error {
 	location := fmt.Sprintf("[%d:%d]", node.Line(), node.Column())
-	message := location + " " + fmt.Sprintf(format, a...)
+	description := fmt.Sprintf(format, a...)
+	message := location + " " + description
 
-	return &Error{node: node, Message: message}
+	return &Error{node: node, Message: message, Description: description}
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
 func (e *Error) Node() ast.Node   { return e.node }
 
+/*
+Get a traceback of the calls that were running when the error happened, like:
+
+	Traceback (most recent call last):
+	  File "main.vorn", line 9, column 6, in <program>
+	  File "stats.vorn", line 4, column 17, in average
+	  File "stats.vorn", line 2, column 14, in sum
+
+Each line holds where the next call was made, and the last line where the error happened.
+Lines that repeat more than three times in a row, like in deep recursion, are only counted.
+
+Returns an empty string when the error did not happen inside a call.
+*/
+func (e *Error) Traceback() string {
+	if len(e.Stack) == 0 {
+		return ""
+	}
+
+	var out bytes.Buffer
+
+	out.WriteString("Traceback (most recent call last):\n")
+
+	previous := ""
+	repeated := 0
+
+	writeLine := func(file string, node ast.Node, name string) {
+		line := fmt.Sprintf("  line %d, column %d, in %s\n", node.Line(), node.Column(), name)
+
+		if file != "" {
+			line = fmt.Sprintf("  File %q,%s", filepath.Base(file), line[1:])
+		}
+
+		if line == previous {
+			repeated++
+		} else {
+			writeRepeated(&out, repeated)
+			previous = line
+			repeated = 0
+		}
+
+		if repeated < 3 {
+			out.WriteString(line)
+		}
+	}
+
+	for i, frame := range e.Stack {
+		caller := "<program>"
+
+		if i > 0 {
+			caller = e.Stack[i-1].Name
+		}
+
+		writeLine(frame.File, frame.Node, caller)
+	}
+
+	writeLine(e.File, e.node, e.Stack[len(e.Stack)-1].Name)
+	writeRepeated(&out, repeated)
+
+	return out.String()
+}
+
+func writeRepeated(out *bytes.Buffer, repeated int) {
+	if repeated >= 3 {
+		fmt.Fprintf(out, "  [Previous line repeated %d more times]\n", repeated-2)
+	}
+}
+
 type Function struct {
 	node      ast.Node
+	Name      string // Name of the function used in error messages, empty for anonymous functions
 	Arguments []*ast.Identifier
+	Defaults  map[string]ast.Expression
+	Rest      *ast.Identifier
+	Patterns  map[string]ast.Expression
 	Body      *ast.BlockStatement
 	Env       *Environment
+	Slots     int    // The size of the frame the arguments are bound in
+	Compiled  any    // The bytecode of the body when the function was created by the vm, nil otherwise
+	File      string // The file the function was defined in, empty when no file is set
 }
 
 func NewFunction(node ast.Node, args []*ast.Identifier, body *ast.BlockStatement, env *Environment) *Function {
@@ -157,15 +279,9 @@ func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
 func (f *Function) Inspect() string {
 	var out bytes.Buffer
 
-	args := []string{}
-
-	for _, p := range f.Arguments {
-		args = append(args, p.String())
-	}
-
 	out.WriteString("func")
 	out.WriteString("(")
-	out.WriteString(strings.Join(args, ", "))
+	out.WriteString(ast.FormatArguments(f.Arguments, f.Defaults, f.Rest))
 	out.WriteString(") ")
 	out.WriteString(f.Body.String())
 	out.WriteString("\n")
@@ -174,6 +290,35 @@ func (f *Function) Inspect() string {
 }
 func (f *Function) Node() ast.Node { return f.node }
 
+/*
+Get the name of the function for use in error messages
+*/
+func (f *Function) DisplayName() string {
+	if f.Name == "" {
+		return "anonymous function"
+	}
+
+	return f.Name
+}
+
+/*
+Get the minimum amount of arguments the function needs, which are all arguments without a default value
+*/
+func (f *Function) MinArguments() int {
+	return len(f.Arguments) - len(f.Defaults)
+}
+
+/*
+Get the maximum amount of arguments the function accepts, -1 if it has a rest argument and accepts any amount
+*/
+func (f *Function) MaxArguments() int {
+	if f.Rest != nil {
+		return -1
+	}
+
+	return len(f.Arguments)
+}
+
 type String struct {
 	node  ast.Node
 	Value string
@@ -191,6 +336,7 @@ type BuiltinFunction func(node ast.Node, args ...Object) Object
 
 type Builtin struct {
 	node           ast.Node
+	Name           string // Name of the builtin used in tracebacks
 	Function       BuiltinFunction
 	ArgumentsCount int
 }
@@ -213,25 +359,18 @@ func NewArray(node ast.Node, elements []Object) *Array {
 }
 
 func (arr *Array) Type() ObjectType { return ARRAY_OBJ }
-func (arr *Array) Inspect() string {
-	var out bytes.Buffer
-	elements := []string{}
-
-	for _, e := range arr.Elements {
-		elements = append(elements, e.Inspect())
-	}
-
-	out.WriteString("[")
-	out.WriteString(strings.Join(elements, ", "))
-	out.WriteString("]")
+func (arr *Array) Inspect() string  { return inspect(arr, map[Object]bool{}, nil) }
+func (arr *Array) Node() ast.Node   { return arr.node }
 
-	return out.String()
-}
-func (arr *Array) Node() ast.Node { return arr.node }
+/*
+The key a value is stored under in a hash. Keys are equal exactly when the values they are made of are equal with ==.
 
+Strings, arrays and hashes keep their whole contents in Content, so different keys never share a HashKey.
+*/
 type HashKey struct {
-	Type  ObjectType
-	Value uint64
+	Type    ObjectType
+	Value   uint64
+	Content string
 }
 
 type HashPair struct {
@@ -242,28 +381,100 @@ type HashPair struct {
 type Hash struct {
 	node  ast.Node
 	Pairs map[HashKey]HashPair
+	Keys  []HashKey // The keys of the pairs in the order they were added, use Set to add pairs
+}
+
+func NewHash(node ast.Node) *Hash {
+	return &Hash{node: node, Pairs: map[HashKey]HashPair{}}
 }
 
-func NewHash(node ast.Node, pairs map[HashKey]HashPair) *Hash {
-	return &Hash{node: node, Pairs: pairs}
+/*
+Set the pair stored under a key. Keys that are new to the hash are ordered after all existing keys,
+replacing the pair of an existing key keeps its position.
+*/
+func (h *Hash) Set(key HashKey, pair HashPair) {
+	// Arrays and hashes used as keys are copied, so changing them afterwards does not change the stored key
+	switch pair.Key.(type) {
+	case *Array, *Hash:
+		pair.Key = Clone(pair.Key.Node(), pair.Key)
+	}
+
+	if _, ok := h.Pairs[key]; !ok {
+		h.Keys = append(h.Keys, key)
+	}
+
+	h.Pairs[key] = pair
 }
 
 func (h *Hash) Type() ObjectType { return HASH_OBJ }
-func (h *Hash) Inspect() string {
-	var out bytes.Buffer
-	pairs := []string{}
+func (h *Hash) Inspect() string  { return inspect(h, map[Object]bool{}, nil) }
+func (h *Hash) Node() ast.Node   { return h.node }
+
+type Module struct {
+	node    ast.Node
+	Name    string
+	Path    string
+	Env     *Environment
+	Exports []string
+}
 
-	for _, pair := range h.Pairs {
-		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
+func NewModule(node ast.Node, name string, path string, env *Environment, exports []string) *Module {
+	return &Module{node: node, Name: name, Path: path, Env: env, Exports: exports}
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string  { return fmt.Sprintf("module %s (%s)", m.Name, m.Path) }
+func (m *Module) Node() ast.Node   { return m.node }
+
+/*
+Get an exported member of the module by name.
+
+Returns the member and a boolean indicating if the module exports a member with the given name.
+*/
+func (m *Module) Get(name string) (Object, bool) {
+	if !slices.Contains(m.Exports, name) {
+		return nil, false
 	}
 
-	out.WriteString("{")
-	out.WriteString(strings.Join(pairs, ", "))
-	out.WriteString("}")
+	return m.Env.GetFromCurrent(name)
+}
 
-	return out.String()
+type Struct struct {
+	node     ast.Node
+	Name     string
+	Fields   []string
+	Defaults map[string]ast.Expression
+	Methods  map[string]*Function
+	Env      *Environment // The environment the default values of the fields are evaluated in
+}
+
+func NewStruct(node ast.Node, name string, fields []string, defaults map[string]ast.Expression, methods map[string]*Function, env *Environment) *Struct {
+	return &Struct{node: node, Name: name, Fields: fields, Defaults: defaults, Methods: methods, Env: env}
 }
-func (h *Hash) Node() ast.Node { return h.node }
+
+func (s *Struct) Type() ObjectType { return STRUCT_OBJ }
+func (s *Struct) Inspect() string  { return "struct " + s.Name }
+func (s *Struct) Node() ast.Node   { return s.node }
+
+/*
+An instance of a user defined struct.
+
+All instances share the INSTANCE type, so a struct named after a built-in type is never mistaken for it.
+The name of its struct is what users see as its type, see TypeName.
+*/
+type Instance struct {
+	node   ast.Node
+	Struct *Struct
+	Fields map[string]Object
+}
+
+func NewInstance(node ast.Node, s *Struct, fields map[string]Object) *Instance {
+	return &Instance{node: node, Struct: s, Fields: fields}
+}
+
+func (i *Instance) Type() ObjectType { return INSTANCE_OBJ }
+func (i *Instance) Inspect() string  { return inspect(i, map[Object]bool{}, nil) }
+func (i *Instance) Node() ast.Node   { return i.node }
 
 type Hashable interface {
 	HashKey() HashKey
@@ -286,10 +497,7 @@ func (i *Integer) HashKey() HashKey {
 }
 
 func (s *String) HashKey() HashKey {
-	h := fnv.New64a()
-	h.Write([]byte(s.Value))
-
-	return HashKey{Type: s.Type(), Value: h.Sum64()}
+	return HashKey{Type: s.Type(), Content: s.Value}
 }
 
 // Reusable objects for TRUE, FALSE and NULL
@@ -321,6 +529,11 @@ var (
 )
 
 func Clone(node ast.Node, object Object) Object {
+	// Instances are passed by reference, so the copy shares its fields with the original
+	if instance, ok := object.(*Instance); ok {
+		return NewInstance(node, instance.Struct, instance.Fields)
+	}
+
 	switch object.Type() {
 	case NULL_OBJ:
 		return newNull(node)
@@ -337,13 +550,32 @@ func Clone(node ast.Node, object Object) Object {
 	case CONTINUE_OBJ:
 		return NewContinue(node)
 	case ERROR_OBJ:
-		return &Error{node: node, Message: object.(*Error).Message}
+		return &Error{
+			node:        node,
+			Message:     object.(*Error).Message,
+			Description: object.(*Error).Description,
+			Value:       object.(*Error).Value,
+			Stack:       object.(*Error).Stack,
+			File:        object.(*Error).File,
+		}
 	case FUNCTION_OBJ:
-		return NewFunction(node, object.(*Function).Arguments, object.(*Function).Body, object.(*Function).Env)
+		function := NewFunction(node, object.(*Function).Arguments, object.(*Function).Body, object.(*Function).Env)
+		function.Name = object.(*Function).Name
+		function.Defaults = object.(*Function).Defaults
+		function.Rest = object.(*Function).Rest
+		function.Patterns = object.(*Function).Patterns
+		function.Compiled = object.(*Function).Compiled
+		function.Slots = object.(*Function).Slots
+		function.File = object.(*Function).File
+
+		return function
 	case STRING_OBJ:
 		return NewString(node, object.(*String).Value)
 	case BUILTIN_OBJ:
-		return NewBuiltin(node, object.(*Builtin).Function)
+		builtin := NewBuiltin(node, object.(*Builtin).Function)
+		builtin.Name = object.(*Builtin).Name
+
+		return builtin
 	case ARRAY_OBJ:
 		elements := make([]Object, len(object.(*Array).Elements))
 
@@ -353,15 +585,23 @@ func Clone(node ast.Node, object Object) Object {
 
 		return NewArray(node, elements)
 	case HASH_OBJ:
-		pairs := make(map[HashKey]HashPair)
+		hash := NewHash(node)
 
-		for key, pair := range object.(*Hash).Pairs {
-			pairCopy := HashPair{Key: Clone(node, pair.Key), Value: Clone(node, pair.Value)}
+		for _, key := range object.(*Hash).Keys {
+			pair := object.(*Hash).Pairs[key]
 
-			pairs[key] = pairCopy
+			hash.Set(key, HashPair{Key: Clone(node, pair.Key), Value: Clone(node, pair.Value)})
 		}
 
-		return NewHash(node, object.(*Hash).Pairs)
+		return hash
+	case MODULE_OBJ:
+		module := object.(*Module)
+
+		return NewModule(node, module.Name, module.Path, module.Env, module.Exports)
+	case STRUCT_OBJ:
+		s := object.(*Struct)
+
+		return NewStruct(node, s.Name, s.Fields, s.Defaults, s.Methods, s.Env)
 	default:
 		return newNull(node)
 	}
@@ -69,6 +69,20 @@ func TestHashKey(t *testing.T) {
 	}
 }
 
+/*
+Create a hash containing the given pairs in order
+*/
+func newTestHash(node ast.Node, pairs ...HashPair) *Hash {
+	hash := NewHash(node)
+
+	for _, pair := range pairs {
+		key, _ := HashKeyOf(pair.Key)
+		hash.Set(key, pair)
+	}
+
+	return hash
+}
+
 type Nonexistent struct {
 	node ast.Node
 }
@@ -547,7 +561,7 @@ func TestObjectClone(t *testing.T) {
 					},
 				},
 			},
-			object: NewHash(&ast.HashLiteral{
+			object: newTestHash(&ast.HashLiteral{
 				Token: token.Token{
 					Type:    token.LBRACE,
 					Literal: "{",
@@ -569,55 +583,41 @@ func TestObjectClone(t *testing.T) {
 						Value: 1,
 					},
 				},
-			}, map[HashKey]HashPair{
-				NewString(&ast.StringLiteral{
+			}, HashPair{
+				Key: NewString(&ast.StringLiteral{
 					Token: token.Token{
 						Type:    token.STRING,
 						Literal: "a",
 					},
 					Value: "a",
-				}, "a").HashKey(): {
-					Key: NewString(&ast.StringLiteral{
-						Token: token.Token{
-							Type:    token.STRING,
-							Literal: "a",
-						},
-						Value: "a",
-					}, "a"),
-					Value: NewInteger(&ast.IntegerLiteral{
-						Token: token.Token{
-							Type:    token.INT,
-							Literal: "1",
-						},
-						Value: 1,
-					}, 1),
-				},
+				}, "a"),
+				Value: NewInteger(&ast.IntegerLiteral{
+					Token: token.Token{
+						Type:    token.INT,
+						Literal: "1",
+					},
+					Value: 1,
+				}, 1),
 			}),
 			modifyFunction: func(obj Object) {
-				obj.(*Hash).Pairs = map[HashKey]HashPair{
-					NewString(&ast.StringLiteral{
+				key := NewString(&ast.StringLiteral{
+					Token: token.Token{
+						Type:    token.STRING,
+						Literal: "b",
+					},
+					Value: "b",
+				}, "b")
+
+				obj.(*Hash).Set(key.HashKey(), HashPair{
+					Key: key,
+					Value: NewInteger(&ast.IntegerLiteral{
 						Token: token.Token{
-							Type:    token.STRING,
-							Literal: "b",
+							Type:    token.INT,
+							Literal: "2",
 						},
-						Value: "b",
-					}, "b").HashKey(): {
-						Key: NewString(&ast.StringLiteral{
-							Token: token.Token{
-								Type:    token.STRING,
-								Literal: "b",
-							},
-							Value: "b",
-						}, "b"),
-						Value: NewInteger(&ast.IntegerLiteral{
-							Token: token.Token{
-								Type:    token.INT,
-								Literal: "2",
-							},
-							Value: 2,
-						}, 2),
-					},
-				}
+						Value: 2,
+					}, 2),
+				})
 			},
 		},
 		{
@@ -712,12 +712,13 @@ func TestNewFunctions(t *testing.T) {
 			NewInteger(&ast.IntegerLiteral{Value: 1}, 1),
 			NewInteger(&ast.IntegerLiteral{Value: 2}, 2),
 		}), "[1, 2]"},
-		{HASH_OBJ, NewHash(&ast.HashLiteral{}, map[HashKey]HashPair{
-			NewString(&ast.StringLiteral{Value: "a"}, "a").HashKey(): {
-				Key:   NewString(&ast.StringLiteral{Value: "a"}, "a"),
-				Value: NewInteger(&ast.IntegerLiteral{Value: 1}, 1),
-			},
-		}), "{a: 1}"},
+		{HASH_OBJ, newTestHash(&ast.HashLiteral{}, HashPair{
+			Key:   NewString(&ast.StringLiteral{Value: "a"}, "a"),
+			Value: NewInteger(&ast.IntegerLiteral{Value: 1}, 1),
+		}, HashPair{
+			Key:   NewString(&ast.StringLiteral{Value: "b"}, "b"),
+			Value: NewInteger(&ast.IntegerLiteral{Value: 2}, 2),
+		}), "{a: 1, b: 2}"},
 		{NULL_OBJ, newNull(&ast.NullLiteral{}), "null"},
 		{RETURN_VALUE_OBJ, NewReturnValue(&ast.ReturnStatement{}, NewInteger(&ast.IntegerLiteral{Value: 2}, 2)), "2"},
 		{BREAK_OBJ, NewBreak(&ast.BreakExpression{}), "break"},
@@ -768,3 +769,52 @@ func TestNewFunctions(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorTraceback(t *testing.T) {
+	at := func(line, column int) ast.Node {
+		return &ast.Identifier{Token: token.Token{Type: token.IDENT, Line: line, Column: column}}
+	}
+
+	tests := []struct {
+		stack    []Frame
+		expected string
+	}{
+		{nil, ""},
+		{
+			[]Frame{{Name: "average", Node: at(9, 6)}, {Name: "sum", Node: at(4, 17)}},
+			"Traceback (most recent call last):\n" +
+				"  line 9, column 6, in <program>\n" +
+				"  line 4, column 17, in average\n" +
+				"  line 2, column 3, in sum\n",
+		},
+		{
+			[]Frame{{Name: "f", Node: at(5, 1)}, {Name: "f", Node: at(2, 3)}, {Name: "f", Node: at(2, 3)}, {Name: "f", Node: at(2, 3)}, {Name: "f", Node: at(2, 3)}},
+			"Traceback (most recent call last):\n" +
+				"  line 5, column 1, in <program>\n" +
+				"  line 2, column 3, in f\n" +
+				"  line 2, column 3, in f\n" +
+				"  line 2, column 3, in f\n" +
+				"  [Previous line repeated 2 more times]\n",
+		},
+		{
+			[]Frame{{Name: "run", Node: at(7, 11), File: "/scripts/main.vorn"}, {Name: "helper", Node: at(4, 24), File: "/scripts/main.vorn"}},
+			"Traceback (most recent call last):\n" +
+				"  File \"main.vorn\", line 7, column 11, in <program>\n" +
+				"  File \"main.vorn\", line 4, column 24, in run\n" +
+				"  File \"util.vorn\", line 2, column 3, in helper\n",
+		},
+	}
+
+	for _, tt := range tests {
+		err := NewError(at(2, 3), "error")
+		err.Stack = tt.stack
+
+		if len(tt.stack) > 0 && tt.stack[0].File != "" {
+			err.File = "/scripts/util.vorn"
+		}
+
+		if err.Traceback() != tt.expected {
+			t.Errorf("wrong traceback. got\n%s\nwant\n%s", err.Traceback(), tt.expected)
+		}
+	}
+}
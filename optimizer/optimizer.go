@@ -0,0 +1,526 @@
+package optimizer
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/object"
+	"github.com/iskandervdh/vorn/token"
+)
+
+/*
+The operators of the language, which the optimizer uses to fold constant expressions
+with exactly the same results as when they are evaluated
+*/
+type Operators interface {
+	Prefix(node *ast.PrefixExpression, right object.Object) object.Object
+	Infix(node *ast.InfixExpression, left, right object.Object) object.Object
+}
+
+/*
+The optimizer rewrites a program into a program that gives the same results with less work:
+
+  - Prefix and infix expressions of constants are folded into a single constant, unless they result in an error
+  - Branches of if expressions, ternaries and logical operators that can never be taken are removed
+  - Statements after a return, throw, break or continue and statements without any effect are removed
+  - Equal string literals share their value
+
+Folded constants keep the position of the expression they replace, so errors still point to the original source.
+*/
+type Optimizer struct {
+	operators Operators
+	strings   map[string]string // Interned values of string literals
+}
+
+func New(operators Operators) *Optimizer {
+	return &Optimizer{
+		operators: operators,
+		strings:   map[string]string{},
+	}
+}
+
+/*
+Optimize a program in place.
+
+The program can be resolved before it is optimized, the optimizer keeps the slots the resolver assigned.
+*/
+func (o *Optimizer) Optimize(program *ast.Program) {
+	program.Statements = o.optimizeStatements(program.Statements, true)
+}
+
+/*
+Optimize a list of statements, removing the statements that have no effect or can never be reached.
+
+In the program a break or continue only ends its own statement and the value of the last statement
+is the value of the program, so those are kept.
+*/
+func (o *Optimizer) optimizeStatements(statements []ast.Statement, program bool) []ast.Statement {
+	optimized := []ast.Statement{}
+
+	for i, statement := range statements {
+		statement = o.optimizeStatement(statement)
+		last := program && i == len(statements)-1
+
+		if !last && hasNoEffect(statement) {
+			continue
+		}
+
+		optimized = append(optimized, statement)
+
+		if endsBlock(statement, program) {
+			break
+		}
+	}
+
+	return optimized
+}
+
+/*
+Check if a statement can be removed without changing the program
+*/
+func hasNoEffect(statement ast.Statement) bool {
+	switch statement := statement.(type) {
+	case *ast.ExpressionStatement:
+		_, constant := constantValue(statement.Expression)
+
+		return constant
+	case *ast.WhileStatement:
+		truthy, constant := constantTruthiness(statement.Condition)
+
+		return constant && !truthy
+	}
+
+	return false
+}
+
+/*
+Check if the statements after a statement can never be reached
+*/
+func endsBlock(statement ast.Statement, program bool) bool {
+	switch statement := statement.(type) {
+	case *ast.ReturnStatement, *ast.ThrowStatement:
+		return true
+	case *ast.ExpressionStatement:
+		switch statement.Expression.(type) {
+		case *ast.BreakExpression, *ast.ContinueExpression:
+			return !program
+		}
+	}
+
+	return false
+}
+
+func (o *Optimizer) optimizeBlock(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+
+	block.Statements = o.optimizeStatements(block.Statements, false)
+}
+
+func (o *Optimizer) optimizeStatement(statement ast.Statement) ast.Statement {
+	switch statement := statement.(type) {
+	case *ast.ExpressionStatement:
+		statement.Expression = o.optimizeExpression(statement.Expression)
+
+		// An if statement that always takes the same branch is replaced by the block of that branch
+		if ie, ok := statement.Expression.(*ast.IfExpression); ok {
+			if truthy, constant := constantTruthiness(ie.Condition); constant && truthy {
+				return ie.Consequence
+			}
+		}
+
+	case *ast.VariableStatement:
+		statement.Value = o.optimizeExpression(statement.Value)
+		o.optimizePattern(statement.Pattern)
+
+	case *ast.ReturnStatement:
+		statement.ReturnValue = o.optimizeExpression(statement.ReturnValue)
+
+	case *ast.BlockStatement:
+		o.optimizeBlock(statement)
+
+	case *ast.FunctionStatement:
+		o.optimizeFunction(statement.Defaults, statement.Patterns, statement.Body)
+
+	case *ast.StructStatement:
+		o.optimizeDefaults(statement.Defaults)
+
+		for _, method := range statement.Methods {
+			o.optimizeFunction(method.Defaults, method.Patterns, method.Body)
+		}
+
+	case *ast.WhileStatement:
+		statement.Condition = o.optimizeExpression(statement.Condition)
+		o.optimizeBlock(statement.Consequence)
+
+	case *ast.ForStatement:
+		if statement.Init != nil {
+			statement.Init = o.optimizeStatement(statement.Init)
+		}
+
+		statement.Condition = o.optimizeExpression(statement.Condition)
+		statement.Update = o.optimizeExpression(statement.Update)
+		o.optimizeBlock(statement.Body)
+
+	case *ast.ForInStatement:
+		statement.Iterable = o.optimizeExpression(statement.Iterable)
+		o.optimizeBlock(statement.Body)
+
+	case *ast.TryStatement:
+		o.optimizeBlock(statement.Block)
+		o.optimizeBlock(statement.CatchBlock)
+		o.optimizeBlock(statement.FinallyBlock)
+
+	case *ast.ThrowStatement:
+		statement.Value = o.optimizeExpression(statement.Value)
+
+	case *ast.ExportStatement:
+		statement.Statement = o.optimizeStatement(statement.Statement)
+	}
+
+	return statement
+}
+
+func (o *Optimizer) optimizeFunction(defaults map[string]ast.Expression, patterns map[string]ast.Expression, body *ast.BlockStatement) {
+	o.optimizeDefaults(defaults)
+
+	for _, pattern := range patterns {
+		o.optimizePattern(pattern)
+	}
+
+	o.optimizeBlock(body)
+}
+
+func (o *Optimizer) optimizeDefaults(defaults map[string]ast.Expression) {
+	for name, defaultValue := range defaults {
+		defaults[name] = o.optimizeExpression(defaultValue)
+	}
+}
+
+/*
+Optimize the default values of the elements of a destructuring pattern
+*/
+func (o *Optimizer) optimizePattern(pattern ast.Expression) {
+	var elements []*ast.PatternElement
+
+	switch pattern := pattern.(type) {
+	case *ast.ArrayPattern:
+		elements = pattern.Elements
+	case *ast.HashPattern:
+		elements = pattern.Elements
+	}
+
+	for _, element := range elements {
+		if element.Default != nil {
+			element.Default = o.optimizeExpression(element.Default)
+		}
+
+		o.optimizePattern(element.Target)
+	}
+}
+
+func (o *Optimizer) optimizeExpressions(expressions []ast.Expression) {
+	for i, expression := range expressions {
+		expressions[i] = o.optimizeExpression(expression)
+	}
+}
+
+/*
+Optimize an expression.
+
+Returns the expression that replaces it, which is the expression itself when it can not be replaced.
+*/
+func (o *Optimizer) optimizeExpression(expression ast.Expression) ast.Expression {
+	switch expression := expression.(type) {
+	case *ast.StringLiteral:
+		expression.Value = o.intern(expression.Value)
+
+	case *ast.PrefixExpression:
+		expression.Right = o.optimizeExpression(expression.Right)
+
+		return o.foldPrefix(expression)
+
+	case *ast.InfixExpression:
+		expression.Left = o.optimizeExpression(expression.Left)
+		expression.Right = o.optimizeExpression(expression.Right)
+
+		if expression.Operator == token.AND || expression.Operator == token.OR {
+			return o.optimizeLogicalExpression(expression)
+		}
+
+		return o.foldInfix(expression)
+
+	case *ast.IfExpression:
+		return o.optimizeIfExpression(expression)
+
+	case *ast.TernaryExpression:
+		expression.Condition = o.optimizeExpression(expression.Condition)
+		expression.Consequence = o.optimizeExpression(expression.Consequence)
+		expression.Alternative = o.optimizeExpression(expression.Alternative)
+
+		if truthy, constant := constantTruthiness(expression.Condition); constant {
+			if truthy {
+				return expression.Consequence
+			}
+
+			return expression.Alternative
+		}
+
+	case *ast.FunctionLiteral:
+		o.optimizeFunction(expression.Defaults, expression.Patterns, expression.Body)
+
+	case *ast.CallExpression:
+		expression.Function = o.optimizeExpression(expression.Function)
+		o.optimizeExpressions(expression.Arguments)
+
+	case *ast.IndexExpression:
+		expression.Left = o.optimizeExpression(expression.Left)
+		expression.Index = o.optimizeExpression(expression.Index)
+
+	case *ast.ReassignmentExpression:
+		expression.Value = o.optimizeExpression(expression.Value)
+
+	case *ast.MemberReassignmentExpression:
+		o.optimizeExpression(expression.Target)
+		expression.Value = o.optimizeExpression(expression.Value)
+
+	case *ast.MemberIncrementDecrementExpression:
+		o.optimizeExpression(expression.Target)
+
+	case *ast.ChainingExpression:
+		expression.Left = o.optimizeExpression(expression.Left)
+		o.optimizeChainingRight(expression.Right)
+
+	case *ast.SpreadExpression:
+		expression.Value = o.optimizeExpression(expression.Value)
+
+	case *ast.ArrayLiteral:
+		o.optimizeExpressions(expression.Elements)
+
+	case *ast.HashLiteral:
+		pairs := make(map[ast.Expression]ast.Expression, len(expression.Pairs))
+
+		for i, key := range expression.Keys {
+			value, ok := expression.Pairs[key]
+			expression.Keys[i] = o.optimizeExpression(key)
+
+			if ok {
+				pairs[expression.Keys[i]] = o.optimizeExpression(value)
+			}
+		}
+
+		expression.Pairs = pairs
+
+	case *ast.TemplateLiteral:
+		o.optimizeExpressions(expression.Parts)
+
+	case *ast.MatchExpression:
+		expression.Subject = o.optimizeExpression(expression.Subject)
+
+		for _, arm := range expression.Arms {
+			arm.Guard = o.optimizeExpression(arm.Guard)
+			arm.Body = o.optimizeExpression(arm.Body)
+		}
+	}
+
+	return expression
+}
+
+/*
+Optimize the arguments of a method call on the right-hand side of a chaining expression
+*/
+func (o *Optimizer) optimizeChainingRight(right ast.Node) {
+	switch right := right.(type) {
+	case *ast.CallExpression:
+		o.optimizeExpressions(right.Arguments)
+	case *ast.ExpressionStatement:
+		o.optimizeChainingRight(right.Expression)
+	}
+}
+
+/*
+Remove the branch of an if expression that is never taken when its condition is a constant.
+
+An if expression without a branch that is taken is replaced by null, which is its value.
+As a statement the if expression is replaced by the block of the branch that is taken.
+*/
+func (o *Optimizer) optimizeIfExpression(ie *ast.IfExpression) ast.Expression {
+	ie.Condition = o.optimizeExpression(ie.Condition)
+	o.optimizeBlock(ie.Consequence)
+	o.optimizeBlock(ie.Alternative)
+
+	truthy, constant := constantTruthiness(ie.Condition)
+
+	switch {
+	case !constant:
+		return ie
+	case truthy:
+		ie.Alternative = nil
+
+		return ie
+	case ie.Alternative == nil:
+		return &ast.NullLiteral{Token: token.Token{Type: token.NULL, Literal: "null", Line: ie.Line(), Column: ie.Column()}}
+	}
+
+	// Only the alternative is taken, so it becomes the consequence of an if expression that is always true
+	ie.Condition = &ast.BooleanLiteral{
+		Token: token.Token{Type: token.TRUE, Literal: "true", Line: ie.Condition.Line(), Column: ie.Condition.Column()},
+		Value: true,
+	}
+	ie.Consequence = ie.Alternative
+	ie.Alternative = nil
+
+	return ie
+}
+
+/*
+Replace a logical && or || expression with its left-hand side when it determines the result,
+or with its right-hand side when it does not
+*/
+func (o *Optimizer) optimizeLogicalExpression(node *ast.InfixExpression) ast.Expression {
+	truthy, constant := constantTruthiness(node.Left)
+
+	if !constant {
+		return node
+	}
+
+	if truthy == (node.Operator == token.OR) {
+		return node.Left
+	}
+
+	return node.Right
+}
+
+func (o *Optimizer) foldPrefix(node *ast.PrefixExpression) ast.Expression {
+	right, ok := constantValue(node.Right)
+
+	if !ok {
+		return node
+	}
+
+	return o.literal(node, o.operators.Prefix(node, right))
+}
+
+func (o *Optimizer) foldInfix(node *ast.InfixExpression) ast.Expression {
+	left, ok := constantValue(node.Left)
+
+	if !ok {
+		return node
+	}
+
+	right, ok := constantValue(node.Right)
+
+	if !ok {
+		return node
+	}
+
+	return o.literal(node, o.operators.Infix(node, left, right))
+}
+
+/*
+Get the value of an expression that is a constant, in the same way the evaluator creates it
+*/
+func constantValue(expression ast.Expression) (object.Object, bool) {
+	switch expression := expression.(type) {
+	case *ast.IntegerLiteral:
+		return object.NewInteger(expression, expression.Value), true
+	case *ast.FloatLiteral:
+		return object.NewFloat(expression, expression.Value), true
+	case *ast.StringLiteral:
+		return object.NewString(expression, expression.Value), true
+	case *ast.BooleanLiteral:
+		if expression.Value {
+			return object.TRUE, true
+		}
+
+		return object.FALSE, true
+	case *ast.NullLiteral:
+		return object.NULL, true
+	}
+
+	return nil, false
+}
+
+/*
+Check if an expression is a constant and if so, whether it is truthy
+*/
+func constantTruthiness(expression ast.Expression) (bool, bool) {
+	value, ok := constantValue(expression)
+
+	if !ok {
+		return false, false
+	}
+
+	return value != object.NULL && value != object.FALSE, true
+}
+
+/*
+Create the literal of the result of folding an expression.
+
+The literal gets the position the evaluator would have given the result. When the result is an error,
+or a value that has no literal, the expression is returned instead so it still fails when it is evaluated.
+Infinite and NaN floats have no literal either.
+*/
+func (o *Optimizer) literal(expression ast.Expression, result object.Object) ast.Expression {
+	var position ast.Node = expression
+
+	if result.Node() != nil {
+		position = result.Node()
+	}
+
+	newToken := func(tokenType token.TokenType, literal string) token.Token {
+		return token.Token{Type: tokenType, Literal: literal, Line: position.Line(), Column: position.Column()}
+	}
+
+	switch result := result.(type) {
+	case *object.Integer:
+		return &ast.IntegerLiteral{Token: newToken(token.INT, strconv.FormatInt(result.Value, 10)), Value: result.Value}
+	case *object.Float:
+		if math.IsInf(result.Value, 0) || math.IsNaN(result.Value) {
+			return expression
+		}
+
+		return &ast.FloatLiteral{Token: newToken(token.FLOAT, formatFloat(result.Value)), Value: result.Value}
+	case *object.String:
+		value := o.intern(result.Value)
+
+		return &ast.StringLiteral{Token: newToken(token.STRING, value), Value: value}
+	case *object.Boolean:
+		if result.Value {
+			return &ast.BooleanLiteral{Token: newToken(token.TRUE, "true"), Value: true}
+		}
+
+		return &ast.BooleanLiteral{Token: newToken(token.FALSE, "false"), Value: false}
+	}
+
+	return expression
+}
+
+/*
+Format a float so it is read back as a float, e.g. 2.0 instead of 2
+*/
+func formatFloat(value float64) string {
+	formatted := strconv.FormatFloat(value, 'g', -1, 64)
+
+	if !strings.ContainsAny(formatted, ".e") {
+		formatted += ".0"
+	}
+
+	return formatted
+}
+
+/*
+Get the shared copy of a string literal value
+*/
+func (o *Optimizer) intern(value string) string {
+	if interned, ok := o.strings[value]; ok {
+		return interned
+	}
+
+	o.strings[value] = value
+
+	return value
+}
@@ -0,0 +1,148 @@
+package optimizer
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/evaluator"
+	"github.com/iskandervdh/vorn/lexer"
+	"github.com/iskandervdh/vorn/object"
+	"github.com/iskandervdh/vorn/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l, false)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors for %q: %v", input, p.Errors())
+	}
+
+	return program
+}
+
+func optimize(t *testing.T, input string) *ast.Program {
+	program := parse(t, input)
+	New(evaluator.New()).Optimize(program)
+
+	return program
+}
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"60 * 60 * 24;", "86400"},
+		{"7 / 2;", "3.5"},
+		{"4 / 2;", "2.0"},
+		{"1.5 * 2;", "3.0"},
+		{"1e308 * 10.0;", "(1e308 * 10.0)"},
+		{"-1e308 * 10.0;", "(-1e+308 * 10.0)"},
+		{"2 << 10;", "2048"},
+		{"-(5);", "-5"},
+		{"!true;", "false"},
+		{"1 < 2;", "true"},
+		{"1 == 1.0;", "true"},
+		{`"a" + "b";`, `"ab"`},
+		{`"ab" == "ab";`, "true"},
+		{"1 / 0;", "(1 / 0)"},
+		{`1 + "a";`, `(1 + "a")`},
+		{"x + 1 * 2;", "(x + 2)"},
+		{"false || x;", "x"},
+		{"true || x;", "true"},
+		{"null && x;", "null"},
+		{"1 && x;", "x"},
+		{"x || true;", "(x || true)"},
+		{"true ? a : b;", "a"},
+		{"null ? a : b;", "b"},
+		{"x ? 1 + 1 : 2;", "(x ? 2 : 2)"},
+		{"if (false) { print(1); } x;", "x"},
+		{"if (true) { a; } else { b; }", "{\n  a\n}"},
+		{"if (false) { a; } else { b; }", "{\n  b\n}"},
+		{"if (false) { a; } else { if (true) { b; } }", "{\n  {\n    b\n}\n}"},
+		{"let a = if (false) { a; } else { b; };", "let a = if (true) {\n  b\n};"},
+		{"let a = if (false) { a; };", "let a = null;"},
+		{"if (x) { a; } else { b; }", "if (x) {\n  a\n} else {\n  b\n}"},
+		{"1; 2; x; 3;", "x3"},
+		{"while (false) { a; } x;", "x"},
+		{"func f() { return 1; a; }", "func f() {\n  return 1;\n}"},
+		{"func f() { throw 1; a; }", "func f() {\n  throw 1;\n}"},
+		{"while (x) { break; a; }", "while (x) {\n  break\n}"},
+		{"for (let i = 0; i < 1 + 1; i++) { continue; a; }", "for (let i = 0;; (i < 2); ++i) {\n    continue\n}"},
+		{"break; x;", "breakx"},
+		{"return 1 + 1; x;", "return 2;"},
+		{"let [a = 1 + 1] = x;", "let [a = 2] = x;"},
+		{"func f(a = 2 * 3) { return a; }", "func f(a = 6) {\n  return a;\n}"},
+		{"let f = func(a) { return a + (1 + 2); };", "let f = func(a) {\n  return (a + 3);\n};"},
+		{"struct P { x = 1 + 1; func get() { return 2 * 2; } }", "struct P { x = 2; func get() {\n  return 4;\n} }"},
+		{"[1 + 1, ...[2 * 2]];", "[2, ...[4]]"},
+		{`{"a" + "b": 1 + 1};`, `{"ab": 2}`},
+		{`"x${1 + 1}";`, `"x${2}"`},
+		{"f(1 + 1)[2 - 1];", "(f(2)[1])"},
+		{"a.map(1 + 1);", "(a.map(2))"},
+		{"a[0] = 1 + 1; a.b += 2 * 2; a.b++;", "(a[0]) = 2(a.b) += 4(a.b)++"},
+		{"a = 1 + 1;", "a = 2"},
+		{"match (1 + 1) { 2 if 1 < 2 => 3 * 3, _ => 0 };", "match 2 { 2 if true => 9, _ => 0 }"},
+		{"try { return 1; a; } catch (e) { throw e; a; } finally { 1 + 1; }", "try {\n  return 1;\n} catch (e) {\n  throw e;\n} finally {\n}"},
+		{"export let a = 1 + 1;", "export let a = 2;"},
+		{"for (x in [1 + 1]) { }", "for (x in [2]) {\n}"},
+	}
+
+	for _, tt := range tests {
+		program := optimize(t, tt.input)
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong program for %q. got %q, want %q", tt.input, program.String(), tt.expected)
+		}
+	}
+}
+
+func TestOptimizeKeepsResults(t *testing.T) {
+	tests := []string{
+		"60 * 60 * 24",
+		"7 / 2 + 1",
+		"-2 * 2 - 1",
+		"10 % 3 * 2.5",
+		`"ab" * 3`,
+		`"a" < "b"`,
+		"1 / 0",
+		"1 - true",
+		"!null",
+		"let a = 1; if (1 > 2) { a = 2; } else { a = 3; } a",
+		"func f() { return 1; f(); } f()",
+		"let a = 0; for (let i = 0; i < 3; i++) { if (true) { break; } a++; } a",
+		"let x = 1; x + (2 * 3) / 1 / 0",
+	}
+
+	for _, input := range tests {
+		expected := evaluator.New().Eval(parse(t, input), object.NewEnvironment())
+		got := evaluator.New().Eval(optimize(t, input), object.NewEnvironment())
+
+		if got.Inspect() != expected.Inspect() {
+			t.Errorf("wrong result for %q. got %s, want %s", input, got.Inspect(), expected.Inspect())
+		}
+	}
+}
+
+func TestOptimizeKeepsPositions(t *testing.T) {
+	program := optimize(t, "let a =\n  2 * 3;")
+	value := program.Statements[0].(*ast.VariableStatement).Value
+
+	if value.Line() != 2 {
+		t.Errorf("wrong line of folded constant. got %d, want 2", value.Line())
+	}
+}
+
+func TestOptimizeInternsStrings(t *testing.T) {
+	program := optimize(t, `let a = "text"; let b = "te" + "xt";`)
+
+	a := program.Statements[0].(*ast.VariableStatement).Value.(*ast.StringLiteral)
+	b := program.Statements[1].(*ast.VariableStatement).Value.(*ast.StringLiteral)
+
+	if unsafe.StringData(a.Value) != unsafe.StringData(b.Value) {
+		t.Errorf("equal string literals do not share their value")
+	}
+}
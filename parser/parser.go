@@ -17,10 +17,11 @@ type (
 )
 
 type Parser struct {
-	l      *lexer.Lexer
-	scope  ast.Scope
-	errors []string
-	trace  bool
+	l        *lexer.Lexer
+	scope    ast.Scope
+	errors   []string
+	warnings []string
+	trace    bool
 
 	currentToken token.Token
 	peekToken    token.Token
@@ -35,6 +36,8 @@ Precedence levels for the different operators
 const (
 	_ int = iota
 	LOWEST
+	ASSIGN       // =, +=, -=, ... on array elements and object properties
+	TERNARY      // ? :
 	OR           // ||
 	AND          // &&
 	BITWISE_OR   // |
@@ -54,29 +57,41 @@ const (
 Precedence levels for the tokens based on their operator type
 */
 var precedences = map[token.TokenType]int{
-	token.OR:          OR,
-	token.AND:         AND,
-	token.BITWISE_OR:  BITWISE_OR,
-	token.BITWISE_XOR: BITWISE_XOR,
-	token.BITWISE_AND: BITWISE_AND,
-	token.EQ:          EQUALS,
-	token.NOT_EQ:      EQUALS,
-	token.LT:          LESS_GREATER,
-	token.GT:          LESS_GREATER,
-	token.LTE:         LESS_GREATER,
-	token.GTE:         LESS_GREATER,
-	token.LEFT_SHIFT:  SHIFT,
-	token.RIGHT_SHIFT: SHIFT,
-	token.PLUS:        SUM,
-	token.MINUS:       SUM,
-	token.ASTERISK:    PRODUCT,
-	token.SLASH:       PRODUCT,
-	token.PERCENT:     PRODUCT,
-	token.DOT:         POSTFIX,
-	token.LPAREN:      POSTFIX,
-	token.LBRACKET:    POSTFIX,
-	token.INCREMENT:   INC_DEC,
-	token.DECREMENT:   INC_DEC,
+	token.ASSIGN:             ASSIGN,
+	token.PLUS_ASSIGN:        ASSIGN,
+	token.MINUS_ASSIGN:       ASSIGN,
+	token.MULTIPLY_ASSIGN:    ASSIGN,
+	token.DIVIDE_ASSIGN:      ASSIGN,
+	token.MODULO_ASSIGN:      ASSIGN,
+	token.BITWISE_OR_ASSIGN:  ASSIGN,
+	token.BITWISE_AND_ASSIGN: ASSIGN,
+	token.BITWISE_XOR_ASSIGN: ASSIGN,
+	token.LEFT_SHIFT_ASSIGN:  ASSIGN,
+	token.RIGHT_SHIFT_ASSIGN: ASSIGN,
+	token.QUESTION:           TERNARY,
+	token.OR:                 OR,
+	token.AND:                AND,
+	token.BITWISE_OR:         BITWISE_OR,
+	token.BITWISE_XOR:        BITWISE_XOR,
+	token.BITWISE_AND:        BITWISE_AND,
+	token.EQ:                 EQUALS,
+	token.NOT_EQ:             EQUALS,
+	token.LT:                 LESS_GREATER,
+	token.GT:                 LESS_GREATER,
+	token.LTE:                LESS_GREATER,
+	token.GTE:                LESS_GREATER,
+	token.LEFT_SHIFT:         SHIFT,
+	token.RIGHT_SHIFT:        SHIFT,
+	token.PLUS:               SUM,
+	token.MINUS:              SUM,
+	token.ASTERISK:           PRODUCT,
+	token.SLASH:              PRODUCT,
+	token.PERCENT:            PRODUCT,
+	token.DOT:                POSTFIX,
+	token.LPAREN:             POSTFIX,
+	token.LBRACKET:           POSTFIX,
+	token.INCREMENT:          INC_DEC,
+	token.DECREMENT:          INC_DEC,
 }
 
 /*
@@ -84,9 +99,10 @@ Create a new parser with the given lexer and trace flag
 */
 func New(l *lexer.Lexer, trace bool) *Parser {
 	p := &Parser{
-		l:      l,
-		errors: []string{},
-		trace:  trace,
+		l:        l,
+		errors:   []string{},
+		warnings: []string{},
+		trace:    trace,
 	}
 
 	// Read two tokens, so currentToken and peekToken are both set
@@ -124,8 +140,11 @@ func (p *Parser) registerPrefixFunctions() {
 	p.registerPrefix(token.CONTINUE, p.parseContinueExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.TEMPLATE_START, p.parseTemplateLiteral)
+	p.registerPrefix(token.ILLEGAL, p.parseIllegal)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
 	p.registerPrefix(token.INCREMENT, p.parseIncrementDecrementPrefix)
 	p.registerPrefix(token.DECREMENT, p.parseIncrementDecrementPrefix)
 }
@@ -156,14 +175,26 @@ func (p *Parser) registerInfixFunctions() {
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 	p.registerInfix(token.DOT, p.parseChainingExpression)
+	p.registerInfix(token.QUESTION, p.parseTernaryExpression)
 	p.registerInfix(token.INCREMENT, p.parseIncrementDecrement)
 	p.registerInfix(token.DECREMENT, p.parseIncrementDecrement)
+
+	for _, operator := range token.AssignmentOperators {
+		p.registerInfix(operator, p.parseMemberReassignmentExpression)
+	}
 }
 
 func (p *Parser) Errors() []string {
 	return p.errors
 }
 
+/*
+Get the warnings found while parsing, these do not stop the program from running
+*/
+func (p *Parser) Warnings() []string {
+	return p.warnings
+}
+
 func PrintErrors(out io.Writer, errors []string) {
 	io.WriteString(out, "Syntax errors:\n")
 
@@ -172,6 +203,14 @@ func PrintErrors(out io.Writer, errors []string) {
 	}
 }
 
+func PrintWarnings(out io.Writer, warnings []string) {
+	io.WriteString(out, "Warnings:\n")
+
+	for _, msg := range warnings {
+		io.WriteString(out, msg+"\n")
+	}
+}
+
 /*
 Get the precedence of the peek token
 */
@@ -248,6 +287,13 @@ func (p *Parser) peekTokenIsAssignmentOperator() bool {
 	return slices.Contains(token.AssignmentOperators, p.peekToken.Type)
 }
 
+/*
+Check if the parser is currently parsing statements at the top level of the program
+*/
+func (p *Parser) isTopLevel() bool {
+	return p.scope == nil || p.scope.GetParentScope() == nil
+}
+
 /*
 Expect the next token to be of a certain type.
 
@@ -286,27 +332,60 @@ func (p *Parser) expectReassignmentOperator() bool {
 }
 
 /*
-Check if the current token is a reassignment of a constant.
+Get the variable statement of a statement, looking inside of export statements
+*/
+func asVariableStatement(statement ast.Statement) (*ast.VariableStatement, bool) {
+	if exportStatement, ok := statement.(*ast.ExportStatement); ok && exportStatement != nil {
+		statement = exportStatement.Statement
+	}
+
+	variableStatement, ok := statement.(*ast.VariableStatement)
+
+	return variableStatement, ok
+}
+
+/*
+Get the variable and operator token of a statement that reassigns a variable, which are reassignments and ++ and --.
 
-Add an error if it is
+Returns false if the statement does not reassign a variable.
 */
-func (p *Parser) checkConstReassignment(scope ast.Scope, newStatement ast.Statement) {
-	expressionStatement, ok := newStatement.(*ast.ExpressionStatement)
+func reassignedVariable(statement ast.Statement) (*ast.Identifier, token.Token, bool) {
+	expressionStatement, ok := statement.(*ast.ExpressionStatement)
 
 	if !ok || expressionStatement == nil {
-		return
+		return nil, token.Token{}, false
+	}
+
+	switch expression := expressionStatement.Expression.(type) {
+	case *ast.ReassignmentExpression:
+		if expression != nil {
+			return expression.Name, expression.Token, true
+		}
+	case *ast.IncrementDecrementExpression:
+		if expression != nil {
+			return expression.Identifier, expression.Token, true
+		}
 	}
 
-	reassignmentExpression, ok := expressionStatement.Expression.(*ast.ReassignmentExpression)
+	return nil, token.Token{}, false
+}
+
+/*
+Check if the current token is a reassignment of a constant, including ++ and -- on a constant.
+
+Add an error if it is
+*/
+func (p *Parser) checkConstReassignment(scope ast.Scope, newStatement ast.Statement) {
+	name, operator, ok := reassignedVariable(newStatement)
 
-	if !ok || reassignmentExpression == nil {
+	if !ok {
 		return
 	}
 
 	definedAsLetInScope := false
 
 	for _, statement := range scope.GetScopeStatements() {
-		variableStatement, ok := statement.(*ast.VariableStatement)
+		variableStatement, ok := asVariableStatement(statement)
 
 		if !ok {
 			continue
@@ -317,15 +396,17 @@ func (p *Parser) checkConstReassignment(scope ast.Scope, newStatement ast.Statem
 			continue
 		}
 
-		if variableStatement.Name.Value == reassignmentExpression.Name.Value {
-			e := fmt.Sprintf("[%d:%d] can not reassign constant %s.",
-				reassignmentExpression.Token.Line,
-				reassignmentExpression.Token.Column,
-				reassignmentExpression.Name.Value,
-			)
-
-			p.errors = append(p.errors, e)
-			return
+		for _, constant := range variableStatement.Names() {
+			if constant.Value == name.Value {
+				e := fmt.Sprintf("[%d:%d] can not reassign constant %s.",
+					operator.Line,
+					operator.Column,
+					name.Value,
+				)
+
+				p.errors = append(p.errors, e)
+				return
+			}
 		}
 	}
 
@@ -335,27 +416,31 @@ func (p *Parser) checkConstReassignment(scope ast.Scope, newStatement ast.Statem
 }
 
 func (p *Parser) checkVariableRedefinition(statements []ast.Statement, newStatement ast.Statement) {
-	expressionStatement, ok := newStatement.(*ast.VariableStatement)
+	expressionStatement, ok := asVariableStatement(newStatement)
 
 	if !ok || expressionStatement == nil {
 		return
 	}
 
 	for _, statement := range statements {
-		variableStatement, ok := statement.(*ast.VariableStatement)
+		variableStatement, ok := asVariableStatement(statement)
 
 		if !ok {
 			continue
 		}
 
-		if variableStatement.Name.Value == expressionStatement.Name.Value {
-			e := fmt.Sprintf("[%d:%d] can not redefine variable %s.",
-				expressionStatement.Token.Line,
-				expressionStatement.Token.Column,
-				expressionStatement.Name.Value,
-			)
-
-			p.errors = append(p.errors, e)
+		for _, definedName := range variableStatement.Names() {
+			for _, name := range expressionStatement.Names() {
+				if definedName.Value == name.Value {
+					e := fmt.Sprintf("[%d:%d] can not redefine variable %s.",
+						expressionStatement.Token.Line,
+						expressionStatement.Token.Column,
+						name.Value,
+					)
+
+					p.errors = append(p.errors, e)
+				}
+			}
 		}
 	}
 }
@@ -398,15 +483,114 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseReturnStatement()
 	case token.FUNCTION:
 		return p.parseFunction()
+	case token.STRUCT:
+		return p.parseStructStatement()
 	case token.FOR:
 		return p.parseForStatement()
 	case token.WHILE:
 		return p.parseWhileStatement()
+	case token.TRY:
+		return p.parseTryStatement()
+	case token.THROW:
+		return p.parseThrowStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
+	case token.EXPORT:
+		return p.parseExportStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
+/*
+Parse an import statement, including the path of the module and the name it is bound to
+*/
+func (p *Parser) parseImportStatement() *ast.ImportStatement {
+	if p.trace { // coverage-ignore
+		defer untrace(trace("ImportStatement"))
+	}
+
+	statement := &ast.ImportStatement{Token: p.currentToken}
+
+	if !p.isTopLevel() {
+		p.addError("import is only allowed at the top level", false)
+
+		return nil
+	}
+
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+
+	statement.Path = &ast.StringLiteral{Token: p.currentToken, Value: p.currentToken.Literal}
+
+	if !p.expectPeek(token.AS) {
+		return nil
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	statement.Name = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return statement
+}
+
+/*
+Parse an export statement, which wraps a function or variable statement
+*/
+func (p *Parser) parseExportStatement() *ast.ExportStatement {
+	if p.trace { // coverage-ignore
+		defer untrace(trace("ExportStatement"))
+	}
+
+	statement := &ast.ExportStatement{Token: p.currentToken}
+
+	if !p.isTopLevel() {
+		p.addError("export is only allowed at the top level", false)
+
+		return nil
+	}
+
+	p.nextToken()
+
+	switch p.currentToken.Type {
+	case token.LET, token.CONST:
+		variableStatement := p.parseVariableStatement()
+
+		if variableStatement == nil {
+			return nil
+		}
+
+		statement.Statement = variableStatement
+	case token.FUNCTION:
+		if !p.peekTokenIs(token.IDENT) {
+			p.addError("can only export named functions", true)
+
+			return nil
+		}
+
+		functionStatement := p.parseFunctionStatement()
+
+		if functionStatement == nil {
+			return nil
+		}
+
+		statement.Statement = functionStatement
+	default:
+		p.addError(fmt.Sprintf("can only export functions and variables, got %s", p.currentToken.Type), false)
+
+		return nil
+	}
+
+	return statement
+}
+
 /*
 Parse a named or anonymous function based on if the peek token is an identifier
 */
@@ -434,7 +618,7 @@ func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
 		return nil
 	}
 
-	statement.Arguments = p.parseFunctionArguments()
+	statement.Arguments, statement.Defaults, statement.Rest, statement.Patterns = p.parseFunctionArguments()
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -446,7 +630,7 @@ func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
 }
 
 /*
-Parse a variable statement, including the variable name and value
+Parse a variable statement, including the variable name or destructuring pattern and value
 */
 func (p *Parser) parseVariableStatement() *ast.VariableStatement {
 	if p.trace { // coverage-ignore
@@ -455,11 +639,21 @@ func (p *Parser) parseVariableStatement() *ast.VariableStatement {
 
 	statement := &ast.VariableStatement{Token: p.currentToken}
 
-	if !p.expectPeek(token.IDENT) {
-		return nil
-	}
+	if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
 
-	statement.Name = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+		statement.Pattern = p.parsePattern(map[string]bool{}, "duplicate name %s in destructuring pattern")
+
+		if statement.Pattern == nil {
+			return nil
+		}
+	} else {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		statement.Name = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+	}
 
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
@@ -469,7 +663,7 @@ func (p *Parser) parseVariableStatement() *ast.VariableStatement {
 
 	statement.Value = p.parseExpression(LOWEST)
 
-	for !p.currentTokenIs(token.SEMICOLON) {
+	for !p.currentTokenIs(token.SEMICOLON) && !p.currentTokenIs(token.EOF) {
 		p.nextToken()
 	}
 
@@ -486,8 +680,87 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 
 	statement.ReturnValue = p.parseExpression(LOWEST)
 
-	for !p.currentTokenIs(token.SEMICOLON) {
+	for !p.currentTokenIs(token.SEMICOLON) && !p.currentTokenIs(token.EOF) {
+		p.nextToken()
+	}
+
+	return statement
+}
+
+/*
+Parse a throw statement, including the value that is thrown
+*/
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	statement := &ast.ThrowStatement{Token: p.currentToken}
+
+	p.nextToken()
+
+	statement.Value = p.parseExpression(LOWEST)
+
+	for !p.currentTokenIs(token.SEMICOLON) && !p.currentTokenIs(token.EOF) {
+		p.nextToken()
+	}
+
+	return statement
+}
+
+/*
+Parse a try statement, including the try block and the optional catch and finally blocks.
+
+At least one of the catch or finally blocks has to be present.
+*/
+func (p *Parser) parseTryStatement() *ast.TryStatement {
+	if p.trace { // coverage-ignore
+		defer untrace(trace("TryStatement"))
+	}
+
+	statement := &ast.TryStatement{Token: p.currentToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	statement.Block = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.CATCH) {
+		p.nextToken()
+
+		// The name the error is bound to is optional, e.g. catch { ... }
+		if p.peekTokenIs(token.LPAREN) {
+			p.nextToken()
+
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+
+			statement.CatchParameter = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+			if !p.expectPeek(token.RPAREN) {
+				return nil
+			}
+		}
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		statement.CatchBlock = p.parseBlockStatement()
+	}
+
+	if p.peekTokenIs(token.FINALLY) {
 		p.nextToken()
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		statement.FinallyBlock = p.parseBlockStatement()
+	}
+
+	if statement.CatchBlock == nil && statement.FinallyBlock == nil {
+		p.addError(fmt.Sprintf("expected 'catch' or 'finally', got %s instead", p.peekToken.Type), true)
+
+		return nil
 	}
 
 	return statement
@@ -524,9 +797,11 @@ func (p *Parser) parseWhileStatement() *ast.WhileStatement {
 }
 
 /*
-Parse a for statement, including the initialization, condition, update and body
+Parse a for statement, including the initialization, condition, update and body.
+
+Loops of the form `for (x in iterable)` or `for (i, x in iterable)` are parsed as a for-in statement.
 */
-func (p *Parser) parseForStatement() *ast.ForStatement {
+func (p *Parser) parseForStatement() ast.Statement {
 	if p.trace { // coverage-ignore
 		defer untrace(trace("ForStatement"))
 	}
@@ -542,6 +817,12 @@ func (p *Parser) parseForStatement() *ast.ForStatement {
 
 	p.nextToken()
 
+	if p.currentTokenIs(token.IDENT) && (p.peekTokenIs(token.IN) || p.peekTokenIs(token.COMMA)) {
+		p.scope = forStatement.Parent
+
+		return p.parseForInStatement(forStatement.Token)
+	}
+
 	if p.currentTokenIs(token.LET) {
 		forStatement.Init = p.parseVariableStatement()
 	} else if p.currentTokenIs(token.CONST) {
@@ -567,6 +848,12 @@ func (p *Parser) parseForStatement() *ast.ForStatement {
 		forStatement.Update = p.parseExpression(LOWEST)
 	}
 
+	// The lexer ends the loop definition with a semicolon, which is
+	// only consumed by the update expression if it is a reassignment
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
@@ -583,6 +870,58 @@ func (p *Parser) parseForStatement() *ast.ForStatement {
 	return forStatement
 }
 
+/*
+Parse a for-in statement starting at the first loop variable, including the loop variables, the iterable and the body
+*/
+func (p *Parser) parseForInStatement(forToken token.Token) *ast.ForInStatement {
+	if p.trace { // coverage-ignore
+		defer untrace(trace("ForInStatement"))
+	}
+
+	forInStatement := &ast.ForInStatement{Token: forToken}
+	forInStatement.Value = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		forInStatement.Key = forInStatement.Value
+		forInStatement.Value = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+		if forInStatement.Key.Value == forInStatement.Value.Value {
+			p.addError(fmt.Sprintf("duplicate loop variable %s", forInStatement.Value.Value), false)
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+	forInStatement.Iterable = p.parseExpression(LOWEST)
+
+	// The lexer ends the loop definition with a semicolon
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	forInStatement.Body = p.parseBlockStatement()
+
+	return forInStatement
+}
+
 /*
 Parse an expression based on the current token type
 
@@ -660,7 +999,7 @@ func (p *Parser) parseReassignmentExpression() ast.Expression {
 
 	statement.Value = p.parseExpression(LOWEST)
 
-	for !p.currentTokenIs(token.SEMICOLON) {
+	for !p.currentTokenIs(token.SEMICOLON) && !p.currentTokenIs(token.EOF) {
 		p.nextToken()
 	}
 
@@ -668,47 +1007,116 @@ func (p *Parser) parseReassignmentExpression() ast.Expression {
 }
 
 /*
-Parse an identifier expression based on the current token
-
-If the current token is an identifier, the parser will check if the next token is an assignment operator.
-If the next token is an assignment operator, the parser will parse a reassignment expression.
-If the next token is not an assignment operator, the parser will parse an identifier expression.
+Parse a ternary expression, e.g. condition ? consequence : alternative
 
-The parser will then return the parsed expression
+The consequence is parsed up to the colon, so nested ternaries and ternaries
+inside hash literals can't be confused with a hash key-value separator.
+The alternative is parsed right associatively so a ? b : c ? d : e
+is parsed as a ? b : (c ? d : e).
 */
-func (p *Parser) parseIdentifier() ast.Expression {
-	identifier := &ast.Identifier{
-		Token: p.currentToken,
-		Value: p.currentToken.Literal,
+func (p *Parser) parseTernaryExpression(condition ast.Expression) ast.Expression {
+	if p.trace { // coverage-ignore
+		defer untrace(trace("TernaryExpression"))
 	}
 
-	if p.currentTokenIs(token.IDENT) && p.peekTokenIsAssignmentOperator() {
-		return p.parseReassignmentExpression()
+	expression := &ast.TernaryExpression{Token: p.currentToken, Condition: condition}
+
+	p.nextToken()
+
+	expression.Consequence = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.COLON) {
+		return nil
 	}
 
-	return identifier
+	p.nextToken()
+
+	expression.Alternative = p.parseExpression(ASSIGN)
+
+	return expression
 }
 
 /*
-Parse an integer literal expression based on the current token
+Check if an expression can be assigned to as a member of an array or object, e.g. arr[0] or obj.key
 */
-func (p *Parser) parseIntegerLiteral() ast.Expression {
-	if p.trace { // coverage-ignore
-		defer untrace(trace("IntegerLiteral"))
+func isMemberTarget(expression ast.Expression) bool {
+	switch expression := expression.(type) {
+	case *ast.IndexExpression:
+		return true
+	case *ast.ChainingExpression:
+		_, ok := expression.Right.(*ast.Identifier)
+
+		return ok
 	}
 
-	literal := &ast.IntegerLiteral{Token: p.currentToken}
-	value, err := strconv.ParseInt(p.currentToken.Literal, 0, 64)
+	return false
+}
 
-	if err != nil {
-		p.addError(fmt.Sprintf("could not parse %q as integer", p.currentToken.Literal), false)
+/*
+Parse a reassignment of an array element or object property, e.g. arr[0] = 1 or obj.key += 1
+*/
+func (p *Parser) parseMemberReassignmentExpression(target ast.Expression) ast.Expression {
+	if p.trace { // coverage-ignore
+		defer untrace(trace("MemberReassignmentExpression"))
+	}
+
+	if !isMemberTarget(target) {
+		p.addError(fmt.Sprintf("invalid assignment target %s", target), false)
 
 		return nil
 	}
 
-	literal.Value = value
+	expression := &ast.MemberReassignmentExpression{Token: p.currentToken, Target: target}
 
-	return literal
+	p.nextToken()
+
+	expression.Value = p.parseExpression(LOWEST)
+
+	return expression
+}
+
+/*
+Parse an identifier expression based on the current token
+
+If the current token is an identifier, the parser will check if the next token is an assignment operator.
+If the next token is an assignment operator, the parser will parse a reassignment expression.
+If the next token is not an assignment operator, the parser will parse an identifier expression.
+
+The parser will then return the parsed expression
+*/
+func (p *Parser) parseIdentifier() ast.Expression {
+	identifier := &ast.Identifier{
+		Token: p.currentToken,
+		Value: p.currentToken.Literal,
+	}
+
+	if p.currentTokenIs(token.IDENT) && p.peekTokenIsAssignmentOperator() {
+		return p.parseReassignmentExpression()
+	}
+
+	return identifier
+}
+
+/*
+Parse an integer literal expression based on the current token
+*/
+func (p *Parser) parseIntegerLiteral() ast.Expression {
+	if p.trace { // coverage-ignore
+		defer untrace(trace("IntegerLiteral"))
+	}
+
+	literal := &ast.IntegerLiteral{Token: p.currentToken}
+	value, err := strconv.ParseInt(p.currentToken.Literal, 0, 64)
+
+	if err != nil {
+		p.addError(fmt.Sprintf("could not parse %q as integer", p.currentToken.Literal), false)
+
+		return nil
+	}
+
+	literal.Value = value
+
+	return literal
 }
 
 /*
@@ -743,6 +1151,73 @@ func (p *Parser) parseFloatLiteral() ast.Expression {
 	return literal
 }
 
+/*
+Add a literal part to an interpolated string, empty parts are left out
+*/
+func appendTemplateString(template *ast.TemplateLiteral, t token.Token) {
+	if t.Literal == "" {
+		return
+	}
+
+	template.Parts = append(template.Parts, &ast.StringLiteral{Token: t, Value: t.Literal})
+}
+
+/*
+Parse an interpolated string like "Hello ${name}!" into its literal parts and interpolated expressions
+*/
+func (p *Parser) parseTemplateLiteral() ast.Expression {
+	if p.trace { // coverage-ignore
+		defer untrace(trace("TemplateLiteral"))
+	}
+
+	template := &ast.TemplateLiteral{Token: p.currentToken}
+	appendTemplateString(template, p.currentToken)
+
+	for {
+		if p.peekTokenIs(token.TEMPLATE_MIDDLE) || p.peekTokenIs(token.TEMPLATE_END) {
+			p.addError("empty string interpolation", true)
+			return nil
+		}
+
+		p.nextToken()
+
+		expression := p.parseExpression(LOWEST)
+
+		if expression == nil {
+			return nil
+		}
+
+		template.Parts = append(template.Parts, expression)
+
+		if p.peekTokenIs(token.TEMPLATE_MIDDLE) {
+			p.nextToken()
+			appendTemplateString(template, p.currentToken)
+
+			continue
+		}
+
+		if !p.peekTokenIs(token.TEMPLATE_END) {
+			p.addError(fmt.Sprintf("expected '}' to end string interpolation, got %s instead", p.peekToken.Type), true)
+			return nil
+		}
+
+		p.nextToken()
+		appendTemplateString(template, p.currentToken)
+
+		return template
+	}
+}
+
+/*
+Add an error to the parser for an illegal token produced by the lexer,
+e.g. an unterminated string or an invalid escape sequence
+*/
+func (p *Parser) parseIllegal() ast.Expression {
+	p.addError(fmt.Sprintf("illegal token: %s", p.currentToken.Literal), false)
+
+	return nil
+}
+
 /*
 Add an error to the parser if there is no prefix parse function for the current token
 */
@@ -927,7 +1402,7 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		return nil
 	}
 
-	fl.Arguments = p.parseFunctionArguments()
+	fl.Arguments, fl.Defaults, fl.Rest, fl.Patterns = p.parseFunctionArguments()
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -939,34 +1414,511 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 }
 
 /*
-Parse a the arguments of a function until a right parenthesis is encountered
+Parse the arguments of a function definition until a right parenthesis is encountered.
+
+Arguments can have a default value (b = 10), which makes them optional. Optional
+arguments must come after all required arguments. The last argument can be a rest
+argument (...rest) which collects all remaining arguments of a call into an array.
+
+Arguments can also be destructuring patterns ([a, b] or {a, b}). Those are named after
+the pattern itself, which can not be used as an identifier in the function body.
 */
-func (p *Parser) parseFunctionArguments() []*ast.Identifier {
+func (p *Parser) parseFunctionArguments() ([]*ast.Identifier, map[string]ast.Expression, *ast.Identifier, map[string]ast.Expression) {
 	identifiers := []*ast.Identifier{}
+	defaults := map[string]ast.Expression{}
+	patterns := map[string]ast.Expression{}
+	names := map[string]bool{}
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return identifiers
+		return identifiers, defaults, nil, patterns
 	}
 
-	p.nextToken()
+	for {
+		p.nextToken()
+
+		if p.currentTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return nil, nil, nil, nil
+			}
+
+			rest := p.parsePatternName(names, "duplicate argument %s")
+
+			if rest == nil {
+				return nil, nil, nil, nil
+			}
+
+			if !p.peekTokenIs(token.RPAREN) {
+				p.addError("rest argument must be the last argument", true)
+				return nil, nil, nil, nil
+			}
+
+			p.nextToken()
+
+			return identifiers, defaults, rest, patterns
+		}
+
+		var ident *ast.Identifier
+
+		switch p.currentToken.Type {
+		case token.IDENT:
+			ident = p.parsePatternName(names, "duplicate argument %s")
+		case token.LBRACKET, token.LBRACE:
+			patternToken := p.currentToken
+			pattern := p.parsePattern(names, "duplicate argument %s")
+
+			if pattern != nil {
+				ident = &ast.Identifier{Token: patternToken, Value: pattern.String()}
+				patterns[ident.Value] = pattern
+			}
+		default:
+			p.addError(fmt.Sprintf("expected argument name, got %s instead", p.currentToken.Type), false)
+		}
+
+		if ident == nil {
+			return nil, nil, nil, nil
+		}
+
+		identifiers = append(identifiers, ident)
+
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+
+			defaults[ident.Value] = p.parseExpression(LOWEST)
+		} else if len(defaults) > 0 {
+			p.addError(fmt.Sprintf("required argument %s can not follow an argument with a default value", ident.Value), false)
+			return nil, nil, nil, nil
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil, nil, nil, nil
+	}
+
+	return identifiers, defaults, nil, patterns
+}
+
+/*
+Parse a destructuring pattern or a single name a value is bound to, starting at the current token.
+
+Every name the pattern binds is added to names. Binding a name that is already in names adds
+an error formatted with duplicateError.
+*/
+func (p *Parser) parsePattern(names map[string]bool, duplicateError string) ast.Expression {
+	switch p.currentToken.Type {
+	case token.IDENT:
+		if ident := p.parsePatternName(names, duplicateError); ident != nil {
+			return ident
+		}
+
+		return nil
+	case token.LBRACKET, token.LBRACE:
+		parseTarget := func() ast.Expression { return p.parsePattern(names, duplicateError) }
+
+		return p.parseArrayOrHashPattern(names, duplicateError, parseTarget, true)
+	}
+
+	p.addError(fmt.Sprintf("expected name or destructuring pattern, got %s instead", p.currentToken.Type), false)
+
+	return nil
+}
+
+/*
+Parse an array pattern or object pattern depending on the current token, using parseTarget to parse the pattern of each element
+*/
+func (p *Parser) parseArrayOrHashPattern(names map[string]bool, duplicateError string, parseTarget func() ast.Expression, allowDefaults bool) ast.Expression {
+	if p.currentTokenIs(token.LBRACKET) {
+		pattern := &ast.ArrayPattern{Token: p.currentToken}
+		elements, rest, ok := p.parsePatternElements(token.RBRACKET, names, duplicateError, parseTarget, allowDefaults)
 
+		if !ok {
+			return nil
+		}
+
+		pattern.Elements, pattern.Rest = elements, rest
+
+		return pattern
+	}
+
+	pattern := &ast.HashPattern{Token: p.currentToken}
+	elements, rest, ok := p.parsePatternElements(token.RBRACE, names, duplicateError, parseTarget, allowDefaults)
+
+	if !ok {
+		return nil
+	}
+
+	pattern.Elements, pattern.Rest = elements, rest
+
+	return pattern
+}
+
+/*
+Parse the name at the current token that a value is bound to, checking that it is not bound twice
+*/
+func (p *Parser) parsePatternName(names map[string]bool, duplicateError string) *ast.Identifier {
 	ident := &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
-	identifiers = append(identifiers, ident)
 
-	for p.peekTokenIs(token.COMMA) {
+	if names[ident.Value] {
+		p.addError(fmt.Sprintf(duplicateError, ident.Value), false)
+		return nil
+	}
+
+	names[ident.Value] = true
+
+	return ident
+}
+
+/*
+Parse the elements of an array pattern (end is ']') or an object pattern (end is '}') until the end token is encountered.
+
+Elements of an object pattern start with the name of the property, optionally followed by a colon and the pattern
+the property is bound to. Elements can have a default value if allowDefaults is true and the last element can be a rest element.
+*/
+func (p *Parser) parsePatternElements(end token.TokenType, names map[string]bool, duplicateError string, parseTarget func() ast.Expression, allowDefaults bool) ([]*ast.PatternElement, *ast.Identifier, bool) {
+	elements := []*ast.PatternElement{}
+
+	for !p.peekTokenIs(end) {
+		p.nextToken()
+
+		if p.currentTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return nil, nil, false
+			}
+
+			rest := p.parsePatternName(names, duplicateError)
+
+			if rest == nil {
+				return nil, nil, false
+			}
+
+			if !p.peekTokenIs(end) {
+				p.addError("rest element must be the last element of a destructuring pattern", true)
+				return nil, nil, false
+			}
+
+			p.nextToken()
+
+			return elements, rest, true
+		}
+
+		element := &ast.PatternElement{}
+
+		if end == token.RBRACE {
+			if !p.currentTokenIs(token.IDENT) {
+				p.addError(fmt.Sprintf("expected property name, got %s instead", p.currentToken.Type), false)
+				return nil, nil, false
+			}
+
+			element.Key = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+			if p.peekTokenIs(token.COLON) {
+				p.nextToken()
+				p.nextToken()
+			}
+		}
+
+		element.Target = parseTarget()
+
+		if element.Target == nil {
+			return nil, nil, false
+		}
+
+		if allowDefaults && p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+
+			element.Default = p.parseExpression(LOWEST)
+		}
+
+		elements = append(elements, element)
+
+		if !p.peekTokenIs(end) && !p.expectPeek(token.COMMA) {
+			return nil, nil, false
+		}
+	}
+
+	p.nextToken()
+
+	return elements, nil, true
+}
+
+/*
+Parse a struct statement, including the name of the struct, its fields and methods, e.g.
+
+	struct Point {
+		x, y = 0;
+
+		func norm() { ... }
+	}
+*/
+func (p *Parser) parseStructStatement() *ast.StructStatement {
+	if p.trace { // coverage-ignore
+		defer untrace(trace("StructStatement"))
+	}
+
+	statement := &ast.StructStatement{Token: p.currentToken, Defaults: map[string]ast.Expression{}}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	statement.Name = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	members := map[string]bool{}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		switch p.currentToken.Type {
+		case token.IDENT:
+			if !p.parseStructFields(statement, members) {
+				return nil
+			}
+		case token.FUNCTION:
+			if !p.peekTokenIs(token.IDENT) {
+				p.addError(fmt.Sprintf("methods of struct %s must have a name", statement.Name.Value), true)
+				return nil
+			}
+
+			if members[p.peekToken.Literal] {
+				p.addError(fmt.Sprintf("duplicate member %s in struct %s", p.peekToken.Literal, statement.Name.Value), true)
+				return nil
+			}
+
+			method := p.parseFunctionStatement()
+
+			if method == nil { // coverage-ignore
+				return nil
+			}
+
+			members[method.Name.Value] = true
+			statement.Methods = append(statement.Methods, method)
+		default:
+			p.addError(fmt.Sprintf("expected field or method in struct %s, got %s instead", statement.Name.Value, p.currentToken.Type), false)
+			return nil
+		}
+	}
+
+	p.nextToken()
+
+	return statement
+}
+
+/*
+Parse a list of fields of a struct ending with a semicolon, e.g. `x, y = 0;`
+
+Fields with a default value are optional when constructing the struct and have to come after all required fields.
+*/
+func (p *Parser) parseStructFields(statement *ast.StructStatement, members map[string]bool) bool {
+	for {
+		if !p.currentTokenIs(token.IDENT) {
+			p.addError(fmt.Sprintf("expected field name, got %s instead", p.currentToken.Type), false)
+			return false
+		}
+
+		field := &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+		if members[field.Value] {
+			p.addError(fmt.Sprintf("duplicate member %s in struct %s", field.Value, statement.Name.Value), false)
+			return false
+		}
+
+		members[field.Value] = true
+		statement.Fields = append(statement.Fields, field)
+
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+
+			statement.Defaults[field.Value] = p.parseExpression(LOWEST)
+		} else if len(statement.Defaults) > 0 {
+			p.addError(fmt.Sprintf("required field %s can not follow a field with a default value", field.Value), false)
+			return false
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+
 		p.nextToken()
 		p.nextToken()
+	}
 
-		ident := &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
-		identifiers = append(identifiers, ident)
+	return p.expectPeek(token.SEMICOLON)
+}
+
+/*
+Parse a match expression, including the subject and all the arms with their patterns, guards and bodies
+*/
+func (p *Parser) parseMatchExpression() ast.Expression {
+	if p.trace { // coverage-ignore
+		defer untrace(trace("MatchExpression"))
 	}
 
-	if !p.expectPeek(token.RPAREN) {
+	expression := &ast.MatchExpression{Token: p.currentToken}
+
+	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		arm := p.parseMatchArm()
+
+		if arm == nil {
+			return nil
+		}
+
+		expression.Arms = append(expression.Arms, arm)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	p.nextToken()
+
+	if len(expression.Arms) == 0 {
+		p.addError("match expression must have at least one arm", false)
+		return nil
+	}
+
+	p.checkMatchExhaustive(expression)
+
+	return expression
+}
+
+/*
+Parse a single arm of a match expression starting at its first pattern
+*/
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	arm := &ast.MatchArm{}
+
+	for {
+		pattern := p.parseMatchPattern(map[string]bool{})
+
+		if pattern == nil {
+			return nil
+		}
+
+		arm.Patterns = append(arm.Patterns, pattern)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+
+		p.nextToken()
+		p.nextToken()
+	}
+
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		p.nextToken()
+
+		arm.Guard = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+
+	p.nextToken()
+	arm.Body = p.parseExpression(LOWEST)
+
+	return arm
+}
+
+/*
+Parse a pattern of a match expression.
+
+A pattern is a literal that is compared to the value, a name the value is bound to, _ which matches
+anything without binding it, or an array or object pattern containing other patterns.
+*/
+func (p *Parser) parseMatchPattern(names map[string]bool) ast.Expression {
+	switch p.currentToken.Type {
+	case token.IDENT:
+		if p.currentToken.Literal == "_" {
+			return &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+		}
+
+		if ident := p.parsePatternName(names, "duplicate name %s in match pattern"); ident != nil {
+			return ident
+		}
+
 		return nil
+	case token.LBRACKET, token.LBRACE:
+		parseTarget := func() ast.Expression { return p.parseMatchPattern(names) }
+
+		return p.parseArrayOrHashPattern(names, "duplicate name %s in match pattern", parseTarget, false)
+	case token.INT, token.FLOAT, token.STRING, token.TRUE, token.FALSE, token.NULL:
+		return p.prefixParseFunctions[p.currentToken.Type]()
+	case token.MINUS:
+		if p.peekTokenIs(token.INT) || p.peekTokenIs(token.FLOAT) {
+			return p.parsePrefixExpression()
+		}
 	}
 
-	return identifiers
+	p.addError(fmt.Sprintf("invalid match pattern, got %s instead", p.currentToken.Type), false)
+
+	return nil
+}
+
+/*
+Add a warning if not every value is handled by an arm of the match expression.
+
+A match is exhaustive if an arm without a guard binds the value to a name or _, or if
+arms without guards match both true and false when all patterns are booleans.
+Booleans are the only finite set of values the check knows, so a match on other literals,
+like the strings of an enum-like set, always needs a _ or binding arm to be exhaustive.
+*/
+func (p *Parser) checkMatchExhaustive(expression *ast.MatchExpression) {
+	booleans := map[bool]bool{}
+	onlyBooleans := true
+
+	for _, arm := range expression.Arms {
+		for _, pattern := range arm.Patterns {
+			switch pattern := pattern.(type) {
+			case *ast.Identifier:
+				if arm.Guard == nil {
+					return
+				}
+			case *ast.BooleanLiteral:
+				if arm.Guard == nil {
+					booleans[pattern.Value] = true
+				}
+			default:
+				onlyBooleans = false
+			}
+		}
+	}
+
+	var warning string
+
+	switch {
+	case onlyBooleans && len(booleans) == 2:
+		return
+	case onlyBooleans && len(booleans) == 1:
+		warning = fmt.Sprintf("match is not exhaustive, missing pattern %t", !booleans[true])
+	default:
+		warning = "match is not exhaustive, add a _ arm to handle the remaining values"
+	}
+
+	p.warnings = append(p.warnings, fmt.Sprintf("[%d:%d]: %s", expression.Token.Line, expression.Token.Column, warning))
 }
 
 /*
@@ -1002,12 +1954,12 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	}
 
 	p.nextToken()
-	list = append(list, p.parseExpression(LOWEST))
+	list = append(list, p.parseListElement())
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		list = append(list, p.parseExpression(LOWEST))
+		list = append(list, p.parseListElement())
 	}
 
 	if !p.expectPeek(end) {
@@ -1017,6 +1969,29 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	return list
 }
 
+/*
+Parse an element of an expression list, which can be spread using ...
+*/
+func (p *Parser) parseListElement() ast.Expression {
+	if p.currentTokenIs(token.ELLIPSIS) {
+		return p.parseSpreadExpression()
+	}
+
+	return p.parseExpression(LOWEST)
+}
+
+/*
+Parse a spread expression, e.g. ...arr
+*/
+func (p *Parser) parseSpreadExpression() ast.Expression {
+	spread := &ast.SpreadExpression{Token: p.currentToken}
+
+	p.nextToken()
+	spread.Value = p.parseExpression(LOWEST)
+
+	return spread
+}
+
 /*
 Parse an index expression, including the left-hand side and the index
 */
@@ -1081,6 +2056,17 @@ func (p *Parser) parseIncrementDecrement(left ast.Expression) ast.Expression {
 		identifier = exp
 	case *ast.IncrementDecrementExpression:
 		identifier = exp.Identifier
+	case *ast.IndexExpression, *ast.ChainingExpression:
+		if isMemberTarget(exp) {
+			return &ast.MemberIncrementDecrementExpression{
+				Token:  p.currentToken,
+				Target: exp,
+				Before: true,
+			}
+		}
+
+		p.addError(fmt.Sprintf("unexpected token %s", p.currentToken.Type), false)
+		return nil
 	default:
 		p.addError(fmt.Sprintf("unexpected token %s", p.currentToken.Type), false)
 		return nil
@@ -1107,16 +2093,29 @@ func (p *Parser) parseIncrementDecrementPrefix() ast.Expression {
 
 	p.nextToken()
 
-	identifier, ok := p.parseIdentifier().(*ast.Identifier)
-
-	if !ok {
+	if !p.currentTokenIs(token.IDENT) {
 		p.addError(fmt.Sprintf("unexpected token %s", p.currentToken.Type), false)
 		return nil
 	}
 
-	expression.Identifier = identifier
+	target := p.parseExpression(PREFIX)
 
-	return expression
+	if identifier, ok := target.(*ast.Identifier); ok {
+		expression.Identifier = identifier
+
+		return expression
+	}
+
+	if !isMemberTarget(target) {
+		p.addError(fmt.Sprintf("unexpected token %s", p.currentToken.Type), false)
+		return nil
+	}
+
+	return &ast.MemberIncrementDecrementExpression{
+		Token:  expression.Token,
+		Target: target,
+		Before: false,
+	}
 }
 
 /*
@@ -1131,6 +2130,16 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 	for !p.peekTokenIs(token.RBRACE) {
 		p.nextToken()
 
+		if p.currentTokenIs(token.ELLIPSIS) {
+			hash.Keys = append(hash.Keys, p.parseSpreadExpression())
+
+			if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+				return nil
+			}
+
+			continue
+		}
+
 		key := p.parseExpression(LOWEST)
 
 		if !p.expectPeek(token.COLON) {
@@ -1141,6 +2150,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 
 		value := p.parseExpression(LOWEST)
 		hash.Pairs[key] = value
+		hash.Keys = append(hash.Keys, key)
 
 		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
 			return nil
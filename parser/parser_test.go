@@ -591,6 +591,52 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 	}
 }
 
+func TestTernaryExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a ? b : c", "(a ? b : c)"},
+		{"a > 1 ? b + 1 : c * 2", "((a > 1) ? (b + 1) : (c * 2))"},
+		{"a || b ? c : d", "((a || b) ? c : d)"},
+		{"a ? b : c ? d : e", "(a ? b : (c ? d : e))"},
+		{"a ? b ? c : d : e", "(a ? (b ? c : d) : e)"},
+		{"(a ? b : c) + 1", "((a ? b : c) + 1)"},
+		{"f(a ? b : c, d)", "f((a ? b : c), d)"},
+		{"x[0] = a ? b : c", "(x[0]) = (a ? b : c)"},
+		{`{"k": a ? b : c}`, `{"k": (a ? b : c)}`},
+		{`{a ? "x" : "y": b}`, `{(a ? "x" : "y"): b}`},
+		{`{"k": a ? b ? 1 : 2 : 3}`, `{"k": (a ? (b ? 1 : 2) : 3)}`},
+	}
+
+	for _, tt := range tests {
+		program := initializeParserTest(t, tt.input, 1)
+		actual := program.String()
+
+		if actual != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, actual)
+		}
+	}
+}
+
+func TestTernaryExpressionError(t *testing.T) {
+	l := lexer.New("a ? b;")
+	p := New(l, false)
+	p.ParseProgram()
+
+	errors := p.Errors()
+
+	if len(errors) == 0 {
+		t.Fatal("Expected a parser error")
+	}
+
+	expectedError := "[1:7]: expected ':', got ; instead"
+
+	if !strings.Contains(errors[0], expectedError) {
+		t.Errorf("Expected error message to contain %q, got %q", expectedError, errors[0])
+	}
+}
+
 func TestBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input           string
@@ -771,6 +817,83 @@ func TestForStatement(t *testing.T) {
 	}
 }
 
+func TestForInStatement(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedKey      string
+		expectedValue    string
+		expectedIterable string
+	}{
+		{"for (x in arr) { x }", "", "x", "arr"},
+		{"for (i, x in [1, 2]) { x }", "i", "x", "[1, 2]"},
+		{"for (k, v in config.items()) { k }", "k", "v", "(config.items())"},
+		{"for (i in range(1, 10)) { i }", "", "i", "range(1, 10)"},
+	}
+
+	for _, test := range tests {
+		program := initializeParserTest(t, test.input, 1)
+
+		statement, ok := program.Statements[0].(*ast.ForInStatement)
+
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ForInStatement. got %T", program.Statements[0])
+		}
+
+		if test.expectedKey == "" {
+			if statement.Key != nil {
+				t.Errorf("statement.Key is not nil. got %s", statement.Key)
+			}
+		} else {
+			checkIdentifier(t, statement.Key, test.expectedKey)
+		}
+
+		checkIdentifier(t, statement.Value, test.expectedValue)
+
+		if statement.Iterable.String() != test.expectedIterable {
+			t.Errorf("statement.Iterable.String() wrong. want %q, got %q", test.expectedIterable, statement.Iterable.String())
+		}
+
+		if len(statement.Body.Statements) != 1 {
+			t.Errorf("body is not 1 statements. got %d\n", len(statement.Body.Statements))
+		}
+	}
+
+	program := initializeParserTest(t, "for (x in arr) { x } for (let i = 0; i < 1; i++) { i }", 2)
+
+	if _, ok := program.Statements[1].(*ast.ForStatement); !ok {
+		t.Fatalf("program.Statements[1] is not ast.ForStatement. got %T", program.Statements[1])
+	}
+}
+
+func TestForInStatementErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"for (x, x in arr) {}", "[1:10]: duplicate loop variable x"},
+		{"for (x, 1 in arr) {}", "[1:10]: expected 'IDENT', got INT instead"},
+		{"for (i, x of arr) {}", "[1:12]: expected 'IN', got IDENT instead"},
+		{"for (x in arr {}", "[1:16]: expected ')', got { instead"},
+		{"for (x in arr) x", "[1:17]: expected '{', got IDENT instead"},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l, false)
+		p.ParseProgram()
+
+		errors := p.Errors()
+
+		if len(errors) == 0 {
+			t.Fatalf("Expected a parser error for %q", test.input)
+		}
+
+		if errors[0] != test.expectedError {
+			t.Errorf("Expected error message to be %q, got %q", test.expectedError, errors[0])
+		}
+	}
+}
+
 func TestForOnlyConditionStatement(t *testing.T) {
 	program := initializeParserTest(t, "for (; i < 10;) { i = i + 1; }", 1)
 
@@ -873,6 +996,71 @@ func TestFunctionArgumentParsing(t *testing.T) {
 	}
 }
 
+func TestDefaultAndRestArgumentParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"func(a, b = 10) {};", "func(a, b = 10) {\n}"},
+		{"func(a, b = a * 2, ...rest) {};", "func(a, b = (a * 2), ...rest) {\n}"},
+		{"func(...rest) {};", "func(...rest) {\n}"},
+		{"func add(a = 1, b = 2) { a + b }", "func add(a = 1, b = 2) {\n  (a + b)\n}"},
+	}
+
+	for _, test := range tests {
+		program := initializeParserTest(t, test.input, 1)
+
+		if program.String() != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, program.String())
+		}
+	}
+
+	program := initializeParserTest(t, "func(a, b = 10, ...rest) {};", 1)
+	function := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+
+	if len(function.Arguments) != 2 {
+		t.Fatalf("length of arguments is wrong. want 2, got %d", len(function.Arguments))
+	}
+
+	if _, ok := function.Defaults["a"]; ok {
+		t.Errorf("argument a should not have a default value")
+	}
+
+	checkLiteralExpression(t, function.Defaults["b"], 10)
+
+	if function.Rest == nil || function.Rest.Value != "rest" {
+		t.Errorf("rest argument is not 'rest'. got %v", function.Rest)
+	}
+}
+
+func TestFunctionArgumentErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"func(a, a) {};", "[1:10]: duplicate argument a"},
+		{"func(...rest, a) {};", "[1:14]: rest argument must be the last argument"},
+		{"func(1) {};", "[1:7]: expected argument name, got INT instead"},
+		{"func(a = 1, b) {};", "[1:14]: required argument b can not follow an argument with a default value"},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l, false)
+		p.ParseProgram()
+
+		errors := p.Errors()
+
+		if len(errors) == 0 {
+			t.Fatalf("Expected a parser error for %q", test.input)
+		}
+
+		if errors[0] != test.expectedError {
+			t.Errorf("Expected error message to be %q, got %q", test.expectedError, errors[0])
+		}
+	}
+}
+
 func TestCallExpressionParsing(t *testing.T) {
 	program := initializeParserTest(t, "add(1, 2 * 3, 4 + 5);", 1)
 
@@ -935,6 +1123,136 @@ func TestStringLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestStringLiteralRoundTrip(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"hello"`, `"hello"`},
+		{`"a\tb\nc"`, `"a\tb\nc"`},
+		{`"say \"hi\" \\ \u{1F600} \u{7}"`, `"say \"hi\" \\ 😀 \u{7}"`},
+		{"`raw \\n\nstring`", `"raw \\n\nstring"`},
+	}
+
+	for _, test := range tests {
+		program := initializeParserTest(t, test.input, 1)
+		output := program.String()
+
+		if output != test.expected {
+			t.Errorf("program.String() wrong. expected %q, got %q", test.expected, output)
+		}
+
+		// Parsing the output again should give the same value
+		reparsed := initializeParserTest(t, output, 1)
+
+		original := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.StringLiteral)
+		roundTrip := reparsed.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.StringLiteral)
+
+		if original.Value != roundTrip.Value {
+			t.Errorf("round trip changed the string value. expected %q, got %q", original.Value, roundTrip.Value)
+		}
+	}
+}
+
+func TestTemplateLiteralParsing(t *testing.T) {
+	program := initializeParserTest(t, `"Hello ${user.name}, you have ${len(items)} items"`, 1)
+
+	statement := program.Statements[0].(*ast.ExpressionStatement)
+	template, ok := statement.Expression.(*ast.TemplateLiteral)
+
+	if !ok {
+		t.Fatalf("expression is not ast.TemplateLiteral. got %T", statement.Expression)
+	}
+
+	expectedParts := []string{`"Hello "`, "(user.name)", `", you have "`, "len(items)", `" items"`}
+
+	if len(template.Parts) != len(expectedParts) {
+		t.Fatalf("template.Parts has wrong length. expected %d, got %d", len(expectedParts), len(template.Parts))
+	}
+
+	for i, part := range template.Parts {
+		if part.String() != expectedParts[i] {
+			t.Errorf("template.Parts[%d] wrong. expected %q, got %q", i, expectedParts[i], part.String())
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"${a}"`, `"${a}"`},
+		{`"${a}${b}"`, `"${a}${b}"`},
+		{`"a ${b + 1} \${c} $d"`, `"a ${(b + 1)} \${c} $d"`},
+		{`"outer ${"inner ${x}"}"`, `"outer ${"inner ${x}"}"`},
+		{`"${ {"k": 1}["k"] }"`, `"${({"k": 1}["k"])}"`},
+		{`"${a ? "yes" : "no"}"`, `"${(a ? "yes" : "no")}"`},
+	}
+
+	for _, test := range tests {
+		program := initializeParserTest(t, test.input, 1)
+
+		if program.String() != test.expected {
+			t.Errorf("program.String() wrong. expected %q, got %q", test.expected, program.String())
+		}
+	}
+}
+
+func TestTemplateLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a ${} b"`, "[1:7]: empty string interpolation"},
+		{`"a ${b c} d"`, "[1:9]: expected '}' to end string interpolation, got IDENT instead"},
+		{`"a ${b`, "[1:8]: expected '}' to end string interpolation, got EOF instead"},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l, false)
+		p.ParseProgram()
+
+		errors := p.Errors()
+
+		if len(errors) == 0 {
+			t.Errorf("Expected a parser error for %q", test.input)
+			continue
+		}
+
+		if errors[0] != test.expected {
+			t.Errorf("Expected error message to be %q, got %q", test.expected, errors[0])
+		}
+	}
+}
+
+func TestIllegalStringErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let s = "abc\q";`, `[1:14]: illegal token: invalid escape sequence \q`},
+		{`let s = "abc`, `[1:10]: illegal token: unterminated string`},
+		{"let s = `abc", `[1:10]: illegal token: unterminated raw string`},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l, false)
+		p.ParseProgram()
+
+		errors := p.Errors()
+
+		if len(errors) == 0 {
+			t.Errorf("Expected a parser error for %q", test.input)
+			continue
+		}
+
+		if errors[0] != test.expected {
+			t.Errorf("Expected error message to be %q, got %q", test.expected, errors[0])
+		}
+	}
+}
+
 func TestParsingArrayLiterals(t *testing.T) {
 	program := initializeParserTest(t, "[1, 2 * 2, 3 + 3]", 1)
 
@@ -1010,7 +1328,7 @@ func TestParsingHashLiteralsStringKeys(t *testing.T) {
 			t.Errorf("key is not ast.StringLiteral. got %T", key)
 		}
 
-		expectedValue := expected[literal.String()]
+		expectedValue := expected[literal.Value]
 
 		checkIntegerLiteral(t, value, expectedValue)
 	}
@@ -1064,10 +1382,10 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 			continue
 		}
 
-		testFunction, ok := tests[literal.String()]
+		testFunction, ok := tests[literal.Value]
 
 		if !ok {
-			t.Errorf("No test function for key %q found", literal.String())
+			t.Errorf("No test function for key %q found", literal.Value)
 			continue
 		}
 
@@ -1075,6 +1393,62 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 	}
 }
 
+func TestParsingSpreadExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, ...a, ...[2, 3]];", "[1, ...a, ...[2, 3]]"},
+		{"add(...args, 1);", "add(...args, 1)"},
+		{`{"a": 1, ...b, "c": 2};`, `{"a": 1, ...b, "c": 2}`},
+		{"{...a, ...b};", "{...a, ...b}"},
+	}
+
+	for _, test := range tests {
+		program := initializeParserTest(t, test.input, 1)
+
+		if program.String() != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, program.String())
+		}
+	}
+
+	program := initializeParserTest(t, "[...a];", 1)
+	array := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ArrayLiteral)
+	spread, ok := array.Elements[0].(*ast.SpreadExpression)
+
+	if !ok {
+		t.Fatalf("element is not ast.SpreadExpression. got %T", array.Elements[0])
+	}
+
+	checkIdentifier(t, spread.Value, "a")
+}
+
+func TestParsingSpreadExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"...a;", "[1:4]: unexpected token ..."},
+		{"{...a b};", "[1:8]: expected ',', got IDENT instead"},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l, false)
+		p.ParseProgram()
+
+		errors := p.Errors()
+
+		if len(errors) == 0 {
+			t.Fatalf("Expected a parser error for %q", test.input)
+		}
+
+		if errors[0] != test.expectedError {
+			t.Errorf("Expected error message to be %q, got %q", test.expectedError, errors[0])
+		}
+	}
+}
+
 func TestParsingReassignment(t *testing.T) {
 	program := initializeParserTest(t, "let x = 4; x = 5;", 2)
 
@@ -1101,23 +1475,123 @@ func TestParsingReassignment(t *testing.T) {
 	}
 }
 
-func TestParsingConstReassignmentError(t *testing.T) {
-	input := `const NAME = "YOU";
-NAME = "ME";`
-
-	l := lexer.New(input)
-	p := New(l, false)
-	p.ParseProgram()
-
-	errors := p.Errors()
-
-	if len(errors) != 1 {
-		t.Error("Expected a parser error")
+func TestParsingMemberReassignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"arr[0] = 5;", "(arr[0]) = 5"},
+		{"arr[i + 1] += 2 * 3;", "(arr[(i + 1)]) += (2 * 3)"},
+		{`obj["key"] = "value";`, `(obj["key"]) = "value"`},
+		{"obj.key -= 1;", "(obj.key) -= 1"},
+		{"obj.a.b <<= 2;", "((obj.a).b) <<= 2"},
+		{"arr[0][1] = obj.key;", "((arr[0])[1]) = (obj.key)"},
 	}
 
-	expectedError := "[2:7] can not reassign constant NAME."
+	for _, test := range tests {
+		program := initializeParserTest(t, test.input, 1)
 
-	if errors[0] != expectedError {
+		statement, ok := program.Statements[0].(*ast.ExpressionStatement)
+
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got %T", program.Statements[0])
+		}
+
+		expression, ok := statement.Expression.(*ast.MemberReassignmentExpression)
+
+		if !ok {
+			t.Fatalf("expression not *ast.MemberReassignmentExpression. got %T", statement.Expression)
+		}
+
+		if expression.String() != test.expected {
+			t.Errorf("expression.String() not %q. got %q", test.expected, expression.String())
+		}
+	}
+}
+
+func TestParsingMemberIncrementDecrement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		before   bool
+	}{
+		{"arr[0]++;", "(arr[0])++", true},
+		{"obj.count--;", "(obj.count)--", true},
+		{"++arr[0];", "++(arr[0])", false},
+		{"--obj.a.count;", "--((obj.a).count)", false},
+	}
+
+	for _, test := range tests {
+		program := initializeParserTest(t, test.input, 1)
+
+		statement, ok := program.Statements[0].(*ast.ExpressionStatement)
+
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got %T", program.Statements[0])
+		}
+
+		expression, ok := statement.Expression.(*ast.MemberIncrementDecrementExpression)
+
+		if !ok {
+			t.Fatalf("expression not *ast.MemberIncrementDecrementExpression. got %T", statement.Expression)
+		}
+
+		if expression.String() != test.expected {
+			t.Errorf("expression.String() not %q. got %q", test.expected, expression.String())
+		}
+
+		if expression.Before != test.before {
+			t.Errorf("expression.Before not %t. got %t", test.before, expression.Before)
+		}
+	}
+}
+
+func TestParsingMemberReassignmentErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + 2 = 3;", "invalid assignment target (1 + 2)"},
+		{"f() = 3;", "invalid assignment target f()"},
+		{"obj.f() = 3;", "invalid assignment target (obj.f())"},
+		{"f()++;", "unexpected token ++"},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l, false)
+		p.ParseProgram()
+
+		errors := p.Errors()
+
+		if len(errors) == 0 {
+			t.Errorf("Expected a parser error for %q", test.input)
+			continue
+		}
+
+		if !strings.Contains(errors[0], test.expected) {
+			t.Errorf("Expected error message to contain %q, got %q", test.expected, errors[0])
+		}
+	}
+}
+
+func TestParsingConstReassignmentError(t *testing.T) {
+	input := `const NAME = "YOU";
+NAME = "ME";`
+
+	l := lexer.New(input)
+	p := New(l, false)
+	p.ParseProgram()
+
+	errors := p.Errors()
+
+	if len(errors) != 1 {
+		t.Error("Expected a parser error")
+	}
+
+	expectedError := "[2:7] can not reassign constant NAME."
+
+	if errors[0] != expectedError {
 		t.Errorf("Expected error message to be %q, got %q", expectedError, errors[0])
 	}
 
@@ -1146,6 +1620,44 @@ test();`
 	}
 }
 
+func TestParsingConstIncrementDecrementError(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"const a = 1; a++;", "[1:17] can not reassign constant a."},
+		{"const a = 1; a--;", "[1:17] can not reassign constant a."},
+		{"const a = 1; ++a;", "[1:16] can not reassign constant a."},
+		{"const a = 1; --a;", "[1:16] can not reassign constant a."},
+		{"const a = 1; func f() { a++; }", "[1:28] can not reassign constant a."},
+		{"const [a, b] = [1, 2]; ++b;", "[1:26] can not reassign constant b."},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l, false)
+		p.ParseProgram()
+
+		errors := p.Errors()
+
+		if len(errors) == 0 {
+			t.Fatalf("Expected a parser error for %q", test.input)
+		}
+
+		if errors[0] != test.expectedError {
+			t.Errorf("Expected error message to be %q, got %q", test.expectedError, errors[0])
+		}
+	}
+
+	l := lexer.New("let a = 1; a++; --a;")
+	p := New(l, false)
+	p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		t.Errorf("Expected no parser errors, got %v", p.Errors())
+	}
+}
+
 func TestParserPrintErrors(t *testing.T) {
 	input := `let x 5;`
 
@@ -1183,6 +1695,34 @@ func TestLowestPrecedence(t *testing.T) {
 	}
 }
 
+func TestNumberLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1.2.3;", "[1:10]: illegal token: malformed number 1.2.3"},
+		{"let x = 0b102;", "[1:10]: illegal token: invalid digit '2' in binary literal 0b102"},
+		{"let x = 0xFFFFFFFFFFFFFFFFF;", `[1:10]: could not parse "0xFFFFFFFFFFFFFFFFF" as integer`},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l, false)
+		p.ParseProgram()
+
+		errors := p.Errors()
+
+		if len(errors) == 0 {
+			t.Errorf("Expected a parser error for %q", test.input)
+			continue
+		}
+
+		if errors[0] != test.expected {
+			t.Errorf("Expected error message to be %q, got %q", test.expected, errors[0])
+		}
+	}
+}
+
 func TestParseIntegerLiteralError(t *testing.T) {
 	input := "\"Test\";"
 
@@ -1283,6 +1823,278 @@ let x = 10;`
 	}
 }
 
+func TestDestructuringParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b, ...rest] = arr;", "let [a, b, ...rest] = arr;"},
+		{"const { host, port = 80 } = config;", "const {host, port = 80} = config;"},
+		{"let { server: { host: h }, tags: [first] = [] } = config;", "let {server: {host: h}, tags: [first] = []} = config;"},
+		{"let {a, ...others} = config;", "let {a, ...others} = config;"},
+		{"let [] = arr;", "let [] = arr;"},
+		{"func([a, b], {c} = {}) {};", "func([a, b], {c} = {}) {\n}"},
+	}
+
+	for _, test := range tests {
+		program := initializeParserTest(t, test.input, 1)
+
+		if program.String() != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, program.String())
+		}
+	}
+
+	program := initializeParserTest(t, "let [a, {b, c: d}] = arr;", 1)
+	statement := program.Statements[0].(*ast.VariableStatement)
+
+	if statement.Name != nil {
+		t.Errorf("statement.Name should be nil when destructuring. got %s", statement.Name)
+	}
+
+	names := statement.Names()
+	expectedNames := []string{"a", "b", "d"}
+
+	if len(names) != len(expectedNames) {
+		t.Fatalf("wrong number of names. want %d, got %d", len(expectedNames), len(names))
+	}
+
+	for i, name := range expectedNames {
+		checkIdentifier(t, names[i], name)
+	}
+
+	program = initializeParserTest(t, "func f({a, b}) {}", 1)
+	function := program.Statements[0].(*ast.FunctionStatement)
+
+	if _, ok := function.Patterns[function.Arguments[0].Value].(*ast.HashPattern); !ok {
+		t.Errorf("argument pattern is not ast.HashPattern. got %T", function.Patterns[function.Arguments[0].Value])
+	}
+}
+
+func TestDestructuringErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"let [a, a] = arr;", "[1:10]: duplicate name a in destructuring pattern"},
+		{"let [...rest, a] = arr;", "[1:14]: rest element must be the last element of a destructuring pattern"},
+		{"let [1] = arr;", "[1:7]: expected name or destructuring pattern, got INT instead"},
+		{`let {"a"} = config;`, "[1:7]: expected property name, got STRING instead"},
+		{"let [a b] = arr;", "[1:9]: expected ',', got IDENT instead"},
+		{"func([a], a) {};", "[1:12]: duplicate argument a"},
+		{"let [a, b] = arr; let {b} = config;", "[1:20] can not redefine variable b."},
+		{"const [a, b] = arr; b = 5;", "[1:24] can not reassign constant b."},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l, false)
+		p.ParseProgram()
+
+		errors := p.Errors()
+
+		if len(errors) == 0 {
+			t.Fatalf("Expected a parser error for %q", test.input)
+		}
+
+		if errors[0] != test.expectedError {
+			t.Errorf("Expected error message to be %q, got %q", test.expectedError, errors[0])
+		}
+	}
+}
+
+func TestMatchExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`match x { 1, 2 => "small", _ => "big" }`, `match x { 1, 2 => "small", _ => "big" }`},
+		{`match p { [x, y] if x > y => x, [x, ...rest] => rest, }`, `match p { [x, y] if (x > y) => x, [x, ...rest] => rest }`},
+		{`match s { { kind: "circle", r } => r * r, {kind, ...other} => kind, n => n }`, `match s { {kind: "circle", r} => (r * r), {kind, ...other} => kind, n => n }`},
+		{`match n { -1 => null, 1.5 => true, null => false, true => 1 }`, `match n { (-1) => null, 1.5 => true, null => false, true => 1 }`},
+		{`let result = match x { _ => x + 1 };`, `let result = match x { _ => (x + 1) };`},
+	}
+
+	for _, test := range tests {
+		program := initializeParserTest(t, test.input, 1)
+
+		if program.String() != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, program.String())
+		}
+	}
+
+	program := initializeParserTest(t, `match x { [a, b], [a] if a => a }`, 1)
+	expression, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.MatchExpression)
+
+	if !ok {
+		t.Fatalf("expression is not ast.MatchExpression. got %T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+	}
+
+	checkIdentifier(t, expression.Subject, "x")
+
+	if len(expression.Arms) != 1 || len(expression.Arms[0].Patterns) != 2 {
+		t.Fatalf("expected 1 arm with 2 patterns, got %d arms", len(expression.Arms))
+	}
+
+	checkIdentifier(t, expression.Arms[0].Guard, "a")
+}
+
+func TestMatchExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"match x { }", "[1:12]: match expression must have at least one arm"},
+		{"match x { [a, a] => a }", "[1:16]: duplicate name a in match pattern"},
+		{"match x { a + 1 => a }", "[1:14]: expected '=>', got + instead"},
+		{"match x { f() => 1 }", "[1:13]: expected '=>', got ( instead"},
+		{"match x { -a => 1 }", "[1:12]: invalid match pattern, got - instead"},
+		{"match x { [a = 1] => a }", "[1:15]: expected ',', got = instead"},
+		{"match x { 1 => 1 2 => 2 }", "[1:19]: expected ',', got INT instead"},
+		{"match x 1 => 1", "[1:10]: expected '{', got INT instead"},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l, false)
+		p.ParseProgram()
+
+		errors := p.Errors()
+
+		if len(errors) == 0 {
+			t.Fatalf("Expected a parser error for %q", test.input)
+		}
+
+		if errors[0] != test.expectedError {
+			t.Errorf("Expected error message to be %q, got %q", test.expectedError, errors[0])
+		}
+	}
+}
+
+func TestMatchExhaustivenessWarnings(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedWarning string
+	}{
+		{`match x { 1 => "one", 2 => "two" }`, "[1:2]: match is not exhaustive, add a _ arm to handle the remaining values"},
+		{`match x { "red" => 1, "green" => 2, c if c == "blue" => 3 }`, "[1:2]: match is not exhaustive, add a _ arm to handle the remaining values"},
+		{`match x { true => 1 }`, "[1:2]: match is not exhaustive, missing pattern false"},
+		{`match x { false => 1, true if y => 2 }`, "[1:2]: match is not exhaustive, missing pattern true"},
+		{`match x { 1 => "one", _ => "other" }`, ""},
+		{`match x { 1 => "one", n => "other" }`, ""},
+		{`match x { true => 1, false => 0 }`, ""},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l, false)
+		p.ParseProgram()
+
+		if len(p.Errors()) != 0 {
+			t.Fatalf("Unexpected parser errors for %q: %v", test.input, p.Errors())
+		}
+
+		warnings := p.Warnings()
+
+		if test.expectedWarning == "" {
+			if len(warnings) != 0 {
+				t.Errorf("Expected no warnings for %q, got %v", test.input, warnings)
+			}
+
+			continue
+		}
+
+		if len(warnings) != 1 || warnings[0] != test.expectedWarning {
+			t.Errorf("Expected warning %q for %q, got %v", test.expectedWarning, test.input, warnings)
+		}
+	}
+}
+
+func TestStructStatementParsing(t *testing.T) {
+	input := `struct Point {
+	x, y = 0;
+	label = "point";
+
+	func norm() {
+		return self.x;
+	}
+
+	func scale(factor = 1) {
+		return factor;
+	}
+}`
+
+	program := initializeParserTest(t, input, 1)
+
+	statement, ok := program.Statements[0].(*ast.StructStatement)
+
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.StructStatement. got %T", program.Statements[0])
+	}
+
+	checkIdentifier(t, statement.Name, "Point")
+
+	expectedFields := []string{"x", "y", "label"}
+
+	if len(statement.Fields) != len(expectedFields) {
+		t.Fatalf("wrong number of fields. want %d, got %d", len(expectedFields), len(statement.Fields))
+	}
+
+	for i, field := range expectedFields {
+		checkIdentifier(t, statement.Fields[i], field)
+	}
+
+	if len(statement.Defaults) != 2 || statement.Defaults["y"].String() != "0" || statement.Defaults["label"].String() != `"point"` {
+		t.Errorf("statement.Defaults wrong. got %v", statement.Defaults)
+	}
+
+	expectedMethods := []string{"norm", "scale"}
+
+	if len(statement.Methods) != len(expectedMethods) {
+		t.Fatalf("wrong number of methods. want %d, got %d", len(expectedMethods), len(statement.Methods))
+	}
+
+	for i, method := range expectedMethods {
+		checkIdentifier(t, statement.Methods[i].Name, method)
+	}
+
+	if statement.String() != "struct Point { x, y = 0, label = \"point\"; func norm() {\n  return (self.x);\n} func scale(factor = 1) {\n  return factor;\n} }" {
+		t.Errorf("statement.String() wrong. got %q", statement.String())
+	}
+}
+
+func TestStructStatementErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"struct { x; }", "[1:9]: expected 'IDENT', got { instead"},
+		{"struct Point x;", "[1:15]: expected '{', got IDENT instead"},
+		{"struct Point { x, x; }", "[1:20]: duplicate member x in struct Point"},
+		{"struct Point { x; func x() {} }", "[1:25]: duplicate member x in struct Point"},
+		{"struct Point { x = 1, y; }", "[1:24]: required field y can not follow a field with a default value"},
+		{"struct Point { x, 1; }", "[1:20]: expected field name, got INT instead"},
+		{"struct Point { x }", "[1:19]: expected ';', got } instead"},
+		{"struct Point { 1 }", "[1:17]: expected field or method in struct Point, got INT instead"},
+		{"struct Point { func () {} }", "[1:22]: methods of struct Point must have a name"},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l, false)
+		p.ParseProgram()
+
+		errors := p.Errors()
+
+		if len(errors) == 0 {
+			t.Fatalf("Expected a parser error for %q", test.input)
+		}
+
+		if errors[0] != test.expectedError {
+			t.Errorf("Expected error message to be %q, got %q", test.expectedError, errors[0])
+		}
+	}
+}
+
 func TestParseChainingExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -1439,3 +2251,189 @@ func TestExpectReassignmentOperator(t *testing.T) {
 		t.Errorf("Expected error message to be %q, got %q", expectedError, errors[0])
 	}
 }
+
+func TestTryStatement(t *testing.T) {
+	tests := []struct {
+		input            string
+		catchParameter   string
+		hasCatchBlock    bool
+		hasFinallyBlock  bool
+		blockStatements  int
+		catchStatements  int
+		finallyStatments int
+	}{
+		{`try { x; } catch (err) { err; }`, "err", true, false, 1, 1, 0},
+		{`try { x; y; } catch { }`, "", true, false, 2, 0, 0},
+		{`try { } finally { x; }`, "", false, true, 0, 0, 1},
+		{`try { x; } catch (e) { e; } finally { x; y; }`, "e", true, true, 1, 1, 2},
+	}
+
+	for _, test := range tests {
+		program := initializeParserTest(t, test.input, 1)
+
+		statement, ok := program.Statements[0].(*ast.TryStatement)
+
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.TryStatement. got %T", program.Statements[0])
+		}
+
+		if len(statement.Block.Statements) != test.blockStatements {
+			t.Errorf("try block does not contain %d statements. got %d", test.blockStatements, len(statement.Block.Statements))
+		}
+
+		if test.catchParameter == "" && statement.CatchParameter != nil {
+			t.Errorf("statement.CatchParameter is not nil. got %q", statement.CatchParameter.Value)
+		}
+
+		if test.catchParameter != "" && !checkIdentifier(t, statement.CatchParameter, test.catchParameter) {
+			return
+		}
+
+		if (statement.CatchBlock != nil) != test.hasCatchBlock {
+			t.Fatalf("statement.CatchBlock presence wrong. got %v", statement.CatchBlock)
+		}
+
+		if test.hasCatchBlock && len(statement.CatchBlock.Statements) != test.catchStatements {
+			t.Errorf("catch block does not contain %d statements. got %d", test.catchStatements, len(statement.CatchBlock.Statements))
+		}
+
+		if (statement.FinallyBlock != nil) != test.hasFinallyBlock {
+			t.Fatalf("statement.FinallyBlock presence wrong. got %v", statement.FinallyBlock)
+		}
+
+		if test.hasFinallyBlock && len(statement.FinallyBlock.Statements) != test.finallyStatments {
+			t.Errorf("finally block does not contain %d statements. got %d", test.finallyStatments, len(statement.FinallyBlock.Statements))
+		}
+	}
+}
+
+func TestThrowStatement(t *testing.T) {
+	program := initializeParserTest(t, `throw "oops";`, 1)
+
+	statement, ok := program.Statements[0].(*ast.ThrowStatement)
+
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ThrowStatement. got %T", program.Statements[0])
+	}
+
+	if statement.Value.String() != `"oops"` {
+		t.Errorf("statement.Value not %q. got %q", `"oops"`, statement.Value.String())
+	}
+}
+
+func TestTryStatementErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"try { x; }", "[1:12]: expected 'catch' or 'finally', got EOF instead"},
+		{"try x", "[1:6]: expected '{', got IDENT instead"},
+		{"try { } catch (1) { }", "[1:17]: expected 'IDENT', got INT instead"},
+		{"try { } catch (e { }", "[1:19]: expected ')', got { instead"},
+		{"try { } catch x", "[1:16]: expected '{', got IDENT instead"},
+		{"try { } finally x", "[1:18]: expected '{', got IDENT instead"},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l, false)
+		p.parseTryStatement()
+
+		errors := p.Errors()
+
+		if len(errors) != 1 {
+			t.Fatalf("Expected a parser error for %q. got %v", test.input, errors)
+		}
+
+		if errors[0] != test.expectedError {
+			t.Errorf("Expected error message to be %q, got %q", test.expectedError, errors[0])
+		}
+	}
+}
+
+func TestImportStatement(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedPath string
+		expectedName string
+	}{
+		{`import "./utils.vorn" as utils;`, "./utils.vorn", "utils"},
+		{`import "lib/math.vorn" as m`, "lib/math.vorn", "m"},
+	}
+
+	for _, test := range tests {
+		program := initializeParserTest(t, test.input, 1)
+
+		statement, ok := program.Statements[0].(*ast.ImportStatement)
+
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ImportStatement. got %T", program.Statements[0])
+		}
+
+		if statement.Path.Value != test.expectedPath {
+			t.Errorf("statement.Path.Value not %q. got %q", test.expectedPath, statement.Path.Value)
+		}
+
+		if !checkIdentifier(t, statement.Name, test.expectedName) {
+			return
+		}
+	}
+}
+
+func TestExportStatement(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedName string
+	}{
+		{`export const PI = 3.14;`, "PI"},
+		{`export let counter = 0;`, "counter"},
+		{`export func double(x) { return x * 2; }`, "double"},
+	}
+
+	for _, test := range tests {
+		program := initializeParserTest(t, test.input, 1)
+
+		statement, ok := program.Statements[0].(*ast.ExportStatement)
+
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExportStatement. got %T", program.Statements[0])
+		}
+
+		if !checkIdentifier(t, statement.Name(), test.expectedName) {
+			return
+		}
+	}
+}
+
+func TestImportExportErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{`import utils;`, "[1:9]: expected 'STRING', got IDENT instead"},
+		{`import "./utils.vorn" utils;`, "[1:24]: expected 'AS', got IDENT instead"},
+		{`import "./utils.vorn" as 1;`, "[1:27]: expected 'IDENT', got INT instead"},
+		{`if (true) { import "./utils.vorn" as utils; }`, "[1:14]: import is only allowed at the top level"},
+		{`func f() { export const X = 1; }`, "[1:13]: export is only allowed at the top level"},
+		{`export 1;`, "[1:9]: can only export functions and variables, got INT"},
+		{`export func(x) { x };`, "[1:13]: can only export named functions"},
+		{`export const X = 1; X = 2;`, "[1:24] can not reassign constant X."},
+		{`export let x = 1; let x = 2;`, "[1:20] can not redefine variable x."},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l, false)
+		p.ParseProgram()
+
+		errors := p.Errors()
+
+		if len(errors) == 0 {
+			t.Fatalf("Expected a parser error for %q", test.input)
+		}
+
+		if errors[0] != test.expectedError {
+			t.Errorf("Expected error message to be %q, got %q", test.expectedError, errors[0])
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/iskandervdh/vorn/lexer"
 	"github.com/iskandervdh/vorn/object"
 	"github.com/iskandervdh/vorn/parser"
+	"github.com/iskandervdh/vorn/resolver"
 )
 
 // The REPL prompt to show the user.
@@ -20,6 +21,10 @@ func Start(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
 	// Create a new environment for the REPL that will persist between input lines.
 	env := object.NewEnvironment()
+	// Create a single evaluator so imported modules are cached between input lines.
+	e := evaluator.New()
+	// Create a single resolver so variables declared on earlier input lines can be used.
+	r := resolver.New(e)
 
 	for {
 		// Print the REPL prompt and wait for the user to enter a line.
@@ -45,11 +50,20 @@ func Start(in io.Reader, out io.Writer) {
 			continue
 		}
 
+		// If any identifiers could not be resolved, print them and continue to the next line.
+		if errors := r.Resolve(program); len(errors) != 0 {
+			resolver.PrintErrors(out, errors)
+			continue
+		}
+
 		// Otherwise evaluate the current line and print the result.
-		e := evaluator.New()
 		evaluated := e.Eval(program, env)
 
 		if evaluated != nil {
+			if err, ok := evaluated.(*object.Error); ok {
+				io.WriteString(out, err.Traceback())
+			}
+
 			io.WriteString(out, evaluated.Inspect())
 			io.WriteString(out, "\n")
 		}
@@ -0,0 +1,724 @@
+package resolver
+
+import (
+	"io"
+
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/object"
+)
+
+/*
+The builtin functions identifiers can refer to when they are not a variable
+*/
+type Builtins interface {
+	Builtin(name string) (*object.Builtin, bool)
+}
+
+type binding struct {
+	slot     int
+	declared bool   // If the declaration of the name has been resolved already
+	constant string // What declared the name if it can not be assigned, like "struct" or "import", empty for variables
+}
+
+/*
+A scope of the program that gets a frame of its own when it declares any names.
+
+The global scope stores its names by name instead of in a frame.
+*/
+type scope struct {
+	names    map[string]*binding
+	slots    int
+	global   bool
+	function bool // If the code inside the scope only runs when a function is called, after the enclosing scopes are resolved
+	outer    *scope
+}
+
+/*
+The resolver assigns every local variable a slot in the frame of the scope that declares it,
+and annotates every identifier with the frame and slot of the variable it refers to.
+It also marks the return statements that return a call in tail position.
+
+Identifiers that can not be resolved and variables that are used before their declaration
+are reported as errors before the program runs.
+*/
+type Resolver struct {
+	builtins Builtins
+	globals  map[string]string // Global names declared by the programs that were resolved before, with their constant kind
+	scope    *scope
+	tail     bool // If a return statement can replace the call of the function it is in with the call it returns
+	errors   []*object.Error
+}
+
+func New(builtins Builtins) *Resolver {
+	return &Resolver{
+		builtins: builtins,
+		globals:  map[string]string{},
+	}
+}
+
+/*
+Resolve the identifiers of a program.
+
+The global names of a program are remembered when it resolves without errors, so the programs
+resolved after it, like the lines of the REPL, can use them.
+
+Returns the errors that were found.
+*/
+func (r *Resolver) Resolve(program *ast.Program) []*object.Error {
+	r.errors = nil
+	r.scope = &scope{names: map[string]*binding{}, global: true}
+	r.tail = false
+
+	for name, constant := range r.globals {
+		r.scope.names[name] = &binding{declared: true, constant: constant}
+	}
+
+	for _, name := range declaredNames(program.Statements) {
+		if _, ok := r.scope.names[name.Value]; !ok {
+			r.scope.names[name.Value] = &binding{}
+		}
+	}
+
+	r.declareConstants(program.Statements)
+
+	for _, statement := range program.Statements {
+		r.resolveStatement(statement)
+	}
+
+	if len(r.errors) == 0 {
+		for name, b := range r.scope.names {
+			r.globals[name] = b.constant
+		}
+	}
+
+	return r.errors
+}
+
+/*
+Print the errors found by the resolver
+*/
+func PrintErrors(out io.Writer, errors []*object.Error) {
+	io.WriteString(out, "Resolution errors:\n")
+
+	for _, err := range errors {
+		io.WriteString(out, err.Message+"\n")
+	}
+}
+
+/*
+Get the names that are declared directly by the given statements, in the order they are declared in
+*/
+func declaredNames(statements []ast.Statement) []*ast.Identifier {
+	names := []*ast.Identifier{}
+
+	for _, statement := range statements {
+		names = append(names, statementNames(statement)...)
+	}
+
+	return names
+}
+
+func statementNames(statement ast.Statement) []*ast.Identifier {
+	switch statement := statement.(type) {
+	case *ast.VariableStatement:
+		return statement.Names()
+	case *ast.FunctionStatement:
+		return []*ast.Identifier{statement.Name}
+	case *ast.StructStatement:
+		return []*ast.Identifier{statement.Name}
+	case *ast.ImportStatement:
+		return []*ast.Identifier{statement.Name}
+	case *ast.ExportStatement:
+		return statementNames(statement.Statement)
+	case *ast.ForStatement:
+		// The variable of a for loop is declared in the scope that contains the loop
+		if statement.Init != nil {
+			return statementNames(statement.Init)
+		}
+	}
+
+	return nil
+}
+
+/*
+Get what a statement declares when the names it declares can not be assigned or declared again, like "struct" or "import".
+
+Returns an empty string for statements that declare variables.
+*/
+func constantKind(statement ast.Statement) string {
+	switch statement := statement.(type) {
+	case *ast.StructStatement:
+		return "struct"
+	case *ast.ImportStatement:
+		return "import"
+	case *ast.ExportStatement:
+		return constantKind(statement.Statement)
+	}
+
+	return ""
+}
+
+/*
+Get the node the declaration of a name by a statement is reported at, like the evaluator does when it is defined twice
+*/
+func declarationNode(statement ast.Statement) ast.Node {
+	switch statement := statement.(type) {
+	case *ast.ImportStatement:
+		return statement.Name
+	case *ast.ExportStatement:
+		return declarationNode(statement.Statement)
+	}
+
+	return statement
+}
+
+/*
+Mark the names that the statements of the current scope declare as constant, like the names of structs and imports.
+
+Names that are declared again in the same scope are reported when one of the declarations is a constant,
+other redefinitions are left to the parser and the evaluator.
+*/
+func (r *Resolver) declareConstants(statements []ast.Statement) {
+	// The constant kind of the names that are declared before, including the names of programs that were resolved before
+	declared := map[string]string{}
+
+	for name, b := range r.scope.names {
+		if b.declared {
+			declared[name] = b.constant
+		}
+	}
+
+	for _, statement := range statements {
+		constant := constantKind(statement)
+
+		for _, name := range statementNames(statement) {
+			if previous, ok := declared[name.Value]; ok && (previous != "" || constant != "") {
+				r.addError(declarationNode(statement), "identifier already defined: %s", name.Value)
+
+				continue
+			}
+
+			declared[name.Value] = constant
+			r.scope.names[name.Value].constant = constant
+		}
+	}
+}
+
+/*
+Enter a new scope that declares the given names, giving each distinct name a slot
+*/
+func (r *Resolver) pushScope(names []*ast.Identifier, function bool) *scope {
+	s := &scope{names: map[string]*binding{}, function: function, outer: r.scope}
+
+	for _, name := range names {
+		if _, ok := s.names[name.Value]; !ok {
+			s.names[name.Value] = &binding{slot: len(s.names)}
+		}
+	}
+
+	s.slots = len(s.names)
+	r.scope = s
+
+	return s
+}
+
+func (r *Resolver) popScope() {
+	r.scope = r.scope.outer
+}
+
+func (r *Resolver) addError(node ast.Node, format string, a ...interface{}) {
+	r.errors = append(r.errors, object.NewError(node, format, a...))
+}
+
+/*
+Find the variable an identifier refers to and store where it is in the identifier.
+
+A variable of an enclosing scope is used when the variable with the same name in an inner scope is not declared yet,
+so in `let x = x + 1;` the initializer reads the x that is being shadowed.
+
+Returns the binding of the variable, nil if there is none, and a boolean indicating if
+the variable is guaranteed to be declared when the identifier is evaluated.
+*/
+func (r *Resolver) lookup(ident *ast.Identifier) (*binding, bool) {
+	depth := 0
+	inFunction := false
+
+	var undeclared *binding
+	var undeclaredScope *scope
+	var undeclaredDepth int
+
+	for s := r.scope; s != nil; s = s.outer {
+		if b, ok := s.names[ident.Value]; ok {
+			if b.declared || inFunction {
+				ident.Local = !s.global
+				ident.Depth = depth
+				ident.Slot = b.slot
+
+				return b, true
+			}
+
+			if undeclared == nil {
+				undeclared, undeclaredScope, undeclaredDepth = b, s, depth
+			}
+		}
+
+		if s.slots > 0 && !s.global {
+			depth++
+		}
+
+		if s.function {
+			inFunction = true
+		}
+	}
+
+	if undeclared != nil {
+		ident.Local = !undeclaredScope.global
+		ident.Depth = undeclaredDepth
+		ident.Slot = undeclared.slot
+	}
+
+	return undeclared, false
+}
+
+/*
+Mark the variable an identifier declares as declared in the current scope
+*/
+func (r *Resolver) declare(ident *ast.Identifier) {
+	b := r.scope.names[ident.Value]
+
+	ident.Local = !r.scope.global
+	ident.Depth = 0
+	ident.Slot = b.slot
+
+	b.declared = true
+}
+
+func (r *Resolver) resolveIdentifier(ident *ast.Identifier) {
+	b, declared := r.lookup(ident)
+
+	if b == nil {
+		if _, ok := r.builtins.Builtin(ident.Value); ok {
+			ident.Local = false
+
+			return
+		}
+
+		r.addError(ident, "identifier not found: %s", ident.Value)
+
+		return
+	}
+
+	if !declared {
+		r.addError(ident, "identifier used before its declaration: %s", ident.Value)
+	}
+}
+
+/*
+Resolve the variable that is assigned to by a reassignment, increment or decrement
+*/
+func (r *Resolver) resolveAssignment(node ast.Node, ident *ast.Identifier) {
+	b, declared := r.lookup(ident)
+
+	if b == nil || !declared {
+		r.addError(node, "variable %s has not been initialized.", ident.Value)
+
+		return
+	}
+
+	if b.constant != "" {
+		r.addError(node, "can not reassign %s %s.", b.constant, ident.Value)
+	}
+}
+
+func (r *Resolver) resolveBlock(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+
+	s := r.pushScope(declaredNames(block.Statements), false)
+	block.Slots = s.slots
+
+	r.declareConstants(block.Statements)
+
+	for _, statement := range block.Statements {
+		r.resolveStatement(statement)
+	}
+
+	r.popScope()
+}
+
+/*
+Resolve the arguments and body of a function literal or statement.
+
+Returns the amount of slots of the frame the arguments are bound in.
+*/
+func (r *Resolver) resolveFunction(arguments []*ast.Identifier, defaults map[string]ast.Expression, rest *ast.Identifier, patterns map[string]ast.Expression, body *ast.BlockStatement) int {
+	names := []*ast.Identifier{}
+
+	for _, argument := range arguments {
+		if pattern, ok := patterns[argument.Value]; ok {
+			names = append(names, ast.PatternNames(pattern)...)
+		} else {
+			names = append(names, argument)
+		}
+	}
+
+	if rest != nil {
+		names = append(names, rest)
+	}
+
+	s := r.pushScope(names, true)
+	tail := r.tail
+	r.tail = true
+
+	// Default values are evaluated after the arguments before them are bound
+	for _, argument := range arguments {
+		if defaultValue, ok := defaults[argument.Value]; ok {
+			r.resolveExpression(defaultValue)
+		}
+
+		if pattern, ok := patterns[argument.Value]; ok {
+			r.resolvePattern(pattern)
+		} else {
+			r.declare(argument)
+		}
+	}
+
+	if rest != nil {
+		r.declare(rest)
+	}
+
+	r.resolveBlock(body)
+	r.popScope()
+	r.tail = tail
+
+	return s.slots
+}
+
+/*
+Declare the names of a destructuring pattern, resolving the default values of its elements in between
+*/
+func (r *Resolver) resolvePattern(pattern ast.Expression) {
+	var elements []*ast.PatternElement
+	var rest *ast.Identifier
+
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		r.declare(pattern)
+
+		return
+	case *ast.ArrayPattern:
+		elements, rest = pattern.Elements, pattern.Rest
+	case *ast.HashPattern:
+		elements, rest = pattern.Elements, pattern.Rest
+	}
+
+	for _, element := range elements {
+		if element.Default != nil {
+			r.resolveExpression(element.Default)
+		}
+
+		r.resolvePattern(element.Target)
+	}
+
+	if rest != nil {
+		r.declare(rest)
+	}
+}
+
+func (r *Resolver) resolveStruct(node *ast.StructStatement) {
+	r.declare(node.Name)
+
+	// Default values of fields are evaluated when the struct is instantiated
+	r.pushScope(nil, true)
+
+	for _, field := range node.Fields {
+		if defaultValue, ok := node.Defaults[field.Value]; ok {
+			r.resolveExpression(defaultValue)
+		}
+	}
+
+	r.popScope()
+
+	for _, method := range node.Methods {
+		// Methods are called with self bound in a frame of its own
+		self := r.pushScope([]*ast.Identifier{{Value: "self"}}, true)
+		self.names["self"].declared = true
+
+		method.Slots = r.resolveFunction(method.Arguments, method.Defaults, method.Rest, method.Patterns, method.Body)
+
+		r.popScope()
+	}
+}
+
+func (r *Resolver) resolveStatement(statement ast.Statement) {
+	switch statement := statement.(type) {
+	case *ast.ExpressionStatement:
+		r.resolveExpression(statement.Expression)
+
+	case *ast.VariableStatement:
+		r.resolveExpression(statement.Value)
+
+		if statement.Pattern != nil {
+			r.resolvePattern(statement.Pattern)
+		} else {
+			r.declare(statement.Name)
+		}
+
+	case *ast.ReturnStatement:
+		r.resolveExpression(statement.ReturnValue)
+
+		statement.TailCall = r.tail && isTailCall(statement.ReturnValue)
+
+	case *ast.BlockStatement:
+		r.resolveBlock(statement)
+
+	case *ast.FunctionStatement:
+		r.declare(statement.Name)
+		statement.Slots = r.resolveFunction(statement.Arguments, statement.Defaults, statement.Rest, statement.Patterns, statement.Body)
+
+	case *ast.StructStatement:
+		r.resolveStruct(statement)
+
+	case *ast.WhileStatement:
+		r.resolveExpression(statement.Condition)
+		r.resolveBlock(statement.Consequence)
+
+	case *ast.ForStatement:
+		if statement.Init != nil {
+			r.resolveStatement(statement.Init)
+		}
+
+		r.resolveExpression(statement.Condition)
+		r.resolveBlock(statement.Body)
+		r.resolveExpression(statement.Update)
+
+	case *ast.ForInStatement:
+		r.resolveExpression(statement.Iterable)
+
+		names := []*ast.Identifier{statement.Value}
+
+		if statement.Key != nil {
+			names = append([]*ast.Identifier{statement.Key}, names...)
+		}
+
+		s := r.pushScope(names, false)
+		statement.Slots = s.slots
+
+		for _, name := range names {
+			r.declare(name)
+		}
+
+		r.resolveBlock(statement.Body)
+		r.popScope()
+
+	case *ast.TryStatement:
+		// Calls inside a try statement have to return to it, so errors can be caught and the finally block runs
+		tail := r.tail
+		r.tail = false
+
+		r.resolveBlock(statement.Block)
+
+		if statement.CatchBlock != nil {
+			names := []*ast.Identifier{}
+
+			if statement.CatchParameter != nil {
+				names = append(names, statement.CatchParameter)
+			}
+
+			s := r.pushScope(names, false)
+			statement.CatchSlots = s.slots
+
+			for _, name := range names {
+				r.declare(name)
+			}
+
+			r.resolveBlock(statement.CatchBlock)
+			r.popScope()
+		}
+
+		r.resolveBlock(statement.FinallyBlock)
+		r.tail = tail
+
+	case *ast.ThrowStatement:
+		r.resolveExpression(statement.Value)
+
+	case *ast.ImportStatement:
+		r.declare(statement.Name)
+
+	case *ast.ExportStatement:
+		r.resolveStatement(statement.Statement)
+	}
+}
+
+func (r *Resolver) resolveExpressions(expressions []ast.Expression) {
+	for _, expression := range expressions {
+		r.resolveExpression(expression)
+	}
+}
+
+func (r *Resolver) resolveExpression(expression ast.Expression) {
+	switch expression := expression.(type) {
+	case *ast.Identifier:
+		r.resolveIdentifier(expression)
+
+	case *ast.PrefixExpression:
+		r.resolveExpression(expression.Right)
+
+	case *ast.InfixExpression:
+		r.resolveExpression(expression.Left)
+		r.resolveExpression(expression.Right)
+
+	case *ast.IfExpression:
+		r.resolveExpression(expression.Condition)
+		r.resolveBlock(expression.Consequence)
+		r.resolveBlock(expression.Alternative)
+
+	case *ast.TernaryExpression:
+		r.resolveExpression(expression.Condition)
+		r.resolveExpression(expression.Consequence)
+		r.resolveExpression(expression.Alternative)
+
+	case *ast.FunctionLiteral:
+		expression.Slots = r.resolveFunction(expression.Arguments, expression.Defaults, expression.Rest, expression.Patterns, expression.Body)
+
+	case *ast.CallExpression:
+		r.resolveExpression(expression.Function)
+		r.resolveExpressions(expression.Arguments)
+
+	case *ast.IndexExpression:
+		r.resolveExpression(expression.Left)
+		r.resolveExpression(expression.Index)
+
+	case *ast.ReassignmentExpression:
+		r.resolveAssignment(expression, expression.Name)
+		r.resolveExpression(expression.Value)
+
+	case *ast.IncrementDecrementExpression:
+		r.resolveAssignment(expression, expression.Identifier)
+
+	case *ast.MemberReassignmentExpression:
+		r.resolveExpression(expression.Target)
+		r.resolveExpression(expression.Value)
+
+	case *ast.MemberIncrementDecrementExpression:
+		r.resolveExpression(expression.Target)
+
+	case *ast.ChainingExpression:
+		r.resolveExpression(expression.Left)
+		r.resolveChainingRight(expression.Right)
+
+	case *ast.SpreadExpression:
+		r.resolveExpression(expression.Value)
+
+	case *ast.ArrayLiteral:
+		r.resolveExpressions(expression.Elements)
+
+	case *ast.HashLiteral:
+		for _, key := range expression.Keys {
+			r.resolveExpression(key)
+
+			if value, ok := expression.Pairs[key]; ok {
+				r.resolveExpression(value)
+			}
+		}
+
+	case *ast.TemplateLiteral:
+		r.resolveExpressions(expression.Parts)
+
+	case *ast.MatchExpression:
+		r.resolveExpression(expression.Subject)
+
+		for _, arm := range expression.Arms {
+			r.resolveMatchArm(arm)
+		}
+	}
+}
+
+/*
+Check if a returned expression ends in a call that can replace the call of the function, like f(x), self.m(x) or c ? f(x) : y
+*/
+func isTailCall(expression ast.Expression) bool {
+	switch expression := expression.(type) {
+	case *ast.CallExpression:
+		return true
+	case *ast.ChainingExpression:
+		_, ok := expression.Call()
+
+		return ok
+	case *ast.TernaryExpression:
+		return isTailCall(expression.Consequence) || isTailCall(expression.Alternative)
+	}
+
+	return false
+}
+
+/*
+Resolve the right-hand side of a chaining expression, where the name of the property or method is not a variable
+*/
+func (r *Resolver) resolveChainingRight(right ast.Node) {
+	switch right := right.(type) {
+	case *ast.CallExpression:
+		r.resolveExpressions(right.Arguments)
+	case *ast.ExpressionStatement:
+		r.resolveChainingRight(right.Expression)
+	}
+}
+
+/*
+Resolve an arm of a match expression. The names bound by any of its patterns share a frame
+that is visible to the guard and the body of the arm.
+*/
+func (r *Resolver) resolveMatchArm(arm *ast.MatchArm) {
+	names := []*ast.Identifier{}
+
+	for _, pattern := range arm.Patterns {
+		for _, name := range ast.PatternNames(pattern) {
+			if name.Value != "_" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	s := r.pushScope(names, false)
+	arm.Slots = s.slots
+
+	for _, pattern := range arm.Patterns {
+		r.resolveMatchPattern(pattern)
+	}
+
+	r.resolveExpression(arm.Guard)
+	r.resolveExpression(arm.Body)
+	r.popScope()
+}
+
+func (r *Resolver) resolveMatchPattern(pattern ast.Expression) {
+	var elements []*ast.PatternElement
+	var rest *ast.Identifier
+
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != "_" {
+			r.declare(pattern)
+		}
+
+		return
+	case *ast.ArrayPattern:
+		elements, rest = pattern.Elements, pattern.Rest
+	case *ast.HashPattern:
+		elements, rest = pattern.Elements, pattern.Rest
+	default:
+		// Literal patterns are compared to the value
+		r.resolveExpression(pattern)
+
+		return
+	}
+
+	for _, element := range elements {
+		r.resolveMatchPattern(element.Target)
+	}
+
+	if rest != nil {
+		r.declare(rest)
+	}
+}
@@ -0,0 +1,392 @@
+package resolver
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/lexer"
+	"github.com/iskandervdh/vorn/object"
+	"github.com/iskandervdh/vorn/parser"
+)
+
+type testBuiltins struct{}
+
+func (testBuiltins) Builtin(name string) (*object.Builtin, bool) {
+	if name == "print" {
+		return &object.Builtin{}, true
+	}
+
+	return nil, false
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l, false)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors for %q: %v", input, p.Errors())
+	}
+
+	return program
+}
+
+func errorMessages(errors []*object.Error) []string {
+	messages := []string{}
+
+	for _, err := range errors {
+		messages = append(messages, err.Message)
+	}
+
+	return messages
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let a = 1; print(a);", []string{}},
+		{"print(a);", []string{"[1:8] identifier not found: a"}},
+		{"print(a); let a = 1;", []string{"[1:8] identifier used before its declaration: a"}},
+		{"let a = a;", []string{"[1:10] identifier used before its declaration: a"}},
+		{"func f() { return a; } let a = 1; f();", []string{}},
+		{"func f() { return g(); } func g() { return 1; }", []string{}},
+		{"if (true) { print(a); let a = 1; }", []string{"[1:20] identifier used before its declaration: a"}},
+		{"if (true) { let a = 1; } print(a);", []string{"[1:33] identifier not found: a"}},
+		{"a = 1;", []string{"[1:4] variable a has not been initialized."}},
+		{"print = 1;", []string{"[1:8] variable print has not been initialized."}},
+		{"a++;", []string{"[1:4] variable a has not been initialized."}},
+		{"if (true) { a += 1; let a = 1; }", []string{"[1:17] variable a has not been initialized."}},
+		{"func f(a = b, b = 1) { return a; }", []string{"[1:13] identifier used before its declaration: b"}},
+		{"func f(a, b = a) { return b; }", []string{}},
+		{"let [a, b = a] = [1];", []string{}},
+		{"let [a = b, b] = [1];", []string{"[1:11] identifier used before its declaration: b"}},
+		{"for (x in [1]) { print(x); } print(x);", []string{"[1:37] identifier not found: x"}},
+		{"for (let i = 0; i < 3; i++) { } print(i);", []string{}},
+		{"try { } catch (e) { print(e); } print(e);", []string{"[1:40] identifier not found: e"}},
+		{"match (1) { [a, ...b] if a > b => a, x => x, _ => y };", []string{"[1:52] identifier not found: y"}},
+		{"match (1) { _ => _ };", []string{"[1:19] identifier not found: _"}},
+		{"struct P { x, y = x; }", []string{"[1:20] identifier not found: x"}},
+		{"struct P { x; func get() { return self.x + other; } }", []string{"[1:45] identifier not found: other"}},
+		{"let h = {\"a\": 1}; h.a; h.keys(); h.b = h.a;", []string{}},
+		{"let h = {a: 1};", []string{"[1:11] identifier not found: a"}},
+		{"[1].map(func(x) { return x + y; });", []string{"[1:31] identifier not found: y"}},
+		{`"x${a}";`, []string{"[1:6] identifier not found: a"}},
+		{`import "./m.vorn" as m; export func f(x) { return m.g(x); } export let [y] = [f(1)];`, []string{}},
+		{"let a = 0; while (a < 3) { a++; } a > 1 ? -a : a; throw a;", []string{}},
+		{"let h = {}; h.a = b; h[c]++; [...d]; {...e};", []string{
+			"[1:20] identifier not found: b",
+			"[1:25] identifier not found: c",
+			"[1:35] identifier not found: d",
+			"[1:43] identifier not found: e",
+		}},
+		{"match ([1]) { [1, x] => x, {a: [b], ...c} => b + c, -1 => 0 };", []string{}},
+		{"let a = 1; try { a = 2; } finally { a = 3; }", []string{}},
+		{"let a = 1; func f() { let a = a + 1; return a; }", []string{}},
+		{"let a = 1; if (true) { print(a); let a = 2; a += 1; }", []string{}},
+		{"func f() { let a = a; }", []string{"[1:21] identifier used before its declaration: a"}},
+		{"struct P { x; } struct P { y; }", []string{"[1:18] identifier already defined: P"}},
+		{"struct P { x; } let P = 1;", []string{"[1:18] identifier already defined: P"}},
+		{"func P() { } struct P { x; }", []string{"[1:15] identifier already defined: P"}},
+		{"if (true) { struct P { x; } struct P { y; } }", []string{"[1:30] identifier already defined: P"}},
+		{"struct P { x; } if (true) { let P = 1; P = 2; }", []string{}},
+		{"struct P { x; } P = 5;", []string{"[1:20] can not reassign struct P."}},
+		{"struct P { x; } P += 1; P++;", []string{"[1:21] can not reassign struct P.", "[1:28] can not reassign struct P."}},
+		{"struct P { x; } func f() { P = 1; }", []string{"[1:31] can not reassign struct P."}},
+		{`import "./m.vorn" as m; m = 1;`, []string{"[1:28] can not reassign import m."}},
+		{`import "./m.vorn" as m; m++;`, []string{"[1:28] can not reassign import m."}},
+		{`import "./m.vorn" as m; let m = 1;`, []string{"[1:26] identifier already defined: m"}},
+		{`import "./m.vorn" as m; import "./n.vorn" as m;`, []string{"[1:47] identifier already defined: m"}},
+		{`import "./m.vorn" as m; func f() { let m = 1; m = 2; }`, []string{}},
+	}
+
+	for _, tt := range tests {
+		errors := New(testBuiltins{}).Resolve(parse(t, tt.input))
+		messages := errorMessages(errors)
+
+		if len(messages) != len(tt.expected) {
+			t.Errorf("wrong errors for %q. got %v, want %v", tt.input, messages, tt.expected)
+			continue
+		}
+
+		for i, message := range messages {
+			if message != tt.expected[i] {
+				t.Errorf("wrong error for %q. got %q, want %q", tt.input, message, tt.expected[i])
+			}
+		}
+	}
+}
+
+/*
+Find the identifiers with the given name in the order they appear in the program
+*/
+func findIdentifiers(program *ast.Program, name string) []*ast.Identifier {
+	identifiers := []*ast.Identifier{}
+
+	var visit func(node any)
+
+	visit = func(node any) {
+		switch node := node.(type) {
+		case *ast.Program:
+			for _, statement := range node.Statements {
+				visit(statement)
+			}
+		case *ast.BlockStatement:
+			for _, statement := range node.Statements {
+				visit(statement)
+			}
+		case *ast.ExpressionStatement:
+			visit(node.Expression)
+		case *ast.VariableStatement:
+			visit(node.Name)
+			visit(node.Value)
+		case *ast.ReturnStatement:
+			visit(node.ReturnValue)
+		case *ast.FunctionStatement:
+			visit(node.Name)
+
+			for _, argument := range node.Arguments {
+				visit(argument)
+			}
+
+			visit(node.Body)
+		case *ast.FunctionLiteral:
+			for _, argument := range node.Arguments {
+				visit(argument)
+			}
+
+			visit(node.Body)
+		case *ast.IfExpression:
+			visit(node.Condition)
+			visit(node.Consequence)
+		case *ast.InfixExpression:
+			visit(node.Left)
+			visit(node.Right)
+		case *ast.CallExpression:
+			visit(node.Function)
+
+			for _, argument := range node.Arguments {
+				visit(argument)
+			}
+		case *ast.Identifier:
+			if node != nil && node.Value == name {
+				identifiers = append(identifiers, node)
+			}
+		}
+	}
+
+	visit(program)
+
+	return identifiers
+}
+
+func TestResolveSlots(t *testing.T) {
+	tests := []struct {
+		input    string
+		name     string
+		expected []string // Where each identifier with the name is stored, as local:depth:slot or global
+	}{
+		{"let a = 1; a;", "a", []string{"global", "global"}},
+		{"func f(a, b) { return b; }", "b", []string{"0:1", "0:1"}},
+		{"func f(a) { let b = 1; return a + b; }", "a", []string{"0:0", "1:0"}},
+		{"func f(a) { let b = 1; if (true) { let c = 2; return a + b + c; } }", "a", []string{"0:0", "2:0"}},
+		{"func f(a) { if (true) { return a; } }", "a", []string{"0:0", "0:0"}},
+		{"func f() { let a = 1; return func(b) { return a + b; }; }", "a", []string{"0:0", "1:0"}},
+		{"func f() { let a = 1; let b = 2; return b; }", "b", []string{"0:1", "0:1"}},
+		{"func f() { return print; }", "print", []string{"global"}},
+		{"func f(a) { if (true) { let a = a + 1; return a; } }", "a", []string{"0:0", "0:0", "1:0", "0:0"}},
+		{"func f() { let a = 1; if (true) { let b = 2; let a = a + b; } }", "a", []string{"0:0", "0:1", "1:0"}},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+
+		if errors := New(testBuiltins{}).Resolve(program); len(errors) != 0 {
+			t.Fatalf("resolver errors for %q: %v", tt.input, errorMessages(errors))
+		}
+
+		identifiers := findIdentifiers(program, tt.name)
+
+		if len(identifiers) != len(tt.expected) {
+			t.Fatalf("wrong amount of identifiers %s in %q. got %d, want %d", tt.name, tt.input, len(identifiers), len(tt.expected))
+		}
+
+		for i, identifier := range identifiers {
+			got := "global"
+
+			if identifier.Local {
+				got = string(rune('0'+identifier.Depth)) + ":" + string(rune('0'+identifier.Slot))
+			}
+
+			if got != tt.expected[i] {
+				t.Errorf("wrong location of identifier %d in %q. got %s, want %s", i, tt.input, got, tt.expected[i])
+			}
+		}
+	}
+}
+
+func TestResolveScopeSlots(t *testing.T) {
+	program := parse(t, `
+func f(a, [b, c], ...d) {
+	let e = 1;
+	for (k, v in [1]) { }
+	try { } catch (err) { }
+	if (true) { 1; }
+	return match (a) { [x, y], [y, x] => x, _ => 0 };
+}`)
+
+	if errors := New(testBuiltins{}).Resolve(program); len(errors) != 0 {
+		t.Fatalf("resolver errors: %v", errorMessages(errors))
+	}
+
+	function := program.Statements[0].(*ast.FunctionStatement)
+	body := function.Body
+	forIn := body.Statements[1].(*ast.ForInStatement)
+	try := body.Statements[2].(*ast.TryStatement)
+	ifBlock := body.Statements[3].(*ast.ExpressionStatement).Expression.(*ast.IfExpression).Consequence
+	match := body.Statements[4].(*ast.ReturnStatement).ReturnValue.(*ast.MatchExpression)
+
+	tests := []struct {
+		name     string
+		got      int
+		expected int
+	}{
+		{"function", function.Slots, 4},
+		{"body", body.Slots, 1},
+		{"for-in", forIn.Slots, 2},
+		{"catch", try.CatchSlots, 1},
+		{"try", try.Block.Slots, 0},
+		{"if", ifBlock.Slots, 0},
+		{"first arm", match.Arms[0].Slots, 2},
+		{"second arm", match.Arms[1].Slots, 0},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.expected {
+			t.Errorf("wrong amount of slots for %s. got %d, want %d", tt.name, tt.got, tt.expected)
+		}
+	}
+}
+
+func TestResolveTailCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"func f() { return f(); }", true},
+		{"func f() { if (true) { while (true) { return f(); } } }", true},
+		{"let f = func() { return f(); };", true},
+		{"struct S { func m() { return self.m(); } }", true},
+		{"struct S { x; func m() { return self.x; } }", false},
+		{"func f(c) { return c ? f(false) : 1; }", true},
+		{"func f(c) { return c ? 1 : [1].map(f); }", true},
+		{"func f(c) { return c ? 1 : 2; }", false},
+		{"struct S { func m() { return m(); } }", true},
+		{"func f() { return 1 + f(); }", false},
+		{"func f() { try { return f(); } catch (e) { } }", false},
+		{"func f() { try { } catch (e) { return f(); } }", false},
+		{"func f() { try { } finally { return f(); } }", false},
+		{"func f() { try { func() { return f(); }; } finally { } }", true},
+		{"func f() { return f(); } return f();", false},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		New(testBuiltins{}).Resolve(program)
+
+		// The last return statement of the program
+		var last *ast.ReturnStatement
+		var visit func(node ast.Node)
+
+		visit = func(node ast.Node) {
+			switch node := node.(type) {
+			case *ast.ReturnStatement:
+				last = node
+			case *ast.BlockStatement:
+				if node == nil {
+					return
+				}
+
+				for _, statement := range node.Statements {
+					visit(statement)
+				}
+			case *ast.ExpressionStatement:
+				visit(node.Expression)
+			case *ast.VariableStatement:
+				visit(node.Value)
+			case *ast.FunctionStatement:
+				visit(node.Body)
+			case *ast.FunctionLiteral:
+				visit(node.Body)
+			case *ast.StructStatement:
+				for _, method := range node.Methods {
+					visit(method.Body)
+				}
+			case *ast.IfExpression:
+				visit(node.Consequence)
+			case *ast.WhileStatement:
+				visit(node.Consequence)
+			case *ast.TryStatement:
+				visit(node.Block)
+				visit(node.CatchBlock)
+				visit(node.FinallyBlock)
+			}
+		}
+
+		for _, statement := range program.Statements {
+			visit(statement)
+		}
+
+		if last == nil {
+			t.Fatalf("no return statement in %q", tt.input)
+		}
+
+		if last.TailCall != tt.expected {
+			t.Errorf("wrong tail call of %q. got %t, want %t", tt.input, last.TailCall, tt.expected)
+		}
+	}
+}
+
+func TestResolveRemembersGlobals(t *testing.T) {
+	r := New(testBuiltins{})
+
+	if errors := r.Resolve(parse(t, "let a = b;")); len(errors) != 1 {
+		t.Fatalf("wrong amount of errors. got %v, want 1", errorMessages(errors))
+	}
+
+	// Names of programs with errors are not remembered
+	if errors := r.Resolve(parse(t, "a;")); len(errors) != 1 {
+		t.Fatalf("wrong amount of errors. got %v, want 1", errorMessages(errors))
+	}
+
+	if errors := r.Resolve(parse(t, "let a = 1;")); len(errors) != 0 {
+		t.Fatalf("resolver errors: %v", errorMessages(errors))
+	}
+
+	if errors := r.Resolve(parse(t, "a;")); len(errors) != 0 {
+		t.Fatalf("resolver errors: %v", errorMessages(errors))
+	}
+
+	// Structs stay constant in the programs resolved after them
+	if errors := r.Resolve(parse(t, "struct P { x; }")); len(errors) != 0 {
+		t.Fatalf("resolver errors: %v", errorMessages(errors))
+	}
+
+	if errors := r.Resolve(parse(t, "P = 1; struct P { y; }")); len(errors) != 2 {
+		t.Fatalf("wrong amount of errors. got %v, want 2", errorMessages(errors))
+	}
+}
+
+func TestPrintErrors(t *testing.T) {
+	var out bytes.Buffer
+
+	PrintErrors(&out, New(testBuiltins{}).Resolve(parse(t, "a; b;")))
+
+	expected := "Resolution errors:\n[1:2] identifier not found: a\n[1:5] identifier not found: b\n"
+
+	if out.String() != expected {
+		t.Errorf("wrong output. got %q, want %q", out.String(), expected)
+	}
+}
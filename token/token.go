@@ -22,6 +22,12 @@ const (
 	FLOAT  = "FLOAT"
 	STRING = "STRING"
 
+	// Parts of an interpolated string like "a ${b} c ${d} e",
+	// TEMPLATE_START is "a ", TEMPLATE_MIDDLE is " c " and TEMPLATE_END is " e"
+	TEMPLATE_START  = "TEMPLATE_START"
+	TEMPLATE_MIDDLE = "TEMPLATE_MIDDLE"
+	TEMPLATE_END    = "TEMPLATE_END"
+
 	// Operators
 	ASSIGN      = "="
 	PLUS        = "+"
@@ -70,9 +76,12 @@ const (
 
 	// Delimiters
 	DOT       = "."
+	ELLIPSIS  = "..."
 	COMMA     = ","
 	SEMICOLON = ";"
 	COLON     = ":"
+	ARROW     = "=>"
+	QUESTION  = "?"
 
 	LPAREN   = "("
 	RPAREN   = ")"
@@ -93,8 +102,18 @@ const (
 	RETURN   = "RETURN"
 	WHILE    = "WHILE"
 	FOR      = "FOR"
+	IN       = "IN"
 	BREAK    = "BREAK"
 	CONTINUE = "CONTINUE"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
+	FINALLY  = "FINALLY"
+	THROW    = "THROW"
+	IMPORT   = "IMPORT"
+	EXPORT   = "EXPORT"
+	AS       = "AS"
+	MATCH    = "MATCH"
+	STRUCT   = "STRUCT"
 )
 
 var AssignmentOperators = []TokenType{
@@ -111,6 +130,22 @@ var AssignmentOperators = []TokenType{
 	RIGHT_SHIFT_ASSIGN,
 }
 
+/*
+The infix operator each compound assignment operator applies, e.g. + for +=
+*/
+var assignmentInfixOperators = map[TokenType]TokenType{
+	PLUS_ASSIGN:        PLUS,
+	MINUS_ASSIGN:       MINUS,
+	MULTIPLY_ASSIGN:    ASTERISK,
+	DIVIDE_ASSIGN:      SLASH,
+	MODULO_ASSIGN:      PERCENT,
+	BITWISE_OR_ASSIGN:  BITWISE_OR,
+	BITWISE_AND_ASSIGN: BITWISE_AND,
+	BITWISE_XOR_ASSIGN: BITWISE_XOR,
+	LEFT_SHIFT_ASSIGN:  LEFT_SHIFT,
+	RIGHT_SHIFT_ASSIGN: RIGHT_SHIFT,
+}
+
 /*
 Keywords are stored in a map where the key is the keyword and the value is the TokenType.
 
@@ -128,8 +163,18 @@ var keywords = map[string]TokenType{
 	"return":   RETURN,
 	"while":    WHILE,
 	"for":      FOR,
+	"in":       IN,
 	"break":    BREAK,
 	"continue": CONTINUE,
+	"try":      TRY,
+	"catch":    CATCH,
+	"finally":  FINALLY,
+	"throw":    THROW,
+	"import":   IMPORT,
+	"export":   EXPORT,
+	"as":       AS,
+	"match":    MATCH,
+	"struct":   STRUCT,
 }
 
 /*
@@ -153,3 +198,14 @@ func LookupIdent(ident string) TokenType {
 
 	return IDENT
 }
+
+/*
+Lookup the infix operator a compound assignment operator applies, e.g. + for +=.
+
+Returns the infix operator and a boolean indicating if the assignment operator is a compound assignment operator.
+*/
+func AssignmentInfixOperator(assignmentOperator TokenType) (TokenType, bool) {
+	operator, ok := assignmentInfixOperators[assignmentOperator]
+
+	return operator, ok
+}
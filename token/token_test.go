@@ -61,3 +61,25 @@ func TestLookupIdent(t *testing.T) {
 		t.Errorf("Expected ident to be %s, got %s", IDENT, ident)
 	}
 }
+
+func TestAssignmentInfixOperator(t *testing.T) {
+	tests := []struct {
+		assignmentOperator TokenType
+		expected           TokenType
+		ok                 bool
+	}{
+		{PLUS_ASSIGN, PLUS, true},
+		{MULTIPLY_ASSIGN, ASTERISK, true},
+		{RIGHT_SHIFT_ASSIGN, RIGHT_SHIFT, true},
+		{ASSIGN, "", false},
+		{PLUS, "", false},
+	}
+
+	for _, test := range tests {
+		operator, ok := AssignmentInfixOperator(test.assignmentOperator)
+
+		if operator != test.expected || ok != test.ok {
+			t.Errorf("Expected %s to apply %q (%t), got %q (%t)", test.assignmentOperator, test.expected, test.ok, operator, ok)
+		}
+	}
+}
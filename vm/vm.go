@@ -0,0 +1,1055 @@
+package vm
+
+import (
+	"math"
+	"strings"
+
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/compiler"
+	"github.com/iskandervdh/vorn/object"
+	"github.com/iskandervdh/vorn/token"
+)
+
+/*
+The operations of the language the vm shares with the evaluator, so both give the same results and errors.
+
+The evaluator in the evaluator package implements this interface.
+*/
+type Runtime interface {
+	Eval(node ast.Node, env *object.Environment) object.Object
+	Iterator(node *ast.ForInStatement, iterable object.Object) (func() (object.Object, object.Object, bool), *object.Error)
+	RangeIterator(node *ast.CallExpression, args []object.Object) (func() (object.Object, object.Object, bool), *object.Error)
+	Builtin(name string) (*object.Builtin, bool)
+	Prefix(node *ast.PrefixExpression, right object.Object) object.Object
+	Infix(node *ast.InfixExpression, left, right object.Object) object.Object
+	Index(node *ast.IndexExpression, left, index object.Object) object.Object
+	Call(node ast.Node, function object.Object, args []object.Object) object.Object
+	ExtendFunctionEnv(node ast.Node, function *object.Function, args []object.Object) (*object.Environment, *object.Error)
+	ChainingCall(node *ast.CallExpression, left object.Object, args []object.Object) object.Object
+	ChainingMethod(node *ast.CallExpression, left object.Object) (*object.Function, bool)
+	ChainingProperty(node *ast.Identifier, left object.Object) object.Object
+	IncrementDecrement(node *ast.IncrementDecrementExpression, current object.Object) object.Object
+	AssignMember(node *ast.MemberReassignmentExpression, container, key, value object.Object) *object.Error
+	IncrementDecrementMember(node *ast.MemberIncrementDecrementExpression, container, key object.Object) object.Object
+	EnterCall(node ast.Node, function object.Object) *object.Error
+	LeaveCall()
+	RecordCallStack(err *object.Error)
+	File() string
+	Throw(node *ast.ThrowStatement, value object.Object, env *object.Environment) *object.Error
+	CaughtError(node *ast.Identifier, err *object.Error, env *object.Environment) *object.Hash
+	ToString(node ast.Node, value object.Object) object.Object
+	SetCompiledCall(call func(node ast.Node, function *object.Function, args []object.Object) object.Object)
+}
+
+/*
+A call of a compiled function, or the main program
+*/
+type frame struct {
+	function *compiler.Function
+	ip       int // The position of the next instruction
+	env      *object.Environment
+	base     int  // The stack pointer before the function and its arguments were pushed
+	callback bool // If the function was called by the runtime, which gets the value the function returns
+
+	iterators []func() (object.Object, object.Object, bool) // The iterators of the for-in loops that are running
+}
+
+/*
+The state to restore when an error is ignored
+*/
+type handler struct {
+	target    int // The instruction to continue at
+	frames    int
+	sp        int
+	env       *object.Environment
+	iterators int // The amount of iterators of the frame the handler belongs to
+}
+
+type VM struct {
+	runtime Runtime
+
+	stack []object.Object
+	sp    int // Points to the next free slot of the stack
+
+	frames   []*frame
+	handlers []handler
+
+	bytecode *compiler.Bytecode
+	file     string             // The file the functions of the bytecode are defined in
+	globals  []*object.Variable // The global variables of the bytecode that is running, nil until they are first used
+
+	last object.Object // The value of the last statement of the main program
+}
+
+func New(runtime Runtime) *VM {
+	return &VM{runtime: runtime}
+}
+
+/*
+Run compiled bytecode in the given environment.
+
+Returns the value of the last statement or the value of a top level return statement,
+like the evaluator does for a program, or the error that stopped the program.
+*/
+func (vm *VM) Run(bytecode *compiler.Bytecode, env *object.Environment) (result object.Object) {
+	vm.stack = make([]object.Object, 0, 256)
+	vm.sp = 0
+	vm.frames = []*frame{{function: bytecode.Main, env: env}}
+	vm.handlers = nil
+	vm.last = nil
+	vm.bytecode = bytecode
+	vm.file = vm.runtime.File()
+	vm.globals = make([]*object.Variable, len(bytecode.Globals))
+
+	// Leave the calls that are still running when the program stops, after the node of an internal error is found
+	defer vm.popFrames(1)
+	defer vm.recoverInternalError(&result)
+
+	vm.runtime.SetCompiledCall(vm.callback)
+	defer vm.runtime.SetCompiledCall(nil)
+
+	return vm.run()
+}
+
+/*
+Call a compiled function for the runtime, like a function passed to a builtin or chaining function,
+running its bytecode until it returns.
+
+Errors of the function are returned to the runtime instead of being handled by the handlers of the code that is running.
+*/
+func (vm *VM) callback(node ast.Node, function *object.Function, args []object.Object) object.Object {
+	if err := vm.runtime.EnterCall(node, function); err != nil {
+		return err
+	}
+
+	env, err := vm.runtime.ExtendFunctionEnv(node, function, args)
+
+	if err != nil {
+		vm.runtime.LeaveCall()
+
+		return err
+	}
+
+	frames, base, handlers := len(vm.frames), vm.sp, vm.handlers
+	vm.frames = append(vm.frames, &frame{function: function.Compiled.(*compiler.Function), env: env, base: base, callback: true})
+	vm.handlers = nil
+
+	// After an error or a panic the frames of the function and the calls it made are still there
+	defer func() {
+		vm.popFrames(frames)
+		vm.popN(vm.sp - base)
+		vm.handlers = handlers
+	}()
+
+	return vm.run()
+}
+
+/*
+Recover from a panic in the interpreter itself while running the bytecode.
+
+Instead of crashing with a Go stack trace the panic is turned into an error
+at the node of the instruction that was running, like the evaluator does.
+*/
+func (vm *VM) recoverInternalError(result *object.Object) {
+	recovered := recover()
+
+	if recovered == nil {
+		return
+	}
+
+	node := vm.currentNode()
+
+	if node == nil { // coverage-ignore
+		panic(recovered)
+	}
+
+	*result = object.NewError(node, "internal error: %v", recovered)
+}
+
+/*
+Get the node of the instruction that is running, or the closest node of an earlier instruction
+*/
+func (vm *VM) currentNode() ast.Node {
+	for i := len(vm.frames) - 1; i >= 0; i-- {
+		fr := vm.frames[i]
+		ins := fr.function.Instructions
+		var node ast.Node
+
+		// Instructions are decoded from the start as operands can look like opcodes
+		for position := 0; position < len(ins) && position < fr.ip; {
+			definition, err := compiler.Lookup(ins[position])
+
+			if err != nil { // coverage-ignore
+				break
+			}
+
+			operands, read := compiler.ReadOperands(definition, ins[position+1:])
+
+			if definition.HasNode {
+				node = fr.function.Bytecode.Nodes[operands[0]]
+			}
+
+			position += 1 + read
+		}
+
+		if node != nil {
+			return node
+		}
+	}
+
+	return nil
+}
+
+func (vm *VM) push(obj object.Object) {
+	if vm.sp == len(vm.stack) {
+		vm.stack = append(vm.stack, obj)
+	} else {
+		vm.stack[vm.sp] = obj
+	}
+
+	vm.sp++
+}
+
+func (vm *VM) pop() object.Object {
+	vm.sp--
+	obj := vm.stack[vm.sp]
+	vm.stack[vm.sp] = nil
+
+	return obj
+}
+
+/*
+Handle an error by continuing at the innermost handler with the error on top of the stack.
+
+Returns false if there is no handler and the error stops the program.
+*/
+func (vm *VM) handleError(err *object.Error) bool {
+	if len(vm.handlers) == 0 {
+		return false
+	}
+
+	h := vm.handlers[len(vm.handlers)-1]
+	vm.handlers = vm.handlers[:len(vm.handlers)-1]
+
+	for vm.sp > h.sp {
+		vm.pop()
+	}
+
+	// Like the evaluator the calls the error passed through are recorded before they are left
+	if len(vm.frames) > h.frames {
+		vm.runtime.RecordCallStack(err)
+	}
+
+	vm.popFrames(h.frames)
+	vm.push(err)
+	fr := vm.frames[len(vm.frames)-1]
+	fr.env = h.env
+	fr.ip = h.target
+	fr.iterators = fr.iterators[:h.iterators]
+
+	return true
+}
+
+func (vm *VM) run() object.Object {
+	fr := vm.frames[len(vm.frames)-1]
+	ins := fr.function.Instructions
+	bytecode := fr.function.Bytecode
+
+	// Continue with the frame on top of the frame stack after a call, return or handled error
+	reload := func() {
+		fr = vm.frames[len(vm.frames)-1]
+		ins = fr.function.Instructions
+		bytecode = fr.function.Bytecode
+	}
+
+	for {
+		if fr.ip >= len(ins) {
+			return vm.last
+		}
+
+		op := compiler.Opcode(ins[fr.ip])
+		fr.ip++
+
+		var result object.Object
+
+		switch op {
+		case compiler.OpConstant:
+			index := compiler.ReadUint32(ins[fr.ip:])
+			fr.ip += 4
+
+			vm.push(bytecode.Constants[index])
+
+		case compiler.OpNull:
+			vm.push(object.NULL)
+
+		case compiler.OpTrue:
+			vm.push(object.TRUE)
+
+		case compiler.OpFalse:
+			vm.push(object.FALSE)
+
+		case compiler.OpNil:
+			vm.push(nil)
+
+		case compiler.OpPop:
+			vm.last = vm.pop()
+
+		case compiler.OpJump:
+			fr.ip = int(compiler.ReadUint32(ins[fr.ip:]))
+
+		case compiler.OpJumpNotTruthy:
+			target := int(compiler.ReadUint32(ins[fr.ip:]))
+			fr.ip += 4
+
+			if !isTruthy(vm.pop()) {
+				fr.ip = target
+			}
+
+		case compiler.OpAnd, compiler.OpOr:
+			target := int(compiler.ReadUint32(ins[fr.ip:]))
+			fr.ip += 4
+
+			if isTruthy(vm.stack[vm.sp-1]) == (op == compiler.OpOr) {
+				fr.ip = target
+			} else {
+				vm.pop()
+			}
+
+		case compiler.OpGetName:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])].(*ast.Identifier)
+			fr.ip += 4
+
+			result = vm.getName(node, fr.env)
+
+		case compiler.OpDeclare:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])]
+			fr.ip += 4
+
+			name := definedName(node)
+
+			if fr.env.IsDefined(name) {
+				result = object.NewError(node, "identifier already defined: %s", name.Value)
+			}
+
+		case compiler.OpDefine:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])]
+			fr.ip += 4
+
+			value := vm.pop()
+
+			// Name functions defined like `let add = func(a, b) { ... }` after their variable
+			if statement, ok := node.(*ast.VariableStatement); ok {
+				if _, ok := statement.Value.(*ast.FunctionLiteral); ok {
+					value.(*object.Function).Name = statement.Name.Value
+				}
+			}
+
+			fr.env.Define(definedName(node), value)
+
+		case compiler.OpGetVariable:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])]
+			fr.ip += 4
+
+			name := assignedName(node)
+			value, defined := fr.env.Lookup(name)
+
+			if !defined {
+				result = object.NewError(node, "variable %s has not been initialized.", name.Value)
+			} else {
+				vm.push(value)
+			}
+
+		case compiler.OpAssign:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])]
+			fr.ip += 4
+
+			name := assignedName(node)
+
+			if !fr.env.Assign(name, vm.pop()) {
+				result = object.NewError(node, "variable %s has not been initialized.", name.Value)
+			}
+
+		case compiler.OpGetGlobal:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])]
+			variable := vm.global(bytecode, int(compiler.ReadUint32(ins[fr.ip+4:])), fr.env)
+			fr.ip += 8
+
+			if variable.Value != nil {
+				vm.push(variable.Value)
+
+				break
+			}
+
+			if identifier, ok := node.(*ast.Identifier); ok {
+				result = vm.getName(identifier, fr.env)
+			} else {
+				result = object.NewError(node, "variable %s has not been initialized.", assignedName(node).Value)
+			}
+
+		case compiler.OpSetGlobal:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])]
+			variable := vm.global(bytecode, int(compiler.ReadUint32(ins[fr.ip+4:])), fr.env)
+			fr.ip += 8
+
+			if variable.Value == nil {
+				result = object.NewError(node, "variable %s has not been initialized.", assignedName(node).Value)
+			} else {
+				variable.Value = vm.pop()
+			}
+
+		case compiler.OpIncrement:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])].(*ast.IncrementDecrementExpression)
+			fr.ip += 4
+
+			current := vm.pop()
+			updated := vm.incrementDecrement(node, current)
+
+			if updated.Type() == object.ERROR_OBJ {
+				result = updated
+
+				break
+			}
+
+			if node.Before {
+				vm.push(current)
+			} else {
+				vm.push(updated)
+			}
+
+			vm.push(updated)
+
+		case compiler.OpSetMember:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])].(*ast.MemberReassignmentExpression)
+			fr.ip += 4
+
+			value := vm.pop()
+			key := vm.pop()
+			container := vm.pop()
+
+			if err := vm.runtime.AssignMember(node, container, key, value); err != nil {
+				result = err
+			} else {
+				vm.push(nil)
+			}
+
+		case compiler.OpIncrementMember:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])].(*ast.MemberIncrementDecrementExpression)
+			fr.ip += 4
+
+			key := vm.pop()
+			container := vm.pop()
+
+			result = vm.runtime.IncrementDecrementMember(node, container, key)
+
+		case compiler.OpPrefix:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])].(*ast.PrefixExpression)
+			fr.ip += 4
+
+			result = vm.runtime.Prefix(node, vm.pop())
+
+		case compiler.OpInfix:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])].(*ast.InfixExpression)
+			fr.ip += 4
+
+			right := vm.pop()
+			left := vm.pop()
+
+			result = vm.infix(node, left, right)
+
+		case compiler.OpIndex:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])].(*ast.IndexExpression)
+			fr.ip += 4
+
+			index := vm.pop()
+			left := vm.pop()
+
+			result = vm.runtime.Index(node, left, index)
+
+		case compiler.OpArray:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])]
+			count := int(compiler.ReadUint16(ins[fr.ip+4:]))
+			fr.ip += 6
+
+			elements := make([]object.Object, count)
+			copy(elements, vm.stack[vm.sp-count:vm.sp])
+			vm.popN(count)
+
+			result = object.NewArray(node, elements)
+
+		case compiler.OpHash:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])]
+			count := int(compiler.ReadUint16(ins[fr.ip+4:]))
+			fr.ip += 6
+
+			result = vm.buildHash(node, vm.stack[vm.sp-2*count:vm.sp])
+			vm.popN(2 * count)
+
+		case compiler.OpToString:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])]
+			fr.ip += 4
+
+			result = vm.runtime.ToString(node, vm.pop())
+
+		case compiler.OpTemplate:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])]
+			count := int(compiler.ReadUint16(ins[fr.ip+4:]))
+			fr.ip += 6
+
+			var out strings.Builder
+
+			for _, part := range vm.stack[vm.sp-count : vm.sp] {
+				out.WriteString(part.(*object.String).Value)
+			}
+
+			vm.popN(count)
+			result = object.NewString(node, out.String())
+
+		case compiler.OpClosure:
+			function := bytecode.Functions[compiler.ReadUint32(ins[fr.ip:])]
+			fr.ip += 4
+
+			vm.push(newFunction(function, fr.env, vm.file))
+
+		case compiler.OpCall:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])]
+			count := int(compiler.ReadUint16(ins[fr.ip+4:]))
+			fr.ip += 6
+
+			base := vm.sp - count - 1
+			function := vm.stack[base]
+
+			if function, ok := function.(*object.Function); ok && function.Compiled != nil {
+				if err := vm.runtime.EnterCall(node, function); err != nil {
+					result = err
+
+					break
+				}
+
+				env, err := vm.runtime.ExtendFunctionEnv(node, function, vm.stack[base+1:vm.sp])
+
+				if err != nil {
+					vm.runtime.LeaveCall()
+					result = err
+
+					break
+				}
+
+				vm.frames = append(vm.frames, &frame{function: function.Compiled.(*compiler.Function), env: env, base: base})
+				reload()
+
+				continue
+			}
+
+			args := make([]object.Object, count)
+			copy(args, vm.stack[base+1:vm.sp])
+			vm.popN(count + 1)
+
+			result = vm.runtime.Call(node, function, args)
+
+		case compiler.OpTailCall:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])]
+			count := int(compiler.ReadUint16(ins[fr.ip+4:]))
+			fr.ip += 6
+
+			base := vm.sp - count - 1
+			function := vm.stack[base]
+			args := make([]object.Object, count)
+			copy(args, vm.stack[base+1:vm.sp])
+			vm.popN(count + 1)
+
+			if function, ok := function.(*object.Function); ok && function.Compiled != nil {
+				if err := vm.replaceFrame(node, function, args); err != nil {
+					result = err
+
+					break
+				}
+
+				reload()
+
+				continue
+			}
+
+			result = vm.runtime.Call(node, function, args)
+
+		case compiler.OpChainCall:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])].(*ast.CallExpression)
+			count := int(compiler.ReadUint16(ins[fr.ip+4:]))
+			fr.ip += 6
+
+			base := vm.sp - count - 1
+			left := vm.stack[base]
+			args := make([]object.Object, count)
+			copy(args, vm.stack[base+1:vm.sp])
+			vm.popN(count + 1)
+
+			result = vm.runtime.ChainingCall(node, left, args)
+
+		case compiler.OpTailChainCall:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])].(*ast.CallExpression)
+			count := int(compiler.ReadUint16(ins[fr.ip+4:]))
+			fr.ip += 6
+
+			base := vm.sp - count - 1
+			left := vm.stack[base]
+			args := make([]object.Object, count)
+			copy(args, vm.stack[base+1:vm.sp])
+			vm.popN(count + 1)
+
+			if function, ok := vm.runtime.ChainingMethod(node, left); ok && function.Compiled != nil {
+				if err := vm.replaceFrame(node, function, args); err != nil {
+					result = err
+
+					break
+				}
+
+				reload()
+
+				continue
+			}
+
+			result = vm.runtime.ChainingCall(node, left, args)
+
+		case compiler.OpChainProperty:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])].(*ast.Identifier)
+			fr.ip += 4
+
+			result = vm.runtime.ChainingProperty(node, vm.pop())
+
+		case compiler.OpReturnValue:
+			value := vm.pop()
+
+			if returnValue, ok := value.(*object.ReturnValue); ok {
+				value = returnValue.Value
+			}
+
+			// Statements run by the evaluator return their tail calls instead of making them
+			if tailCall, ok := value.(*object.TailCall); ok {
+				if tailCall.Function.Compiled != nil {
+					if err := vm.replaceFrame(tailCall.Node(), tailCall.Function, tailCall.Arguments); err != nil {
+						result = err
+
+						break
+					}
+
+					reload()
+
+					continue
+				}
+
+				if value = vm.runtime.Call(tailCall.Node(), tailCall.Function, tailCall.Arguments); value.Type() == object.ERROR_OBJ {
+					result = value
+
+					break
+				}
+			}
+
+			if len(vm.frames) == 1 {
+				return value
+			}
+
+			vm.popFrames(len(vm.frames) - 1)
+			vm.popN(vm.sp - fr.base)
+
+			if fr.callback {
+				return value
+			}
+
+			vm.push(value)
+			reload()
+
+		case compiler.OpRange:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])].(*ast.CallExpression)
+			count := int(compiler.ReadUint16(ins[fr.ip+4:]))
+			target := int(compiler.ReadUint32(ins[fr.ip+6:]))
+			fr.ip += 10
+
+			base := vm.sp - count - 1
+
+			if builtin, ok := vm.runtime.Builtin("range"); !ok || vm.stack[base] != builtin {
+				break
+			}
+
+			args := make([]object.Object, count)
+			copy(args, vm.stack[base+1:vm.sp])
+			vm.popN(count + 1)
+
+			iterator, err := vm.runtime.RangeIterator(node, args)
+
+			if err != nil {
+				result = err
+
+				break
+			}
+
+			fr.iterators = append(fr.iterators, iterator)
+			fr.ip = target
+
+		case compiler.OpIterate:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])].(*ast.ForInStatement)
+			fr.ip += 4
+
+			iterator, err := vm.runtime.Iterator(node, vm.pop())
+
+			if err != nil {
+				result = err
+
+				break
+			}
+
+			fr.iterators = append(fr.iterators, iterator)
+
+		case compiler.OpIterNext:
+			target := int(compiler.ReadUint32(ins[fr.ip:]))
+			fr.ip += 4
+
+			key, value, ok := fr.iterators[len(fr.iterators)-1]()
+
+			if !ok {
+				fr.ip = target
+
+				break
+			}
+
+			vm.push(value)
+			vm.push(key)
+
+		case compiler.OpPopIterator:
+			fr.iterators[len(fr.iterators)-1] = nil
+			fr.iterators = fr.iterators[:len(fr.iterators)-1]
+
+		case compiler.OpPushScope:
+			slots := int(compiler.ReadUint16(ins[fr.ip:]))
+			fr.ip += 2
+
+			fr.env = object.NewFrame(fr.env, slots)
+
+		case compiler.OpPopScope:
+			fr.env = fr.env.Outer()
+
+		case compiler.OpEval:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])]
+			fr.ip += 4
+
+			result = vm.runtime.Eval(node, fr.env)
+
+			if result == nil {
+				vm.push(nil)
+			}
+
+		case compiler.OpControl:
+			offset := -1
+
+			switch vm.stack[vm.sp-1].(type) {
+			case *object.Break:
+				offset = 0
+			case *object.Continue:
+				offset = 4
+			case *object.ReturnValue:
+				offset = 8
+			}
+
+			if offset == -1 {
+				fr.ip += 12
+			} else {
+				fr.ip = int(compiler.ReadUint32(ins[fr.ip+offset:]))
+			}
+
+		case compiler.OpWrapReturn:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])]
+			fr.ip += 4
+
+			vm.push(object.NewReturnValue(node, vm.pop()))
+
+		case compiler.OpSetHandler:
+			target := int(compiler.ReadUint32(ins[fr.ip:]))
+			fr.ip += 4
+
+			vm.handlers = append(vm.handlers, handler{target: target, frames: len(vm.frames), sp: vm.sp, env: fr.env, iterators: len(fr.iterators)})
+
+		case compiler.OpPopHandler:
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+
+		case compiler.OpThrow:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])].(*ast.ThrowStatement)
+			fr.ip += 4
+
+			result = vm.runtime.Throw(node, vm.pop(), fr.env)
+
+		case compiler.OpCatch:
+			node := bytecode.Nodes[compiler.ReadUint32(ins[fr.ip:])].(*ast.Identifier)
+			fr.ip += 4
+
+			vm.push(vm.runtime.CaughtError(node, vm.pop().(*object.Error), fr.env))
+
+		case compiler.OpFinally:
+			finally := vm.pop()
+			value := vm.pop()
+
+			// A break, continue or return of the finally block takes precedence,
+			// otherwise an error of the try or catch block is thrown again now that the finally block has run
+			switch finally.(type) {
+			case *object.Break, *object.Continue, *object.ReturnValue:
+				value = finally
+			}
+
+			result = value
+		}
+
+		if result == nil {
+			continue
+		}
+
+		if err, ok := result.(*object.Error); ok {
+			if !vm.handleError(err) {
+				// The frames of the calls that are still running are only left after the program stops
+				vm.runtime.RecordCallStack(err)
+
+				return err
+			}
+
+			reload()
+
+			continue
+		}
+
+		vm.push(result)
+	}
+}
+
+/*
+Replace the frame of the running function with a call of function, like a return of the call would
+but without keeping the frame of the running function until the call returns
+*/
+func (vm *VM) replaceFrame(node ast.Node, function *object.Function, args []object.Object) *object.Error {
+	env, err := vm.runtime.ExtendFunctionEnv(node, function, args)
+
+	if err != nil {
+		return err
+	}
+
+	fr := vm.frames[len(vm.frames)-1]
+	vm.popN(vm.sp - fr.base)
+	vm.frames[len(vm.frames)-1] = &frame{function: function.Compiled.(*compiler.Function), env: env, base: fr.base, callback: fr.callback}
+
+	// The call takes the place of the call that is left, so it can not exceed the maximum call depth
+	vm.runtime.LeaveCall()
+	vm.runtime.EnterCall(node, function)
+
+	return nil
+}
+
+/*
+Return from the calls of the frames above the given amount of frames
+*/
+func (vm *VM) popFrames(count int) {
+	for len(vm.frames) > count {
+		vm.frames = vm.frames[:len(vm.frames)-1]
+		vm.runtime.LeaveCall()
+	}
+}
+
+func (vm *VM) popN(count int) {
+	for i := 0; i < count; i++ {
+		vm.pop()
+	}
+}
+
+/*
+Get the global variable at index of the bytecode, looking it up by name in env only the first time it is used
+*/
+func (vm *VM) global(bytecode *compiler.Bytecode, index int, env *object.Environment) *object.Variable {
+	// Functions of other bytecode keep working, but their variables are looked up every time
+	if bytecode != vm.bytecode {
+		return env.Variable(bytecode.Globals[index])
+	}
+
+	variable := vm.globals[index]
+
+	if variable == nil {
+		variable = env.Variable(bytecode.Globals[index])
+		vm.globals[index] = variable
+	}
+
+	return variable
+}
+
+func (vm *VM) getName(node *ast.Identifier, env *object.Environment) object.Object {
+	if value, ok := env.Lookup(node); ok {
+		return value
+	}
+
+	if builtin, ok := vm.runtime.Builtin(node.Value); ok {
+		return builtin
+	}
+
+	return object.NewError(node, "identifier not found: %s", node.Value)
+}
+
+/*
+Get the name a variable or function statement defines, or the identifier itself for the variables of a for-in loop
+*/
+func definedName(node ast.Node) *ast.Identifier {
+	switch node := node.(type) {
+	case *ast.VariableStatement:
+		return node.Name
+	case *ast.FunctionStatement:
+		return node.Name
+	case *ast.Identifier:
+		return node
+	}
+
+	return nil
+}
+
+/*
+Get the name of the variable a reassignment or ++ and -- expression assigns to
+*/
+func assignedName(node ast.Node) *ast.Identifier {
+	switch node := node.(type) {
+	case *ast.ReassignmentExpression:
+		return node.Name
+	case *ast.IncrementDecrementExpression:
+		return node.Identifier
+	}
+
+	return nil
+}
+
+/*
+Create a function from a compiled function literal or function statement defined in file, enclosing the given environment
+*/
+func newFunction(compiled *compiler.Function, env *object.Environment, file string) *object.Function {
+	var function *object.Function
+
+	switch node := compiled.Node.(type) {
+	case *ast.FunctionLiteral:
+		function = object.NewFunction(node, node.Arguments, node.Body, env)
+		function.Defaults = node.Defaults
+		function.Rest = node.Rest
+		function.Patterns = node.Patterns
+		function.Slots = node.Slots
+	case *ast.FunctionStatement:
+		function = object.NewFunction(node, node.Arguments, node.Body, env)
+		function.Name = node.Name.Value
+		function.Defaults = node.Defaults
+		function.Rest = node.Rest
+		function.Patterns = node.Patterns
+		function.Slots = node.Slots
+	}
+
+	function.Compiled = compiled
+	function.File = file
+
+	return function
+}
+
+func (vm *VM) buildHash(node ast.Node, pairs []object.Object) object.Object {
+	hash := object.NewHash(node)
+
+	for i := 0; i < len(pairs); i += 2 {
+		key, value := pairs[i], pairs[i+1]
+		hashed, ok := object.HashKeyOf(key)
+
+		if !ok {
+			return object.NewError(node, "unusable as object key: %s", object.TypeName(key))
+		}
+
+		hash.Set(hashed, object.HashPair{Key: key, Value: value})
+	}
+
+	return hash
+}
+
+/*
+Apply an infix operator, handling the common integer operations without going through the evaluator
+*/
+func (vm *VM) infix(node *ast.InfixExpression, left, right object.Object) object.Object {
+	leftInteger, ok := left.(*object.Integer)
+
+	if !ok {
+		return vm.runtime.Infix(node, left, right)
+	}
+
+	rightInteger, ok := right.(*object.Integer)
+
+	if !ok {
+		return vm.runtime.Infix(node, left, right)
+	}
+
+	a, b := leftInteger.Value, rightInteger.Value
+
+	switch node.Operator {
+	case token.PLUS:
+		if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+			break
+		}
+
+		return object.NewInteger(node, a+b)
+	case token.MINUS:
+		if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+			break
+		}
+
+		return object.NewInteger(node, a-b)
+	case token.PERCENT:
+		if b == 0 {
+			break
+		}
+
+		return object.NewInteger(node, a%b)
+	case token.LT:
+		return nativeBoolToBooleanObject(a < b)
+	case token.GT:
+		return nativeBoolToBooleanObject(a > b)
+	case token.LTE:
+		return nativeBoolToBooleanObject(a <= b)
+	case token.GTE:
+		return nativeBoolToBooleanObject(a >= b)
+	case token.EQ:
+		return nativeBoolToBooleanObject(a == b)
+	case token.NOT_EQ:
+		return nativeBoolToBooleanObject(a != b)
+	}
+
+	// Overflows, modulo by zero and the other operators are left to the evaluator
+	return vm.runtime.Infix(node, left, right)
+}
+
+/*
+Apply the ++ or -- operator to the current value of a variable, handling integers that do not overflow
+without going through the evaluator
+*/
+func (vm *VM) incrementDecrement(node *ast.IncrementDecrementExpression, current object.Object) object.Object {
+	if integer, ok := current.(*object.Integer); ok {
+		if node.Token.Type == token.INCREMENT && integer.Value < math.MaxInt64 {
+			return object.NewInteger(node, integer.Value+1)
+		}
+
+		if node.Token.Type == token.DECREMENT && integer.Value > math.MinInt64 {
+			return object.NewInteger(node, integer.Value-1)
+		}
+	}
+
+	return vm.runtime.IncrementDecrement(node, current)
+}
+
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return object.TRUE
+	}
+
+	return object.FALSE
+}
+
+func isTruthy(obj object.Object) bool {
+	switch obj {
+	case object.NULL:
+		return false
+	case object.TRUE:
+		return true
+	case object.FALSE:
+		return false
+	default:
+		return true
+	}
+}
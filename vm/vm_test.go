@@ -0,0 +1,401 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/iskandervdh/vorn/ast"
+	"github.com/iskandervdh/vorn/compiler"
+	"github.com/iskandervdh/vorn/evaluator"
+	"github.com/iskandervdh/vorn/lexer"
+	"github.com/iskandervdh/vorn/object"
+	"github.com/iskandervdh/vorn/parser"
+	"github.com/iskandervdh/vorn/resolver"
+	"github.com/iskandervdh/vorn/token"
+)
+
+func parse(input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l, false)
+
+	return p.ParseProgram()
+}
+
+/*
+Parse and resolve a program and run it with the given function, returning the first error of the resolver if there are any
+*/
+func run(input string, run func(program *ast.Program) object.Object) object.Object {
+	program := parse(input)
+
+	if errors := resolver.New(evaluator.New()).Resolve(program); len(errors) != 0 {
+		return errors[0]
+	}
+
+	return run(program)
+}
+
+func describe(obj object.Object) string {
+	if obj == nil {
+		return "nil"
+	}
+
+	return string(obj.Type()) + " " + obj.Inspect()
+}
+
+/*
+Check that the vm gives the same result as the evaluator for the given inputs
+*/
+func testSameAsEvaluator(t *testing.T, inputs []string) {
+	t.Helper()
+
+	for _, input := range inputs {
+		evaluated := run(input, func(program *ast.Program) object.Object {
+			return evaluator.New().Eval(program, object.NewEnvironment())
+		})
+		ran := run(input, func(program *ast.Program) object.Object {
+			return New(evaluator.New()).Run(compiler.Compile(program), object.NewEnvironment())
+		})
+
+		if describe(evaluated) != describe(ran) {
+			t.Errorf("wrong result for %q. evaluator gave %s, vm gave %s", input, describe(evaluated), describe(ran))
+		}
+	}
+}
+
+func TestControlFlow(t *testing.T) {
+	testSameAsEvaluator(t, []string{
+		`let x = 0; while (true) { x++; if (x > 3) { break; } } x;`,
+		`let x = 0; let y = 0; while (x < 10) { x++; if (x % 2 == 0) { continue; } y += x; } y;`,
+		`let n = 0; for (let i = 0; i < 10; i++) { if (i == 2) { continue; } if (i == 5) { break; } n += i; } n;`,
+		`for (let i = 0; i < 2; i++) {} let c = 0; for (let i = 0; i < 5; i++) { c++; } c;`,
+		`let n = 0; for (let i = 0; i < 3; 1 / 0) { n++; break; } n;`,
+		`func f() { break; } let i = 0; while (i < 10) { i++; f(); } i;`,
+		`func f() { continue; } f();`,
+		`let i = 0; while (true) { i++; i > 2 ? break : continue; } i;`,
+		`let i = 0; while (true) { i++; i > 2 && break; } i;`,
+		`let v = if (true) { break; }; v;`,
+		`func f() { let v = if (true) { return 1; }; return 2; } f();`,
+		`func f() { while (true) { if (true) { return 3; } } } f();`,
+		`func f() { let i = 0; while (true) { let j = i; i++; if (j == 4) { return j; } } } f();`,
+		`let i = 0; while (i < 5) { i++; try { if (i == 3) { break; } } finally { } } i;`,
+		`let i = 0; while (i < 5) { try { throw "x"; } catch { i++; continue; } i = 100; } i;`,
+		`let n = 0; for (x in [1, 2, 3, 4]) { if (x == 3) { break; } n += x; } n;`,
+		`break; 5;`,
+		`if (true) { break; }`,
+		`let a = 1; return a + 1; a = 5;`,
+		`if (true) { return 10; } 20;`,
+		`bool(continue);`,
+		`let x = 1; if (x) { let x = 2; x; }`,
+	})
+}
+
+func TestFunctions(t *testing.T) {
+	testSameAsEvaluator(t, []string{
+		`func fib(n) { if (n <= 1) { return n; } return fib(n - 1) + fib(n - 2); } fib(15);`,
+		`let add = func(a, b) { a + b }; add(1, 2);`,
+		`let add = func(a, b) { return a + b; }; add;`,
+		`func f(a, b = a + 1, ...rest) { return [a, b, rest]; } [f(1), f(1, 5, 6, 7)];`,
+		`func f([a, b], {c}) { return a + b + c; } f([1, 2], {c: 3});`,
+		`let fs = []; let i = 0; while (i < 3) { let j = i; fs = fs.append(func() { return j; }); i++; } [fs[0](), fs[1](), fs[2]()];`,
+		`let counter = func() { let n = 0; return func() { n++; return n; }; }(); counter(); counter();`,
+		`[1, 2, 3].map(func(x) { return x * 2; }).reduce(func(a, b) { return a + b; }, 0);`,
+		`func f(a) { return a; } f();`,
+		`func f() { return later; } f(); let later = 1;`,
+		`let f = func() { 1 + true }; f();`,
+		`func f() { return 1; } func f() { return 2; }`,
+		`5();`,
+		`len([1, 2, 3]);`,
+		`print;`,
+		`func f(...args) { return args; } f(...[1, 2], 3);`,
+		`let o = {"down": func(n) { return n == 0 ? "done" : o.down(n - 1); }}; o.down(50000);`,
+		`let o = {"down": 5}; func f() { return o.down(1); } f();`,
+		`func f(n) { return n > 0 ? "a".upper() : f(1); } f(0);`,
+		`struct C { func down(n) { return n == 0 ? 9 : self.down(n - 1); } } C().down(50000);`,
+	})
+}
+
+func TestExpressions(t *testing.T) {
+	testSameAsEvaluator(t, []string{
+		`1 + 2 * 3 - 4 / 2;`,
+		`7 % 3;`,
+		`5 % 0;`,
+		`9223372036854775807 + 1;`,
+		`-9223372036854775807 - 2;`,
+		`let x = -9223372036854775807 - 1; -x;`,
+		`abs(-9223372036854775807 - 1);`,
+		`1 < 2 == true;`,
+		`"a" + "b";`,
+		`!true;`,
+		`-5;`,
+		`~5;`,
+		`null || "default";`,
+		`0 && "never";`,
+		`func f() { return false && later; } f(); let later = 1;`,
+		`[1, 2, 3][1];`,
+		`{"a": 1, "b": [1, 2]}["b"];`,
+		`{[1]: 2};`,
+		`{len: 1};`,
+		`let h = {"a": 1, ...{"b": 2}}; h;`,
+		`[1, ...[2, 3], ..."ab"];`,
+		`true ? 1 : 2;`,
+		`let x = 5; x += 2; x -= 1; x *= 3; x /= 2; x;`,
+		`let x = 5; x <<= 2; x;`,
+		`func f() { y = 5; } f(); let y = 1;`,
+		`func f() { y += 5; } f(); let y = 1;`,
+		`let x = 1; x += "a";`,
+		`let x = 1; let x = 2;`,
+		`let x = 1; x++; x--; ++x; x;`,
+		`let s = "abc"; s.upper().length();`,
+		`let h = {"a": {"b": 2}}; h.a.b;`,
+		`let h = {"a": 1}; h.a = 5; h.a++; h;`,
+		`let name = "vorn"; "hello ${name}";`,
+		`match (3) { 1 => "one", 3 => "three", _ => "other" };`,
+		`let [a, b] = [1, 2]; a + b;`,
+		`struct Point { x, y; func sum() { return self.x + self.y; } } Point(1, 2).sum();`,
+		`1.5 + 2;`,
+		`let f = func() { later; }; f(); let later = 1;`,
+	})
+}
+
+func TestIncrementDecrementAndMembers(t *testing.T) {
+	testSameAsEvaluator(t, []string{
+		`let x = 1; [x++, x, ++x, x, x--, --x];`,
+		`func f() { let n = 1.5; n++; return n; } f();`,
+		`let x = 9223372036854775807; x++;`,
+		`let x = -9223372036854775807 - 1; x--;`,
+		`let s = "a"; s++;`,
+		`func f() { y++; } f(); let y = 1;`,
+		`let a = [1, 2]; a[0] = 5; a[-1] += 3; a;`,
+		`let a = [1, 2]; [a[0]++, ++a[1], a];`,
+		`let a = [1]; a[5] = 1;`,
+		`let h = {}; h["a"] = 1; h.b = 2; h.a += 10; h;`,
+		`let h = {"n": 9223372036854775807}; h.n++;`,
+		`let h = {"n": "a"}; h.n -= 1;`,
+		`let h = {}; h[len] = 1;`,
+		`struct P { x; } let p = P(1); p.x++; p.x *= 10; p.x;`,
+		`struct P { x; } let p = P(1); p.y = 1;`,
+		`let x = 5; x.y = 1;`,
+		`let h = {"a": [1, {"b": 1}]}; h.a[1].b++; h.a[1]["b"] <<= 2; h;`,
+		`let n = 0; for (let i = 0; i < 100; i++) { n += i; } n;`,
+	})
+}
+
+func TestForIn(t *testing.T) {
+	testSameAsEvaluator(t, []string{
+		`let s = 0; for (i, x in [10, 20, 30]) { s += i * x; } s;`,
+		`let out = ""; for (c in "héllo") { out += c; } out;`,
+		`let keys = []; for (k in {"a": 1, "b": 2}) { keys = keys.append(k); } keys;`,
+		`let pairs = []; for (k, v in {"a": 1, "b": 2}) { pairs = pairs.append([k, v]); } pairs;`,
+		`let s = 0; for (i in range(10)) { if (i == 2) { continue; } if (i == 6) { break; } s += i; } s;`,
+		`let s = []; for (i, n in range(5, 2)) { s = s.append([i, n]); } s;`,
+		`for (i in range(-1)) { }`,
+		`let r = range; let s = 0; for (i in r(4)) { s += i; } s;`,
+		`func numbers(n) { return [n, n * 2]; } let s = 0; for (x in numbers(3)) { s += x; } s;`,
+		`let s = 0; for (x in range(...[1, 4])) { s += x; } s;`,
+		`func find(arr, value) { for (i, x in arr) { if (x == value) { return i; } } return -1; } [find([4, 5, 6], 6), find([], 1)];`,
+		`func f() { for (x in [1, 2]) { for (y in range(3)) { if (y == 1) { return [x, y]; } } } } f();`,
+		`let n = 0; for (x in [1, 2, 3]) { for (y in [1, 2, 3]) { if (y == 2) { break; } n++; } n += 10; } n;`,
+		`let fs = []; for (x in [1, 2, 3]) { fs = fs.append(func() { return x; }); } [fs[0](), fs[2]()];`,
+		`for (x in 5) { }`,
+		`for (x in undefined()) { }`,
+		`let s = 0; for (x in [1, 2, 3]) { s += x; } for (x in [4]) { s += x; } s;`,
+		`let v = if (true) { for (x in [1, 2]) { if (x == 2) { break; } } 3; }; v;`,
+		`func f() { let v = if (true) { for (x in [1, 2]) { return x; } }; return 0; } f();`,
+		`for (x in [1]) { x.y = 1; }`,
+	})
+}
+
+func TestTryCatch(t *testing.T) {
+	testSameAsEvaluator(t, []string{
+		`try { 1 / 0; } catch (e) { e.message; }`,
+		`let m = ""; try { throw "boom"; } catch (e) { m = e.message; } m;`,
+		`let v = null; try { throw {"code": 42}; } catch (e) { v = e.value; } v;`,
+		`let r = []; try { r = r.append(1); } catch { r = r.append(2); } finally { r = r.append(3); } r;`,
+		`let r = []; try { 1 / 0; } catch { r = r.append(2); } finally { r = r.append(3); } r;`,
+		`let r = []; try { try { throw "x"; } finally { r = r.append("finally"); } } catch (e) { r = r.append(e.message); } r;`,
+		`try { throw "inner"; } catch (e) { throw "outer: " + e.message; }`,
+		`let r = 0; try { try { throw "a"; } catch (e) { throw "b"; } finally { r = 1; } } catch (e) { r += e.message == "b" ? 10 : 0; } r;`,
+		`try { throw "x"; } finally { }`,
+		`try { throw "x"; } finally { 1 + true; }`,
+		`func f() { try { return 1; } finally { print; } } f();`,
+		`func f() { try { return 1; } finally { return 2; } } f();`,
+		`func f() { try { throw "x"; } catch { return "caught"; } finally { } return "after"; } f();`,
+		`func f() { try { throw "x"; } finally { return "finally"; } } f();`,
+		`let i = 0; while (i < 5) { i++; try { if (i == 3) { break; } } finally { i += 10; } } i;`,
+		`let n = 0; for (x in [1, 2, 3]) { try { if (x == 2) { throw "skip"; } n += x; } catch { continue; } } n;`,
+		`let n = 0; for (x in [1, 2, 3]) { try { for (y in [1, 2]) { if (x == 2) { throw "out"; } n++; } } catch { n += 100; } } n;`,
+		`func thrower(n) { if (n == 0) { throw "deep"; } return thrower(n - 1) + 1; } try { thrower(3); } catch (e) { [e.message, e.line, e.column]; }`,
+		`func f() { try { undefined(); } catch (e) { return e.message; } } f();`,
+		`let e = 1; try { throw "x"; } catch (e) { e = 2; } e;`,
+		`try { throw "x"; } catch (e) { let inner = e; inner.message; }`,
+		`let caught = null; try { [1][5] = 1; } catch (e) { caught = e; } throw caught;`,
+		`throw 5;`,
+		`throw null + 1;`,
+		`try { 1; } catch { }`,
+	})
+}
+
+func TestErrorTraceback(t *testing.T) {
+	inputs := []string{
+		`func f() { 1 / 0; } try { f(); } catch (e) { let caught = e; throw caught; }`,
+		`func f() { try { 1 / 0; } catch (e) { throw e; } } func g() { f(); } g();`,
+		`func f() { throw "x"; } func g() { try { f(); } catch (e) { let rethrow = func() { throw e; }; rethrow(); } } g();`,
+		`func f(x) { return 1 / 0; } func g() { return [1].map(f); } g();`,
+		`func f(x) { return [x].map(func(y) { return y.missing(); }); } [1].map(f);`,
+	}
+
+	for _, input := range inputs {
+		evaluated := run(input, func(program *ast.Program) object.Object {
+			return evaluator.New().Eval(program, object.NewEnvironment())
+		})
+		ran := run(input, func(program *ast.Program) object.Object {
+			return New(evaluator.New()).Run(compiler.Compile(program), object.NewEnvironment())
+		})
+
+		evaluatedErr, ok := evaluated.(*object.Error)
+
+		if !ok {
+			t.Fatalf("evaluator did not give an error for %q. got %s", input, describe(evaluated))
+		}
+
+		ranErr, ok := ran.(*object.Error)
+
+		if !ok {
+			t.Fatalf("vm did not give an error for %q. got %s", input, describe(ran))
+		}
+
+		if evaluatedErr.Traceback() == "" || evaluatedErr.Traceback() != ranErr.Traceback() {
+			t.Errorf("wrong traceback for %q.\nevaluator gave\n%s\nvm gave\n%s", input, evaluatedErr.Traceback(), ranErr.Traceback())
+		}
+	}
+}
+
+func TestCallbacks(t *testing.T) {
+	testSameAsEvaluator(t, []string{
+		`let total = 0; [1, 2, 3].map(func(x) { total += x; return x * 2; }).append(total);`,
+		`[3, 1, 2].sort(func(a, b) { return a - b; });`,
+		`[1, 2, 3, 4].filter(func(x) { for (i in range(x)) { if (i == 2) { return true; } } return false; });`,
+		`[1, 2].map(func(x) { try { throw x; } catch (e) { return e.value * 10; } });`,
+		`[1, 2].map(func(x) { return 1 / (x - 2); });`,
+		`try { [1, 2].map(func(x) { throw "in callback " + string(x); }); } catch (e) { e.message; }`,
+		`func f(x) { return [x].map(func(y) { return y == 0 ? 0 : f(y - 1) + 1; })[0]; } f(5);`,
+		`func count(n) { return n == 0 ? 0 : count(n - 1); } [1000].map(func(n) { return count(n); });`,
+		`func loop(n, acc) { if (n == 0) { return acc; } return loop(n - 1, acc + n); } [100].map(func(n) { return loop(n, 0); });`,
+		`[1, 2].map(func(x) { break; });`,
+		`[1].map(func(x) { return x.missing(); });`,
+		`let f = func(x) { return x * 3; }; match (2) { 2 => f(2), _ => 0 };`,
+		`let f = func() { return [1, 2].map(func(x) { return x + 1; }); }; [f(), f()];`,
+	})
+}
+
+/*
+A runtime that counts how often the vm calls a function
+*/
+type countingRuntime struct {
+	*evaluator.Evaluator
+	calls int
+}
+
+func (r *countingRuntime) ExtendFunctionEnv(node ast.Node, function *object.Function, args []object.Object) (*object.Environment, *object.Error) {
+	r.calls++
+
+	return r.Evaluator.ExtendFunctionEnv(node, function, args)
+}
+
+func TestCallbacksRunByVM(t *testing.T) {
+	runtime := &countingRuntime{Evaluator: evaluator.New()}
+
+	result := run(`[1, 2, 3].map(func(x) { return x * 2; }).filter(func(x) { return x > 2; });`, func(program *ast.Program) object.Object {
+		return New(runtime).Run(compiler.Compile(program), object.NewEnvironment())
+	})
+
+	if describe(result) != "ARRAY [4, 6]" {
+		t.Fatalf("wrong result. got %s", describe(result))
+	}
+
+	if runtime.calls != 6 {
+		t.Errorf("wrong amount of calls run by the vm. got %d, want 6", runtime.calls)
+	}
+}
+
+func TestTemplateLiterals(t *testing.T) {
+	testSameAsEvaluator(t, []string{
+		`let name = "vorn"; let n = 2; "hello ${name} ${n + 1} ${[1, "a"]} ${null}";`,
+		`"${"nested ${1 + 1}"}!";`,
+		`"${1 / 0} never";`,
+		`struct P { x; func __str() { return "P(" + string(self.x) + ")"; } } "${P(1)} and ${[P(2)]}";`,
+		`struct P { x; func __str() { return 1; } } "${P(1)}";`,
+		`let log = []; struct P { n; func __str() { log = log.append(self.n); return "p"; } } "${P(1)}${log}";`,
+		`func f(x) { return "${x}!"; } [1, 2].map(f);`,
+	})
+}
+
+func TestGlobals(t *testing.T) {
+	testSameAsEvaluator(t, []string{
+		`let x = 1; func inc() { x++; return x; } inc(); inc(); x;`,
+		`func get() { return v; } let a = get; let v = 5; a();`,
+		`let [a, b] = [1, 2]; a = a + b; let {c} = {c: a}; c += b; [a, b, c];`,
+		`let s = 0; let i = 0; while (i < 10) { s += i; i++; } s;`,
+		`let x = 1; if (true) { let y = x; x = y + 1; } x;`,
+		`undefined;`,
+		`undefined = 1;`,
+		`undefined++;`,
+	})
+}
+
+func TestGlobalEnvironment(t *testing.T) {
+	env := object.NewEnvironment()
+	r := resolver.New(evaluator.New())
+
+	// Run the programs one after the other in the same environment, like the REPL does
+	for _, tt := range []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 2;", "nil"},
+		{"let y = x + 1; x = 5; y;", "INTEGER 3"},
+		{"func f() { return x + y; } y = 10; f();", "INTEGER 15"},
+	} {
+		program := parse(tt.input)
+
+		if errors := r.Resolve(program); len(errors) != 0 {
+			t.Fatalf("resolver errors for %q: %v", tt.input, errors)
+		}
+
+		result := New(evaluator.New()).Run(compiler.Compile(program), env)
+
+		if describe(result) != tt.expected {
+			t.Errorf("wrong result for %q. got %s, want %s", tt.input, describe(result), tt.expected)
+		}
+	}
+
+	if value, ok := env.GetFromCurrent("x"); !ok || value.Inspect() != "5" {
+		t.Errorf("wrong value of x in the environment. got %v", value)
+	}
+}
+
+func TestInternalErrorRecovery(t *testing.T) {
+	// A prefix expression without a right-hand side can't be produced by the parser,
+	// but makes the evaluator panic which should be turned into an error
+	program := &ast.Program{
+		Statements: []ast.Statement{
+			&ast.ExpressionStatement{
+				Token: token.Token{Type: token.MINUS, Literal: "-", Line: 3, Column: 7},
+				Expression: &ast.PrefixExpression{
+					Token:    token.Token{Type: token.MINUS, Literal: "-", Line: 3, Column: 7},
+					Operator: "-",
+				},
+			},
+		},
+	}
+
+	evaluated := New(evaluator.New()).Run(compiler.Compile(program), object.NewEnvironment())
+	err, ok := evaluated.(*object.Error)
+
+	if !ok {
+		t.Fatalf("object is not Error. got %T (%+v)", evaluated, evaluated)
+	}
+
+	if err.Message[:21] != "[3:7] internal error:" {
+		t.Errorf("wrong error message. got %q", err.Message)
+	}
+}